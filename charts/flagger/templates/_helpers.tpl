@@ -39,4 +39,10 @@ Create the name of the service account to use
 {{- else -}}
     {{ default "default" .Values.serviceAccount.name }}
 {{- end -}}
-{{- end -}}
\ No newline at end of file
+{{- end -}}
+{{/*
+Create the name of the secret containing the admission webhook certificate
+*/}}
+{{- define "flagger.webhookCertSecret" -}}
+{{- default (printf "%s-webhook-cert" (include "flagger.fullname" .)) .Values.webhook.certSecret -}}
+{{- end -}}
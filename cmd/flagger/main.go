@@ -40,6 +40,7 @@ var (
 	masterURL                string
 	kubeconfig               string
 	metricsServer            string
+	metricsServerSecret      string
 	controlLoopInterval      time.Duration
 	logLevel                 string
 	port                     string
@@ -61,10 +62,13 @@ var (
 	enableConfigTracking     bool
 	ver                      bool
 	kubeconfigServiceMesh    string
+	webhookPort              string
+	webhookCertDir           string
 )
 
 func init() {
 	flag.StringVar(&metricsServer, "metrics-server", "http://prometheus:9090", "Prometheus URL.")
+	flag.StringVar(&metricsServerSecret, "metrics-server-secret", "", "Secret in the namespace/name format containing the Prometheus credentials.")
 	flag.DurationVar(&controlLoopInterval, "control-loop-interval", 10*time.Second, "Kubernetes API sync interval.")
 	flag.StringVar(&logLevel, "log-level", "debug", "Log level can be: debug, info, warning, error.")
 	flag.StringVar(&port, "port", "8080", "Port to listen on.")
@@ -88,6 +92,8 @@ func init() {
 	flag.BoolVar(&enableConfigTracking, "enable-config-tracking", true, "Enable secrets and configmaps tracking.")
 	flag.BoolVar(&ver, "version", false, "Print version")
 	flag.StringVar(&kubeconfigServiceMesh, "kubeconfig-service-mesh", "", "Path to a kubeconfig for the service mesh control plane cluster.")
+	flag.StringVar(&webhookPort, "webhook-port", "9443", "Port the admission webhook listens on.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "", "Directory containing the admission webhook tls.crt and tls.key, the webhook is disabled if empty.")
 }
 
 func main() {
@@ -156,7 +162,15 @@ func main() {
 		logger.Infof("Watching namespace %s", namespace)
 	}
 
-	observerFactory, err := observers.NewFactory(metricsServer)
+	var metricsServerCredentials map[string][]byte
+	if metricsServerSecret != "" {
+		metricsServerCredentials, err = getSecretData(kubeClient, metricsServerSecret)
+		if err != nil {
+			logger.Fatalf("Error reading the metrics server secret: %s", err.Error())
+		}
+	}
+
+	observerFactory, err := observers.NewFactoryWithCredentials(metricsServer, metricsServerCredentials)
 	if err != nil {
 		logger.Fatalf("Error building prometheus client: %s", err.Error())
 	}
@@ -174,6 +188,11 @@ func main() {
 	// start HTTP server
 	go server.ListenAndServe(port, 3*time.Second, logger, stopCh)
 
+	// start admission webhook server
+	if webhookCertDir != "" {
+		go server.ListenAndServeWebhook(webhookPort, webhookCertDir, meshProvider, 3*time.Second, logger, stopCh)
+	}
+
 	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, ingressAnnotationsPrefix, ingressClass, logger, meshClient)
 
 	var configTracker canary.Tracker
@@ -260,10 +279,35 @@ func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger
 		logger.Fatalf("failed to wait for cache to sync")
 	}
 
+	logger.Info("Waiting for canary group informer cache to sync")
+	groupInformer := flaggerInformerFactory.Flagger().V1beta1().CanaryGroups()
+	go groupInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, groupInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
+	logger.Info("Waiting for analysis template informer cache to sync")
+	templateInformer := flaggerInformerFactory.Flagger().V1beta1().AnalysisTemplates()
+	go templateInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, templateInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
+	logger.Info("Waiting for cluster analysis template informer cache to sync")
+	clusterTemplateInformer := flaggerInformerFactory.Flagger().V1beta1().ClusterAnalysisTemplates()
+	go clusterTemplateInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, clusterTemplateInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
 	return controller.Informers{
 		CanaryInformer: canaryInformer,
 		MetricInformer: metricInformer,
 		AlertInformer:  alertInformer,
+		GroupInformer:  groupInformer,
+
+		TemplateInformer:        templateInformer,
+		ClusterTemplateInformer: clusterTemplateInformer,
 	}
 }
 
@@ -340,6 +384,20 @@ func fromEnv(envVar string, defaultVal string) string {
 	return defaultVal
 }
 
+// getSecretData returns the data of the secret referenced in the namespace/name format
+func getSecretData(kubeClient kubernetes.Interface, ref string) (map[string][]byte, error) {
+	ns, name, err := cache.SplitMetaNamespaceKey(ref)
+	if err != nil || ns == "" {
+		return nil, fmt.Errorf("invalid secret reference %s, expected namespace/name", ref)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("secret %s.%s get error: %w", name, ns, err)
+	}
+	return secret.Data, nil
+}
+
 func verifyCRDs(flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
 	_, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
 	if err != nil {
@@ -355,6 +413,21 @@ func verifyCRDs(flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
 	if err != nil {
 		logger.Fatalf("AlertProvider CRD is not registered %v", err)
 	}
+
+	_, err = flaggerClient.FlaggerV1beta1().CanaryGroups(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logger.Fatalf("CanaryGroup CRD is not registered %v", err)
+	}
+
+	_, err = flaggerClient.FlaggerV1beta1().AnalysisTemplates(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logger.Fatalf("AnalysisTemplate CRD is not registered %v", err)
+	}
+
+	_, err = flaggerClient.FlaggerV1beta1().ClusterAnalysisTemplates().List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		logger.Fatalf("ClusterAnalysisTemplate CRD is not registered %v", err)
+	}
 }
 
 func verifyKubernetesVersion(kubeClient kubernetes.Interface, logger *zap.SugaredLogger) {
@@ -36,5 +36,12 @@ ${CODEGEN_PKG}/generate-groups.sh all \
     --output-base "${TEMP_DIR}" \
     --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.go.txt
 
+# The EDAS route types are embedded in the Canary spec and only need deepcopy funcs.
+${CODEGEN_PKG}/generate-groups.sh deepcopy \
+    github.com/weaveworks/flagger/pkg/client github.com/weaveworks/flagger/pkg/apis \
+    "edas:v1alpha1/route" \
+    --output-base "${TEMP_DIR}" \
+    --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.go.txt
+
 # Copy everything back.
 cp -r "${TEMP_DIR}/github.com/weaveworks/flagger/." "${SCRIPT_ROOT}/"
@@ -1,10 +1,12 @@
 package route
 
+import "fmt"
+
 type ConditionPolicy string
 
 const (
 	PolicyAND ConditionPolicy = "AND"
-	PolicyOR ConditionPolicy = "OR"
+	PolicyOR  ConditionPolicy = "OR"
 )
 
 type ConditionOperator string
@@ -78,4 +80,64 @@ type DubboMatchRequest struct {
 	TriggerPolicy ConditionPolicy `json:"triggerPolicy"`
 
 	Conditions []DubboCondition `json:"conditions"`
-}
\ No newline at end of file
+}
+
+// Validate checks that the match request can be turned into a tag routing rule
+func (r DubboMatchRequest) Validate() error {
+	if r.ServiceName == "" {
+		return fmt.Errorf("dubbo match serviceName is empty")
+	}
+	if r.MethodName == "" {
+		return fmt.Errorf("dubbo match %s methodName is empty", r.ServiceName)
+	}
+	if err := r.TriggerPolicy.Validate(); err != nil {
+		return fmt.Errorf("dubbo match %s.%s %w", r.ServiceName, r.MethodName, err)
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("dubbo match %s.%s has no conditions", r.ServiceName, r.MethodName)
+	}
+	for i, c := range r.Conditions {
+		if c.ParamIndex < 0 || int(c.ParamIndex) >= len(r.ParamTypes) {
+			return fmt.Errorf("dubbo match %s.%s condition %d paramIndex %d is out of paramTypes range",
+				r.ServiceName, r.MethodName, i, c.ParamIndex)
+		}
+		if _, ok := c.Operator.Symbol(); !ok {
+			return fmt.Errorf("dubbo match %s.%s condition %d operator %q is not supported",
+				r.ServiceName, r.MethodName, i, c.Operator)
+		}
+		if len(c.Values) == 0 {
+			return fmt.Errorf("dubbo match %s.%s condition %d has no values", r.ServiceName, r.MethodName, i)
+		}
+	}
+	return nil
+}
+
+// Validate checks the policy is empty (defaults to AND), AND or OR
+func (p ConditionPolicy) Validate() error {
+	switch p {
+	case "", PolicyAND, PolicyOR:
+		return nil
+	default:
+		return fmt.Errorf("triggerPolicy %q is not supported", p)
+	}
+}
+
+// Symbol returns the comparison operator understood by the registry
+func (o ConditionOperator) Symbol() (string, bool) {
+	switch o {
+	case OperatorEqual:
+		return "==", true
+	case OperatorNotEqual:
+		return "!=", true
+	case OperatorGreatThan:
+		return ">", true
+	case OperatorLessThan:
+		return "<", true
+	case OperatorGreatThanOrEqual:
+		return ">=", true
+	case OperatorLessThanOrEqual:
+		return "<=", true
+	default:
+		return "", false
+	}
+}
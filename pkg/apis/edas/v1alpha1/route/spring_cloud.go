@@ -1,5 +1,7 @@
 package route
 
+import "fmt"
+
 type SCTrafficStrategy string
 
 const (
@@ -12,7 +14,7 @@ const (
 
 type SpringCloudCondition struct {
 	// spring cloud traffic strategy
-	Strategy  SCTrafficStrategy `json:"strategy"`
+	Strategy SCTrafficStrategy `json:"strategy"`
 
 	// key in specific strategy
 	Key string `json:"key"`
@@ -33,4 +35,34 @@ type SpringCloudMatchRequest struct {
 	TriggerPolicy ConditionPolicy `json:"triggerPolicy"`
 
 	Conditions []SpringCloudCondition `json:"conditions"`
-}
\ No newline at end of file
+}
+
+// Validate checks that the match request can be turned into a tag routing rule
+func (r SpringCloudMatchRequest) Validate() error {
+	if r.Path == "" {
+		return fmt.Errorf("spring cloud match path is empty")
+	}
+	if err := r.TriggerPolicy.Validate(); err != nil {
+		return fmt.Errorf("spring cloud match %s %w", r.Path, err)
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("spring cloud match %s has no conditions", r.Path)
+	}
+	for i, c := range r.Conditions {
+		switch c.Strategy {
+		case SCTrafficStrategyHEADER, SCTrafficStrategyPARAM, SCTrafficStrategyCOOKIE:
+		default:
+			return fmt.Errorf("spring cloud match %s condition %d strategy %q is not supported", r.Path, i, c.Strategy)
+		}
+		if c.Key == "" {
+			return fmt.Errorf("spring cloud match %s condition %d key is empty", r.Path, i)
+		}
+		if _, ok := c.Operator.Symbol(); !ok {
+			return fmt.Errorf("spring cloud match %s condition %d operator %q is not supported", r.Path, i, c.Operator)
+		}
+		if len(c.Values) == 0 {
+			return fmt.Errorf("spring cloud match %s condition %d has no values", r.Path, i)
+		}
+	}
+	return nil
+}
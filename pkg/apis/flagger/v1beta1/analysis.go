@@ -0,0 +1,204 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	AnalysisTemplateKind        = "AnalysisTemplate"
+	ClusterAnalysisTemplateKind = "ClusterAnalysisTemplate"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AnalysisTemplate holds a canary analysis fragment shared by the canaries of a namespace
+type AnalysisTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AnalysisTemplateSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AnalysisTemplateList is a list of analysis template resources
+type AnalysisTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []AnalysisTemplate `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterAnalysisTemplate holds a canary analysis fragment shared by the canaries of all namespaces
+type ClusterAnalysisTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AnalysisTemplateSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterAnalysisTemplateList is a list of cluster analysis template resources
+type ClusterAnalysisTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterAnalysisTemplate `json:"items"`
+}
+
+// AnalysisTemplateSpec is the specification of an analysis template
+type AnalysisTemplateSpec struct {
+	// Analysis fragment merged into the analysis of the canaries referencing this template
+	Analysis CanaryAnalysis `json:"analysis"`
+}
+
+// AnalysisTemplateReference references an analysis template
+type AnalysisTemplateReference struct {
+	// Name of the template
+	Name string `json:"name"`
+
+	// Kind of the template: AnalysisTemplate (default) or ClusterAnalysisTemplate,
+	// an AnalysisTemplate must be in the canary namespace
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// IsCluster returns true if the reference points to a ClusterAnalysisTemplate
+func (r AnalysisTemplateReference) IsCluster() bool {
+	return r.Kind == ClusterAnalysisTemplateKind
+}
+
+// MergeAnalysis returns the analysis built from the templates in the given order and the inline analysis,
+// the metrics, webhooks and alerts are merged by name and the inline entries override the template entries,
+// the other fields are taken from the last fragment that sets them
+func MergeAnalysis(inline *CanaryAnalysis, templates []CanaryAnalysis) *CanaryAnalysis {
+	if inline == nil {
+		return nil
+	}
+	merged := &CanaryAnalysis{}
+	for _, t := range templates {
+		mergeAnalysisInto(merged, t.DeepCopy())
+	}
+	mergeAnalysisInto(merged, inline.DeepCopy())
+	merged.TemplateRefs = inline.DeepCopy().TemplateRefs
+	return merged
+}
+
+func mergeAnalysisInto(dst *CanaryAnalysis, src *CanaryAnalysis) {
+	if src.Interval != "" {
+		dst.Interval = src.Interval
+	}
+	if src.Iterations != 0 {
+		dst.Iterations = src.Iterations
+	}
+	if src.Mirror {
+		dst.Mirror = src.Mirror
+	}
+	if src.MirrorWeight != 0 {
+		dst.MirrorWeight = src.MirrorWeight
+	}
+	if src.MaxWeight != 0 {
+		dst.MaxWeight = src.MaxWeight
+	}
+	if src.StepWeight != 0 {
+		dst.StepWeight = src.StepWeight
+	}
+	if src.StepWeightPromotion != 0 {
+		dst.StepWeightPromotion = src.StepWeightPromotion
+	}
+	if len(src.Steps) > 0 {
+		dst.Steps = src.Steps
+	}
+	if src.Schedule != nil {
+		dst.Schedule = src.Schedule
+	}
+	if src.Judge != nil {
+		dst.Judge = src.Judge
+	}
+	if src.Threshold != 0 {
+		dst.Threshold = src.Threshold
+	}
+	if src.PassScore != nil {
+		dst.PassScore = src.PassScore
+	}
+	if len(src.Match) > 0 {
+		dst.Match = src.Match
+	}
+	if len(src.DubboMatch) > 0 {
+		dst.DubboMatch = src.DubboMatch
+	}
+	if len(src.SpringCloudMatch) > 0 {
+		dst.SpringCloudMatch = src.SpringCloudMatch
+	}
+	if src.MaxReplicas != 0 {
+		dst.MaxReplicas = src.MaxReplicas
+	}
+	if src.StepReplicas != 0 {
+		dst.StepReplicas = src.StepReplicas
+	}
+	if src.CanaryReplicas != 0 {
+		dst.CanaryReplicas = src.CanaryReplicas
+	}
+	if src.CanaryWeight != 0 {
+		dst.CanaryWeight = src.CanaryWeight
+	}
+
+	for _, m := range src.Metrics {
+		replaced := false
+		for i := range dst.Metrics {
+			if dst.Metrics[i].Name == m.Name {
+				dst.Metrics[i] = m
+				replaced = true
+			}
+		}
+		if !replaced {
+			dst.Metrics = append(dst.Metrics, m)
+		}
+	}
+	for _, w := range src.Webhooks {
+		replaced := false
+		for i := range dst.Webhooks {
+			if dst.Webhooks[i].Name == w.Name {
+				dst.Webhooks[i] = w
+				replaced = true
+			}
+		}
+		if !replaced {
+			dst.Webhooks = append(dst.Webhooks, w)
+		}
+	}
+	for _, a := range src.Alerts {
+		replaced := false
+		for i := range dst.Alerts {
+			if dst.Alerts[i].Name == a.Name {
+				dst.Alerts[i] = a
+				replaced = true
+			}
+		}
+		if !replaced {
+			dst.Alerts = append(dst.Alerts, a)
+		}
+	}
+}
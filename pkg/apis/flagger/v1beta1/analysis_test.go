@@ -0,0 +1,94 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/flagger/pkg/apis/edas/v1alpha1/route"
+	istiov1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
+)
+
+func TestMergeAnalysis(t *testing.T) {
+	min := float64(99)
+	templates := []CanaryAnalysis{
+		{
+			Interval:  "1m",
+			Threshold: 5,
+			Metrics: []CanaryMetric{
+				{Name: "request-success-rate", ThresholdRange: &CanaryThresholdRange{Min: &min}},
+				{Name: "request-duration"},
+			},
+			Webhooks: []CanaryWebhook{
+				{Name: "load-test", Type: RolloutHook, URL: "http://loadtester.test/"},
+			},
+			Alerts: []CanaryAlert{
+				{Name: "on-call", Severity: SeverityError},
+			},
+		},
+		{
+			Threshold: 10,
+			Alerts: []CanaryAlert{
+				{Name: "dev", Severity: SeverityInfo},
+			},
+		},
+	}
+	inline := &CanaryAnalysis{
+		TemplateRefs: []AnalysisTemplateReference{{Name: "slo"}, {Name: "alerts"}},
+		Interval:     "30s",
+		StepWeight:   10,
+		Metrics: []CanaryMetric{
+			{Name: "request-duration", Interval: "30s"},
+		},
+	}
+
+	merged := MergeAnalysis(inline, templates)
+	require.NotNil(t, merged)
+	assert.Equal(t, "30s", merged.Interval)
+	assert.Equal(t, 10, merged.Threshold)
+	assert.Equal(t, 10, merged.StepWeight)
+	assert.Len(t, merged.TemplateRefs, 2)
+
+	require.Len(t, merged.Metrics, 2)
+	assert.Equal(t, "request-success-rate", merged.Metrics[0].Name)
+	assert.Equal(t, "30s", merged.Metrics[1].Interval)
+	assert.Len(t, merged.Webhooks, 1)
+	assert.Len(t, merged.Alerts, 2)
+
+	// the inline analysis and the templates are not modified
+	assert.Len(t, inline.Metrics, 1)
+	assert.Len(t, templates[0].Alerts, 1)
+	assert.Nil(t, MergeAnalysis(nil, templates))
+}
+
+func TestCanary_SetAnalysisTemplates(t *testing.T) {
+	cd := &Canary{
+		Spec: CanarySpec{
+			Analysis: &CanaryAnalysis{
+				TemplateRefs: []AnalysisTemplateReference{{Name: "slo"}},
+			},
+		},
+	}
+	cd.SetAnalysisTemplates([]CanaryAnalysis{{Interval: "30s", Iterations: 5}})
+	assert.Equal(t, 5, cd.GetAnalysis().Iterations)
+	assert.Equal(t, 0, cd.Spec.Analysis.Iterations)
+
+	// the merged analysis is kept by deep copies and replaced when the templates are set again
+	cdCopy := cd.DeepCopy()
+	assert.Equal(t, "30s", cdCopy.GetAnalysis().Interval)
+	cdCopy.SetAnalysisTemplates([]CanaryAnalysis{{Iterations: 3}})
+	assert.Equal(t, 3, cdCopy.GetAnalysis().Iterations)
+	assert.Equal(t, "", cdCopy.GetAnalysis().Interval)
+}
+
+func TestCanary_HasMatchConditions(t *testing.T) {
+	cd := &Canary{Spec: CanarySpec{Analysis: &CanaryAnalysis{
+		DubboMatch: []route.DubboMatchRequest{{ServiceName: "com.example.DemoService"}},
+	}}}
+	assert.True(t, cd.HasMatchConditions(EDASProvider))
+	assert.False(t, cd.HasMatchConditions(IstioProvider), "the Dubbo conditions are ignored by the istio router")
+
+	cd.Spec.Analysis.Match = []istiov1alpha3.HTTPMatchRequest{{}}
+	assert.True(t, cd.HasMatchConditions(IstioProvider))
+}
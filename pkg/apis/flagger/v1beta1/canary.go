@@ -35,6 +35,59 @@ const (
 	OAMProvider             = "oam-provider"
 )
 
+const (
+	// ActionAnnotation requests a one-shot action, the annotation is removed once the action is processed
+	ActionAnnotation = "flagger.app/action"
+	// ActionByAnnotation records the user that requested the action, it is set by the mutating webhook
+	ActionByAnnotation = "flagger.app/action-by"
+)
+
+// CanaryAction is an imperative action requested with the flagger.app/action annotation
+type CanaryAction string
+
+const (
+	// CanaryActionPromote promotes the canary without finishing the analysis
+	CanaryActionPromote CanaryAction = "promote"
+	// CanaryActionAbort rolls back the canary and marks the analysis as failed
+	CanaryActionAbort CanaryAction = "abort"
+	// CanaryActionRetry restarts the analysis of a failed canary
+	CanaryActionRetry CanaryAction = "retry"
+	// CanaryActionSkipStep advances the canary to the next step, iteration or weight
+	CanaryActionSkipStep CanaryAction = "skip-step"
+)
+
+// CanaryActions are the values accepted by the flagger.app/action annotation
+var CanaryActions = []CanaryAction{
+	CanaryActionPromote,
+	CanaryActionAbort,
+	CanaryActionRetry,
+	CanaryActionSkipStep,
+}
+
+// GetAction returns the action requested with the flagger.app/action annotation
+// and the user that requested it
+func (c *Canary) GetAction() (CanaryAction, string, bool) {
+	action, ok := c.Annotations[ActionAnnotation]
+	if !ok {
+		return "", "", false
+	}
+	actor := c.Annotations[ActionByAnnotation]
+	if actor == "" {
+		actor = "unknown"
+	}
+	return CanaryAction(action), actor, true
+}
+
+// IsValid returns true if the action is supported
+func (a CanaryAction) IsValid() bool {
+	for _, action := range CanaryActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -47,6 +100,9 @@ type Canary struct {
 
 	Spec   CanarySpec   `json:"spec"`
 	Status CanaryStatus `json:"status"`
+
+	// analysis is the inline analysis merged with the referenced templates, it is not persisted
+	analysis *CanaryAnalysis
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -103,6 +159,41 @@ type CanarySpec struct {
 	// revert canary mutation on deletion of canary resource
 	// +optional
 	RevertOnDeletion bool `json:"revertOnDeletion,omitempty"`
+
+	// Suspend freezes the canary analysis, the routes and replicas
+	// are kept as they are until the canary is resumed
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// ReplicaScaling shifts the traffic by scaling the primary and canary replicas
+	// instead of relying only on the mesh router weights
+	// +optional
+	ReplicaScaling *CanaryReplicaScaling `json:"replicaScaling,omitempty"`
+
+	// PodSelector defines the pod labels used by the generated services
+	// to select the primary and canary workloads
+	// +optional
+	PodSelector *CanaryPodSelector `json:"podSelector,omitempty"`
+}
+
+// CanaryReplicaScaling defines how the replicas are distributed between the primary and canary
+type CanaryReplicaScaling struct {
+	// Enabled keeps the canary running after the promotion and scales the primary
+	// and canary replicas according to the analysis maxReplicas and canaryReplicas
+	Enabled bool `json:"enabled"`
+}
+
+// CanaryPodSelector defines the pod labels used by the generated services
+type CanaryPodSelector struct {
+	// DistinguishLabels select the primary and canary pods as separate workloads,
+	// the label values must differ between primary and canary
+	// +optional
+	DistinguishLabels []string `json:"distinguishLabels,omitempty"`
+
+	// GeneralLabels select the primary and canary pods as a whole app,
+	// the label values must be the same for primary and canary
+	// +optional
+	GeneralLabels []string `json:"generalLabels,omitempty"`
 }
 
 // CanaryService defines how ClusterIP services, service mesh or ingress routing objects are generated
@@ -189,6 +280,11 @@ type CanaryService struct {
 
 // CanaryAnalysis is used to describe how the analysis should be done
 type CanaryAnalysis struct {
+	// TemplateRefs references the analysis templates merged into this analysis,
+	// the inline metrics, webhooks and alerts override the template entries with the same name
+	// +optional
+	TemplateRefs []AnalysisTemplateReference `json:"templateRefs,omitempty"`
+
 	// Schedule interval for this canary analysis
 	Interval string `json:"interval"`
 
@@ -216,9 +312,27 @@ type CanaryAnalysis struct {
 	// +optional
 	StepWeightPromotion int `json:"stepWeightPromotion,omitempty"`
 
+	// Steps of the progressive traffic increase,
+	// takes precedence over StepWeight and MaxWeight
+	// +optional
+	Steps []CanaryStep `json:"steps,omitempty"`
+
+	// Schedule restricts the advancement of the canary to release windows
+	// +optional
+	Schedule *CanarySchedule `json:"schedule,omitempty"`
+
+	// Judge scores the time series of the compared metrics instead of their current values
+	// +optional
+	Judge *CanaryJudge `json:"judge,omitempty"`
+
 	// Max number of failed checks before the canary is terminated
 	Threshold int `json:"threshold"`
 
+	// PassScore is the minimum weight of the passed metrics in percentage of the total weight
+	// for the metric checks to pass, defaults to 100
+	// +optional
+	PassScore *float64 `json:"passScore,omitempty"`
+
 	// Alert list for this canary analysis
 	Alerts []CanaryAlert `json:"alerts,omitempty"`
 
@@ -260,6 +374,38 @@ type CanaryAnalysis struct {
 	CanaryWeight int `json:"canaryWeight,omitempty"`
 }
 
+// CanaryStep defines the traffic weight and replicas of a progressive delivery step
+type CanaryStep struct {
+	// Weight is the traffic percentage routed to canary during this step
+	Weight int `json:"weight"`
+
+	// Replicas of the canary workload when the traffic is shifted by scaling the replicas,
+	// defaults to the weight percentage of maxReplicas
+	// +optional
+	Replicas int `json:"replicas,omitempty"`
+
+	// Pause is the minimum duration the canary stays at this step, e.g. 30m
+	// +optional
+	Pause string `json:"pause,omitempty"`
+
+	// Approval halts the advancement at the end of this step
+	// until the confirm-step webhooks return HTTP 200
+	// +optional
+	Approval bool `json:"approval,omitempty"`
+}
+
+// GetPause returns the bake duration of the step, zero if not set or invalid
+func (s *CanaryStep) GetPause() time.Duration {
+	if s.Pause == "" {
+		return 0
+	}
+	pause, err := time.ParseDuration(s.Pause)
+	if err != nil {
+		return 0
+	}
+	return pause
+}
+
 // CanaryMetric holds the reference to metrics used for canary analysis
 type CanaryMetric struct {
 	// Name of the metric
@@ -268,6 +414,10 @@ type CanaryMetric struct {
 	// Interval represents the windows size
 	Interval string `json:"interval,omitempty"`
 
+	// Timeout of the metric query, when not set the query runs until the analysis interval elapses
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
 	// Deprecated: Max value accepted for this metric (replaced by ThresholdRange)
 	Threshold float64 `json:"threshold"`
 
@@ -282,6 +432,78 @@ type CanaryMetric struct {
 	// TemplateRef references a metric template object
 	// +optional
 	TemplateRef *CrossNamespaceObjectReference `json:"templateRef,omitempty"`
+
+	// Comparison judges the canary value against the primary value instead of the threshold range
+	// +optional
+	Comparison *CanaryMetricComparison `json:"comparison,omitempty"`
+
+	// Weight of the metric in the analysis score and in the judge score, defaults to 1
+	// +optional
+	Weight *float64 `json:"weight,omitempty"`
+
+	// Critical fails the metric checks when this metric fails regardless of the score
+	// +optional
+	Critical bool `json:"critical,omitempty"`
+
+	// OnNoData is the policy applied when the query returns no values, defaults to fail
+	// +optional
+	OnNoData *CanaryMetricPolicy `json:"onNoData,omitempty"`
+
+	// OnError is the policy applied when the query fails, defaults to fail
+	// +optional
+	OnError *CanaryMetricPolicy `json:"onError,omitempty"`
+}
+
+// MetricPolicyAction is the outcome of a metric check without a value
+type MetricPolicyAction string
+
+const (
+	// MetricPolicyPass counts the metric as passed
+	MetricPolicyPass MetricPolicyAction = "pass"
+	// MetricPolicyFail counts the metric as failed
+	MetricPolicyFail MetricPolicyAction = "fail"
+	// MetricPolicyHold halts the advancement without counting a failed check
+	MetricPolicyHold MetricPolicyAction = "hold"
+	// MetricPolicyRetry holds the advancement for a number of consecutive runs before failing the metric
+	MetricPolicyRetry MetricPolicyAction = "retry"
+)
+
+// CanaryMetricPolicy defines the outcome of a metric check without a value
+type CanaryMetricPolicy struct {
+	// Action is pass, fail, hold or retry
+	Action MetricPolicyAction `json:"action"`
+
+	// Retries is the number of consecutive runs held before the metric fails, used with retry
+	// +optional
+	Retries int `json:"retries,omitempty"`
+}
+
+// GetAction returns the action of the policy, fail if not set
+func (p *CanaryMetricPolicy) GetAction() MetricPolicyAction {
+	if p == nil || p.Action == "" {
+		return MetricPolicyFail
+	}
+	return p.Action
+}
+
+// GetTimeout returns the timeout of the metric query, zero if not set or invalid
+func (m *CanaryMetric) GetTimeout() time.Duration {
+	if m.Timeout == "" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(m.Timeout)
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// GetWeight returns the weight of the metric in the judge score
+func (m *CanaryMetric) GetWeight() float64 {
+	if m.Weight != nil {
+		return *m.Weight
+	}
+	return 1
 }
 
 // CanaryThresholdRange defines the range used for metrics validation
@@ -330,6 +552,8 @@ const (
 	ConfirmRolloutHook HookType = "confirm-rollout"
 	// ConfirmPromotionHook halt canary promotion until webhook returns HTTP 200
 	ConfirmPromotionHook HookType = "confirm-promotion"
+	// ConfirmStepHook halt the advancement past an analysis step with approval until webhook returns HTTP 200
+	ConfirmStepHook HookType = "confirm-step"
 	// EventHook dispatches Flagger events to the specified endpoint
 	EventHook HookType = "event"
 	// RollbackHook rollback canary analysis if webhook returns HTTP 200
@@ -417,14 +641,25 @@ func (c *Canary) GetProgressDeadlineSeconds() int {
 }
 
 // GetAnalysis returns the analysis v1beta1 or v1alpha3
-// to be removed along with spec.canaryAnalysis in v1
+// to be removed along with spec.canaryAnalysis in v1,
+// the analysis is merged with the templates set with SetAnalysisTemplates
 func (c *Canary) GetAnalysis() *CanaryAnalysis {
+	if c.analysis != nil {
+		return c.analysis
+	}
 	if c.Spec.Analysis != nil {
 		return c.Spec.Analysis
 	}
 	return c.Spec.CanaryAnalysis
 }
 
+// SetAnalysisTemplates merges the analysis templates referenced by the analysis templateRefs
+// into the analysis returned by GetAnalysis, the templates must be in the templateRefs order
+func (c *Canary) SetAnalysisTemplates(templates []CanaryAnalysis) {
+	c.analysis = nil
+	c.analysis = MergeAnalysis(c.GetAnalysis(), templates)
+}
+
 // GetAnalysisInterval returns the canary analysis interval (default 60s)
 func (c *Canary) GetAnalysisInterval() time.Duration {
 	if c.GetAnalysis().Interval == "" {
@@ -443,6 +678,14 @@ func (c *Canary) GetAnalysisInterval() time.Duration {
 	return interval
 }
 
+// GetPassScore returns the minimum score for the metric checks to pass
+func (a *CanaryAnalysis) GetPassScore() float64 {
+	if a.PassScore != nil {
+		return *a.PassScore
+	}
+	return 100
+}
+
 // GetAnalysisThreshold returns the canary threshold (default 1)
 func (c *Canary) GetAnalysisThreshold() int {
 	if c.GetAnalysis().Threshold > 0 {
@@ -458,6 +701,28 @@ func (c *Canary) GetMetricInterval() string {
 	return MetricInterval
 }
 
+// HasMatchConditions returns true if the analysis contains A/B testing match conditions
+// routed by the provider, the Dubbo and Spring Cloud conditions are routed by the EDAS provider only
+func (c *Canary) HasMatchConditions(provider string) bool {
+	analysis := c.GetAnalysis()
+	if len(analysis.Match) > 0 {
+		return true
+	}
+	return provider == EDASProvider && (len(analysis.DubboMatch) > 0 || len(analysis.SpringCloudMatch) > 0)
+}
+
+// GetCurrentStep returns the analysis step recorded in the canary status
+func (c *Canary) GetCurrentStep() (CanaryStep, bool) {
+	if c.GetAnalysis() == nil || c.Status.CurrentStepIndex == nil {
+		return CanaryStep{}, false
+	}
+	index := *c.Status.CurrentStepIndex
+	if index < 0 || index >= len(c.GetAnalysis().Steps) {
+		return CanaryStep{}, false
+	}
+	return c.GetAnalysis().Steps[index], true
+}
+
 // SkipAnalysis returns true if the analysis is nil
 // or if spec.SkipAnalysis is true
 func (c *Canary) SkipAnalysis() bool {
@@ -0,0 +1,152 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ComparisonMode is the way the canary value is compared to the primary value
+type ComparisonMode string
+
+const (
+	// ComparisonRatio compares the relative deviation of the canary value in percentage of the primary value
+	ComparisonRatio ComparisonMode = "ratio"
+	// ComparisonDifference compares the absolute deviation of the canary value from the primary value
+	ComparisonDifference ComparisonMode = "difference"
+)
+
+// ComparisonDirection tells which values of a metric are better
+type ComparisonDirection string
+
+const (
+	// ComparisonLowerIsBetter is used for metrics such as latency or error rate
+	ComparisonLowerIsBetter ComparisonDirection = "lower"
+	// ComparisonHigherIsBetter is used for metrics such as success rate or throughput
+	ComparisonHigherIsBetter ComparisonDirection = "higher"
+)
+
+// Variants of the workload a metric query is rendered for
+const (
+	CanaryVariant  = "canary"
+	PrimaryVariant = "primary"
+)
+
+// CanaryMetricComparison defines how much worse than the primary the canary is allowed to be
+type CanaryMetricComparison struct {
+	// Mode of the comparison: ratio or difference
+	Mode ComparisonMode `json:"mode"`
+
+	// Tolerance is the deviation accepted in the bad direction,
+	// in percentage of the primary value for ratio or in metric units for difference
+	Tolerance float64 `json:"tolerance"`
+
+	// Direction of the better values: lower (default) or higher
+	// +optional
+	Direction ComparisonDirection `json:"direction,omitempty"`
+}
+
+// Compare returns the deviation of the canary value from the primary value
+// and true if the deviation in the bad direction is within the tolerance
+func (c *CanaryMetricComparison) Compare(canary float64, primary float64) (float64, bool) {
+	deviation := canary - primary
+	if c.Mode == ComparisonRatio {
+		switch {
+		case primary != 0:
+			deviation = deviation / math.Abs(primary) * 100
+		case canary == 0:
+			deviation = 0
+		default:
+			deviation = math.Inf(int(math.Copysign(1, canary)))
+		}
+	}
+
+	worse := deviation
+	if c.Direction == ComparisonHigherIsBetter {
+		worse = -deviation
+	}
+	return deviation, worse <= c.Tolerance
+}
+
+// FormatDeviation returns the deviation with its unit
+func (c *CanaryMetricComparison) FormatDeviation(deviation float64) string {
+	if c.Mode == ComparisonRatio {
+		return fmt.Sprintf("%+.2f%%", deviation)
+	}
+	return fmt.Sprintf("%+.2f", deviation)
+}
+
+const (
+	defaultJudgeStep          = 15 * time.Second
+	defaultJudgeConfidence    = 95
+	defaultJudgePassScore     = 95
+	defaultJudgeMarginalScore = 75
+)
+
+// CanaryJudge defines the statistical comparison of the canary and primary time series
+// of the metrics with a comparison and a template reference
+type CanaryJudge struct {
+	// Step is the resolution of the time series, defaults to 15s
+	// +optional
+	Step string `json:"step,omitempty"`
+
+	// Confidence level in percentage used to decide if the canary differs from the primary, defaults to 95
+	// +optional
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// PassScore is the minimum score for the analysis to pass, defaults to 95
+	// +optional
+	PassScore float64 `json:"passScore,omitempty"`
+
+	// MarginalScore is the minimum score for the analysis to hold the canary without failing, defaults to 75
+	// +optional
+	MarginalScore float64 `json:"marginalScore,omitempty"`
+}
+
+// GetStep returns the resolution of the time series
+func (j *CanaryJudge) GetStep() time.Duration {
+	if step, err := time.ParseDuration(j.Step); err == nil && step > 0 {
+		return step
+	}
+	return defaultJudgeStep
+}
+
+// GetConfidence returns the confidence level in percentage
+func (j *CanaryJudge) GetConfidence() float64 {
+	if j.Confidence > 0 {
+		return j.Confidence
+	}
+	return defaultJudgeConfidence
+}
+
+// GetPassScore returns the minimum score for the analysis to pass
+func (j *CanaryJudge) GetPassScore() float64 {
+	if j.PassScore > 0 {
+		return j.PassScore
+	}
+	return defaultJudgePassScore
+}
+
+// GetMarginalScore returns the minimum score for the analysis to hold the canary without failing
+func (j *CanaryJudge) GetMarginalScore() float64 {
+	if j.MarginalScore > 0 {
+		return j.MarginalScore
+	}
+	return defaultJudgeMarginalScore
+}
@@ -0,0 +1,35 @@
+package v1beta1
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanaryMetricComparison_Compare(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparison CanaryMetricComparison
+		canary     float64
+		primary    float64
+		deviation  float64
+		ok         bool
+	}{
+		{"ratio within tolerance", CanaryMetricComparison{Mode: ComparisonRatio, Tolerance: 10}, 105, 100, 5, true},
+		{"ratio worse", CanaryMetricComparison{Mode: ComparisonRatio, Tolerance: 10}, 120, 100, 20, false},
+		{"ratio better", CanaryMetricComparison{Mode: ComparisonRatio, Tolerance: 10}, 50, 100, -50, true},
+		{"ratio higher is better", CanaryMetricComparison{Mode: ComparisonRatio, Tolerance: 1, Direction: ComparisonHigherIsBetter}, 98, 100, -2, false},
+		{"ratio zero primary", CanaryMetricComparison{Mode: ComparisonRatio, Tolerance: 10}, 1, 0, math.Inf(1), false},
+		{"ratio both zero", CanaryMetricComparison{Mode: ComparisonRatio, Tolerance: 0}, 0, 0, 0, true},
+		{"difference within tolerance", CanaryMetricComparison{Mode: ComparisonDifference, Tolerance: 50}, 250, 200, 50, true},
+		{"difference higher is better", CanaryMetricComparison{Mode: ComparisonDifference, Tolerance: 0.5, Direction: ComparisonHigherIsBetter}, 99, 99.9, 99 - 99.9, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviation, ok := tt.comparison.Compare(tt.canary, tt.primary)
+			assert.InDelta(t, tt.deviation, deviation, 0.0001)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CanaryGroupKind = "CanaryGroup"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryGroup orchestrates the release of several canaries in dependency order
+type CanaryGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CanaryGroupSpec   `json:"spec"`
+	Status CanaryGroupStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CanaryGroupList is a list of canary group resources
+type CanaryGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []CanaryGroup `json:"items"`
+}
+
+// CanaryGroupSpec is the specification of the desired behavior of the CanaryGroup
+type CanaryGroupSpec struct {
+	// Members of this group, the canaries must be in the group namespace
+	Members []CanaryGroupMember `json:"members"`
+}
+
+// CanaryGroupMember references a canary and the members it depends on
+type CanaryGroupMember struct {
+	// Name of the canary
+	Name string `json:"name"`
+
+	// DependsOn lists the members that must succeed before this member is started
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// CanaryGroupStatus is used for state persistence (read-only)
+type CanaryGroupStatus struct {
+	// Phase is the aggregated phase of the members
+	Phase CanaryPhase `json:"phase"`
+
+	// Message explains the current phase
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Members status
+	// +optional
+	Members []CanaryGroupMemberStatus `json:"members,omitempty"`
+
+	// LastTransitionTime of the group phase
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// CanaryGroupMemberStatus is the state of a group member
+type CanaryGroupMemberStatus struct {
+	// Name of the canary
+	Name string `json:"name"`
+
+	// Phase of the canary
+	Phase CanaryPhase `json:"phase"`
+
+	// CanaryWeight of the canary
+	// +optional
+	CanaryWeight int `json:"canaryWeight,omitempty"`
+
+	// Held is true while the member waits for its dependencies
+	// +optional
+	Held bool `json:"held,omitempty"`
+
+	// BaselineSpec is the promoted spec hash of the canary when the group release started
+	// +optional
+	BaselineSpec string `json:"baselineSpec,omitempty"`
+
+	// RolledBack is true if the member was reverted after another member failed
+	// +optional
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+// SortMembers returns the member names ordered so that every member comes after its dependencies,
+// an error is returned for duplicate members, unknown dependencies and dependency cycles
+func (g *CanaryGroup) SortMembers() ([]string, error) {
+	dependsOn := make(map[string][]string, len(g.Spec.Members))
+	for _, m := range g.Spec.Members {
+		if _, ok := dependsOn[m.Name]; ok {
+			return nil, fmt.Errorf("member %s is declared more than once", m.Name)
+		}
+		dependsOn[m.Name] = m.DependsOn
+	}
+	for _, m := range g.Spec.Members {
+		for _, dep := range m.DependsOn {
+			if _, ok := dependsOn[dep]; !ok {
+				return nil, fmt.Errorf("member %s depends on %s which is not a member", m.Name, dep)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(g.Spec.Members))
+	order := make([]string, 0, len(g.Spec.Members))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected at member %s", name)
+		}
+		state[name] = visiting
+		for _, dep := range dependsOn[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+	for _, m := range g.Spec.Members {
+		if err := visit(m.Name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// GetMember returns the member with the given canary name
+func (g *CanaryGroup) GetMember(name string) (CanaryGroupMember, bool) {
+	for _, m := range g.Spec.Members {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return CanaryGroupMember{}, false
+}
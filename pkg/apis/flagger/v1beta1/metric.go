@@ -74,6 +74,71 @@ type MetricTemplateProvider struct {
 	// Region of the provider
 	// +optional
 	Region string `json:"region,omitempty"`
+
+	// Web request issued by the web provider
+	// +optional
+	Web *MetricTemplateWebRequest `json:"web,omitempty"`
+
+	// Elasticsearch search request issued by the elasticsearch provider
+	// +optional
+	Elasticsearch *MetricTemplateElasticsearchRequest `json:"elasticsearch,omitempty"`
+
+	// Graphite render options of the graphite provider
+	// +optional
+	Graphite *MetricTemplateGraphiteRequest `json:"graphite,omitempty"`
+
+	// SLS log store queried by the sls provider
+	// +optional
+	SLS *MetricTemplateSLSRequest `json:"sls,omitempty"`
+}
+
+// MetricTemplateWebRequest is the request issued by the web provider to the provider address,
+// the address, the header values and the query are rendered with the metric template model
+type MetricTemplateWebRequest struct {
+	// Method of the request, GET (default) or POST, the query is sent as the body of POST requests
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Headers of the request
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// JSONPath expression selecting the metric value in the response body
+	JSONPath string `json:"jsonPath"`
+
+	// HealthAddress is the URL probed to check the availability of the provider
+	// +optional
+	HealthAddress string `json:"healthAddress,omitempty"`
+}
+
+// MetricTemplateElasticsearchRequest is the request issued by the elasticsearch provider,
+// the query is the rendered request body
+type MetricTemplateElasticsearchRequest struct {
+	// Index pattern searched by the query, e.g. logs-*
+	Index string `json:"index"`
+
+	// JSONPath expression selecting the metric value in the search response,
+	// when empty the query is sent to the count API and the count is returned
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+}
+
+// MetricTemplateGraphiteRequest holds the options of the graphite provider render requests
+type MetricTemplateGraphiteRequest struct {
+	// Aggregation turning the first series into the metric value: last (default), avg or max,
+	// the null data points are ignored
+	// +optional
+	Aggregation string `json:"aggregation,omitempty"`
+}
+
+// MetricTemplateSLSRequest is the Alibaba Cloud Log Service (SLS) log store queried by the sls provider,
+// the provider address is the SLS endpoint of the region
+type MetricTemplateSLSRequest struct {
+	// Project of the log store
+	Project string `json:"project"`
+
+	// Logstore queried over the metric interval
+	Logstore string `json:"logstore"`
 }
 
 // MetricTemplateModel is the query template model
@@ -84,6 +149,7 @@ type MetricTemplateModel struct {
 	Service   string `json:"service"`
 	Ingress   string `json:"ingress"`
 	Interval  string `json:"interval"`
+	Variant   string `json:"variant"`
 }
 
 // TemplateFunctions returns a map of functions, one for each model field
@@ -95,6 +161,7 @@ func (mtm *MetricTemplateModel) TemplateFunctions() template.FuncMap {
 		"service":   func() string { return mtm.Service },
 		"ingress":   func() string { return mtm.Ingress },
 		"interval":  func() string { return mtm.Interval },
+		"variant":   func() string { return mtm.Variant },
 	}
 }
 
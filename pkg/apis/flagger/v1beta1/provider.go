@@ -10,4 +10,5 @@ const (
 	NGINXProvider      string = "nginx"
 	KubernetesProvider string = "kubernetes"
 	SkipperProvider    string = "skipper"
+	EDASProvider       string = "edas"
 )
@@ -51,6 +51,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&MetricTemplateList{},
 		&AlertProvider{},
 		&AlertProviderList{},
+		&CanaryGroup{},
+		&CanaryGroupList{},
+		&AnalysisTemplate{},
+		&AnalysisTemplateList{},
+		&ClusterAnalysisTemplate{},
+		&ClusterAnalysisTemplateList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -0,0 +1,178 @@
+/*
+Copyright The Flagger Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CanarySchedule holds the release windows of a canary analysis
+type CanarySchedule struct {
+	// Allowed windows, when set the canary advances only inside one of them
+	// +optional
+	Allowed []CanaryTimeWindow `json:"allowed,omitempty"`
+
+	// Blackout windows during which the canary doesn't advance,
+	// blackout windows take precedence over the allowed ones
+	// +optional
+	Blackout []CanaryTimeWindow `json:"blackout,omitempty"`
+}
+
+// CanaryTimeWindow is a daily time range on a set of week days
+type CanaryTimeWindow struct {
+	// Days of the week (Mon, Tue, ...) or ranges of days (Mon-Fri), every day if empty
+	// +optional
+	Days []string `json:"days,omitempty"`
+
+	// Start time of the window in HH:MM format, defaults to 00:00
+	// +optional
+	Start string `json:"start,omitempty"`
+
+	// End time of the window in HH:MM format, defaults to 24:00,
+	// a window that ends before it starts spans midnight
+	// +optional
+	End string `json:"end,omitempty"`
+
+	// TimeZone of the window in IANA format e.g. Europe/Berlin, defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// IsOpen returns true if the canary is allowed to advance at the given time
+func (s *CanarySchedule) IsOpen(t time.Time) (bool, error) {
+	for _, w := range s.Blackout {
+		in, err := w.Contains(t)
+		if err != nil {
+			return false, err
+		}
+		if in {
+			return false, nil
+		}
+	}
+	if len(s.Allowed) == 0 {
+		return true, nil
+	}
+	for _, w := range s.Allowed {
+		in, err := w.Contains(t)
+		if err != nil {
+			return false, err
+		}
+		if in {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NextOpen returns the next minute the canary is allowed to advance within a week
+func (s *CanarySchedule) NextOpen(t time.Time) (time.Time, bool) {
+	next := t.Truncate(time.Minute)
+	for i := 0; i < 8*24*60; i++ {
+		next = next.Add(time.Minute)
+		if open, err := s.IsOpen(next); err != nil {
+			return time.Time{}, false
+		} else if open {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Contains returns true if the given time is inside the window,
+// the days of a window spanning midnight refer to the day the window starts
+func (w *CanaryTimeWindow) Contains(t time.Time) (bool, error) {
+	loc, err := time.LoadLocation(w.TimeZone)
+	if err != nil {
+		return false, fmt.Errorf("invalid time zone %s: %w", w.TimeZone, err)
+	}
+	days, err := w.weekdays()
+	if err != nil {
+		return false, err
+	}
+	start, err := parseTimeOfDay(w.Start, 0)
+	if err != nil {
+		return false, err
+	}
+	end, err := parseTimeOfDay(w.End, 24*60)
+	if err != nil {
+		return false, err
+	}
+
+	local := t.In(loc)
+	minutes := local.Hour()*60 + local.Minute()
+	today := days[local.Weekday()]
+	if start < end {
+		return today && minutes >= start && minutes < end, nil
+	}
+	yesterday := days[local.AddDate(0, 0, -1).Weekday()]
+	return (today && minutes >= start) || (yesterday && minutes < end), nil
+}
+
+func (w *CanaryTimeWindow) weekdays() (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool, 7)
+	if len(w.Days) == 0 {
+		for _, d := range weekdays {
+			days[d] = true
+		}
+		return days, nil
+	}
+	for _, value := range w.Days {
+		bounds := strings.SplitN(value, "-", 2)
+		from, ok := weekdays[strings.ToLower(strings.TrimSpace(bounds[0]))]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %s", value)
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, ok = weekdays[strings.ToLower(strings.TrimSpace(bounds[1]))]; !ok {
+				return nil, fmt.Errorf("invalid day range %s", value)
+			}
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseTimeOfDay returns the minutes since midnight of a HH:MM value
+func parseTimeOfDay(value string, defaultMinutes int) (int, error) {
+	if value == "" {
+		return defaultMinutes, nil
+	}
+	var hours, minutes int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hours, &minutes); err != nil ||
+		hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("invalid time of day %s, expected HH:MM", value)
+	}
+	return hours*60 + minutes, nil
+}
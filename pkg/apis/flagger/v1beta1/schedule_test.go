@@ -0,0 +1,68 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanarySchedule_IsOpen(t *testing.T) {
+	schedule := &CanarySchedule{
+		Allowed: []CanaryTimeWindow{
+			{Days: []string{"Mon-Thu"}, Start: "09:00", End: "17:00", TimeZone: "Europe/Berlin"},
+			{Days: []string{"Sun"}, Start: "22:00", End: "02:00", TimeZone: "Europe/Berlin"},
+		},
+		Blackout: []CanaryTimeWindow{
+			{Start: "12:00", End: "13:00", TimeZone: "Europe/Berlin"},
+		},
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		time time.Time
+		open bool
+	}{
+		// Monday morning
+		{time.Date(2020, 10, 12, 9, 30, 0, 0, berlin), true},
+		// Monday lunch blackout
+		{time.Date(2020, 10, 12, 12, 15, 0, 0, berlin), false},
+		// Monday evening
+		{time.Date(2020, 10, 12, 17, 0, 0, 0, berlin), false},
+		// Friday morning
+		{time.Date(2020, 10, 16, 10, 0, 0, 0, berlin), false},
+		// Sunday night window spanning midnight
+		{time.Date(2020, 10, 18, 23, 0, 0, 0, berlin), true},
+		{time.Date(2020, 10, 19, 1, 0, 0, 0, berlin), true},
+		// Monday night is not part of the Sunday window
+		{time.Date(2020, 10, 20, 1, 0, 0, 0, berlin), false},
+		// same instant in UTC
+		{time.Date(2020, 10, 12, 7, 30, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		open, err := schedule.IsOpen(tt.time)
+		require.NoError(t, err)
+		assert.Equal(t, tt.open, open, tt.time.String())
+	}
+
+	next, ok := schedule.NextOpen(time.Date(2020, 10, 16, 10, 0, 0, 0, berlin))
+	require.True(t, ok)
+	assert.True(t, next.Equal(time.Date(2020, 10, 18, 22, 0, 0, 0, berlin)), next.String())
+}
+
+func TestCanaryTimeWindow_Invalid(t *testing.T) {
+	windows := []CanaryTimeWindow{
+		{TimeZone: "Mars/Olympus"},
+		{Days: []string{"Friday"}},
+		{Days: []string{"Mon-Funday"}},
+		{Start: "25:00"},
+		{End: "9am"},
+	}
+	for _, w := range windows {
+		_, err := w.Contains(time.Now())
+		assert.Error(t, err)
+	}
+}
@@ -11,6 +11,31 @@ type CanaryConditionType string
 const (
 	// PromotedType refers to the result of the last canary analysis
 	PromotedType CanaryConditionType = "Promoted"
+	// SuspendedType refers to the canary analysis being frozen by spec.suspend
+	SuspendedType CanaryConditionType = "Suspended"
+	// ReadyType is true when the primary runs the promoted revision and no canary run is underway
+	ReadyType CanaryConditionType = "Ready"
+	// ProgressingType is true while the canary is initialized, analysed or promoted
+	ProgressingType CanaryConditionType = "Progressing"
+	// AwaitingApprovalType is true while the canary run is halted by a confirm-rollout webhook
+	AwaitingApprovalType CanaryConditionType = "AwaitingApproval"
+	// RolledBackType is true when the last canary run failed and the traffic was routed back to the primary
+	RolledBackType CanaryConditionType = "RolledBack"
+	// StalledType is true when the canary workload didn't become ready within the progress deadline,
+	// it's cleared when a new canary run starts
+	StalledType CanaryConditionType = "Stalled"
+)
+
+// Reasons of the status conditions in addition to the canary phases
+const (
+	// ConditionReasonWaitingForApproval means a confirm-rollout webhook halts the canary run
+	ConditionReasonWaitingForApproval = "WaitingForApproval"
+	// ConditionReasonReleaseWindowClosed means the canary run is halted outside of the release windows
+	ConditionReasonReleaseWindowClosed = "ReleaseWindowClosed"
+	// ConditionReasonAnalysisFailed means the canary run was rolled back
+	ConditionReasonAnalysisFailed = "AnalysisFailed"
+	// ConditionReasonProgressDeadlineExceeded means the canary workload didn't become ready in time
+	ConditionReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
 )
 
 // CanaryCondition is a status condition for a Canary
@@ -73,6 +98,12 @@ type CanaryStatus struct {
 	CanaryWeight     int         `json:"canaryWeight"`
 	CanaryReplicas   int         `json:"canaryReplicas"`
 	Iterations       int         `json:"iterations"`
+	// CurrentStepIndex is the index of the analysis step the canary is at
+	// +optional
+	CurrentStepIndex *int `json:"currentStepIndex,omitempty"`
+	// CurrentStepStartTime is the time the canary reached the current step
+	// +optional
+	CurrentStepStartTime *metav1.Time `json:"currentStepStartTime,omitempty"`
 	// +optional
 	TrackedConfigs *map[string]string `json:"trackedConfigs,omitempty"`
 	// +optional
@@ -81,6 +112,122 @@ type CanaryStatus struct {
 	LastPromotedSpec string `json:"lastPromotedSpec,omitempty"`
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// ObservedGeneration is the generation of the canary spec the status was computed from
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	Conditions []CanaryCondition `json:"conditions,omitempty"`
+	// Analysis is the outcome of the last metric checks
+	// +optional
+	Analysis *CanaryAnalysisStatus `json:"analysis,omitempty"`
+	// StartTime is the time the current canary run started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// AnalysisHistory holds the records of the last advancements, the oldest record first
+	// +optional
+	AnalysisHistory []CanaryAnalysisRecord `json:"analysisHistory,omitempty"`
+	// Runs holds the summaries of the last completed canary runs, the oldest run first
+	// +optional
+	Runs []CanaryRunStatus `json:"runs,omitempty"`
+}
+
+// CanaryAnalysisRecord is the outcome of the checks run during an advancement of the canary
+type CanaryAnalysisRecord struct {
+	// Time of the advancement
+	Time metav1.Time `json:"time"`
+	// CanaryWeight is the traffic weight routed to the canary during the checks
+	CanaryWeight int `json:"canaryWeight"`
+	// Iteration of the analysis during the checks
+	Iteration int `json:"iteration"`
+	// Metrics is the outcome of the metric checks
+	// +optional
+	Metrics *CanaryAnalysisStatus `json:"metrics,omitempty"`
+	// Judge is the outcome of the statistical judge
+	// +optional
+	Judge *CanaryJudgeStatus `json:"judge,omitempty"`
+	// Webhooks is the outcome of the webhooks
+	// +optional
+	Webhooks []CanaryWebhookStatus `json:"webhooks,omitempty"`
+}
+
+// CanaryJudgeStatus is the outcome of the statistical judge
+type CanaryJudgeStatus struct {
+	// Score is the weighted score of the judged metrics
+	Score float64 `json:"score"`
+	// Metrics is the verdict of each judged metric
+	// +optional
+	Metrics []CanaryMetricStatus `json:"metrics,omitempty"`
+}
+
+// CanaryWebhookStatus is the outcome of a webhook call
+type CanaryWebhookStatus struct {
+	// Name of the webhook
+	Name string `json:"name"`
+	// Type of the webhook
+	Type HookType `json:"type"`
+	// Passed is true if the webhook returned HTTP 200
+	Passed bool `json:"passed"`
+	// Message explains why the call failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// CanaryRunStatus is the summary of a completed canary run
+type CanaryRunStatus struct {
+	// Phase is Succeeded if the canary was promoted, Failed if it was rolled back
+	Phase CanaryPhase `json:"phase"`
+	// Revision is the last applied spec of the canary, promoted if the run succeeded
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// StartTime is the time the run started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the run completed
+	CompletionTime metav1.Time `json:"completionTime"`
+	// Duration of the run
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// FailedChecks is the number of failed checks of the run
+	FailedChecks int `json:"failedChecks"`
+}
+
+// CanaryAnalysisStatus is the outcome of the metric checks of an analysis run
+type CanaryAnalysisStatus struct {
+	// Score is the weight of the passed metrics in percentage of the total weight
+	Score float64 `json:"score"`
+	// Passed is false if a critical metric failed, the score is below the pass score or a metric is held
+	Passed bool `json:"passed"`
+	// Held is true if the advancement is held by the no-data or error policy of a metric
+	// +optional
+	Held bool `json:"held,omitempty"`
+	// Metrics is the outcome of each metric check
+	// +optional
+	Metrics []CanaryMetricStatus `json:"metrics,omitempty"`
+	// LastRunTime is the time of the analysis run
+	LastRunTime metav1.Time `json:"lastRunTime"`
+}
+
+// CanaryMetricStatus is the outcome of a metric check
+type CanaryMetricStatus struct {
+	// Name of the metric
+	Name string `json:"name"`
+	// Value of the metric, not set if the query failed
+	// +optional
+	Value *float64 `json:"value,omitempty"`
+	// Passed is true if the value is within the threshold range or the comparison tolerance
+	Passed bool `json:"passed"`
+	// Held is true if the metric holds the advancement because of its no-data or error policy
+	// +optional
+	Held bool `json:"held,omitempty"`
+	// Retries is the number of consecutive runs held by the retry policy
+	// +optional
+	Retries int `json:"retries,omitempty"`
+	// Weight of the metric in the score
+	Weight float64 `json:"weight"`
+	// Critical is true if the failure of the metric fails the analysis
+	// +optional
+	Critical bool `json:"critical,omitempty"`
+	// Message explains why the check failed
+	// +optional
+	Message string `json:"message,omitempty"`
 }
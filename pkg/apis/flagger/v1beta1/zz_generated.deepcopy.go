@@ -21,8 +21,10 @@ limitations under the License.
 package v1beta1
 
 import (
+	route "github.com/weaveworks/flagger/pkg/apis/edas/v1alpha1/route"
 	v1alpha3 "github.com/weaveworks/flagger/pkg/apis/istio/v1alpha3"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -149,6 +151,99 @@ func (in *AlertProviderStatus) DeepCopy() *AlertProviderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnalysisTemplate) DeepCopyInto(out *AnalysisTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnalysisTemplate.
+func (in *AnalysisTemplate) DeepCopy() *AnalysisTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(AnalysisTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AnalysisTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnalysisTemplateList) DeepCopyInto(out *AnalysisTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AnalysisTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnalysisTemplateList.
+func (in *AnalysisTemplateList) DeepCopy() *AnalysisTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(AnalysisTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AnalysisTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnalysisTemplateReference) DeepCopyInto(out *AnalysisTemplateReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnalysisTemplateReference.
+func (in *AnalysisTemplateReference) DeepCopy() *AnalysisTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(AnalysisTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnalysisTemplateSpec) DeepCopyInto(out *AnalysisTemplateSpec) {
+	*out = *in
+	in.Analysis.DeepCopyInto(&out.Analysis)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnalysisTemplateSpec.
+func (in *AnalysisTemplateSpec) DeepCopy() *AnalysisTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AnalysisTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Canary) DeepCopyInto(out *Canary) {
 	*out = *in
@@ -156,6 +251,11 @@ func (in *Canary) DeepCopyInto(out *Canary) {
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
+	if in.analysis != nil {
+		in, out := &in.analysis, &out.analysis
+		*out = new(CanaryAnalysis)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -178,137 +278,585 @@ func (in *Canary) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryAlert) DeepCopyInto(out *CanaryAlert) {
+func (in *CanaryAlert) DeepCopyInto(out *CanaryAlert) {
+	*out = *in
+	out.ProviderRef = in.ProviderRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAlert.
+func (in *CanaryAlert) DeepCopy() *CanaryAlert {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAlert)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
+	*out = *in
+	if in.TemplateRefs != nil {
+		in, out := &in.TemplateRefs, &out.TemplateRefs
+		*out = make([]AnalysisTemplateReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]CanaryStep, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(CanarySchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Judge != nil {
+		in, out := &in.Judge, &out.Judge
+		*out = new(CanaryJudge)
+		**out = **in
+	}
+	if in.PassScore != nil {
+		in, out := &in.PassScore, &out.PassScore
+		*out = new(float64)
+		**out = **in
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = make([]CanaryAlert, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]CanaryWebhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DubboMatch != nil {
+		in, out := &in.DubboMatch, &out.DubboMatch
+		*out = make([]route.DubboMatchRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SpringCloudMatch != nil {
+		in, out := &in.SpringCloudMatch, &out.SpringCloudMatch
+		*out = make([]route.SpringCloudMatchRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysis.
+func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysisRecord) DeepCopyInto(out *CanaryAnalysisRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(CanaryAnalysisStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Judge != nil {
+		in, out := &in.Judge, &out.Judge
+		*out = new(CanaryJudgeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]CanaryWebhookStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysisRecord.
+func (in *CanaryAnalysisRecord) DeepCopy() *CanaryAnalysisRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysisRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysisStatus) DeepCopyInto(out *CanaryAnalysisStatus) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastRunTime.DeepCopyInto(&out.LastRunTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysisStatus.
+func (in *CanaryAnalysisStatus) DeepCopy() *CanaryAnalysisStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysisStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCondition) DeepCopyInto(out *CanaryCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCondition.
+func (in *CanaryCondition) DeepCopy() *CanaryCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGroup) DeepCopyInto(out *CanaryGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGroup.
+func (in *CanaryGroup) DeepCopy() *CanaryGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGroupList) DeepCopyInto(out *CanaryGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGroupList.
+func (in *CanaryGroupList) DeepCopy() *CanaryGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGroupMember) DeepCopyInto(out *CanaryGroupMember) {
+	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGroupMember.
+func (in *CanaryGroupMember) DeepCopy() *CanaryGroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGroupMemberStatus) DeepCopyInto(out *CanaryGroupMemberStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGroupMemberStatus.
+func (in *CanaryGroupMemberStatus) DeepCopy() *CanaryGroupMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGroupMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGroupSpec) DeepCopyInto(out *CanaryGroupSpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]CanaryGroupMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGroupSpec.
+func (in *CanaryGroupSpec) DeepCopy() *CanaryGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryGroupStatus) DeepCopyInto(out *CanaryGroupStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]CanaryGroupMemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryGroupStatus.
+func (in *CanaryGroupStatus) DeepCopy() *CanaryGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryJudge) DeepCopyInto(out *CanaryJudge) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryJudge.
+func (in *CanaryJudge) DeepCopy() *CanaryJudge {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryJudge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryJudgeStatus) DeepCopyInto(out *CanaryJudgeStatus) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetricStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryJudgeStatus.
+func (in *CanaryJudgeStatus) DeepCopy() *CanaryJudgeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryJudgeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryList) DeepCopyInto(out *CanaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Canary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryList.
+func (in *CanaryList) DeepCopy() *CanaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+	*out = *in
+	if in.ThresholdRange != nil {
+		in, out := &in.ThresholdRange, &out.ThresholdRange
+		*out = new(CanaryThresholdRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(CrossNamespaceObjectReference)
+		**out = **in
+	}
+	if in.Comparison != nil {
+		in, out := &in.Comparison, &out.Comparison
+		*out = new(CanaryMetricComparison)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(float64)
+		**out = **in
+	}
+	if in.OnNoData != nil {
+		in, out := &in.OnNoData, &out.OnNoData
+		*out = new(CanaryMetricPolicy)
+		**out = **in
+	}
+	if in.OnError != nil {
+		in, out := &in.OnError, &out.OnError
+		*out = new(CanaryMetricPolicy)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
+func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricComparison) DeepCopyInto(out *CanaryMetricComparison) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricComparison.
+func (in *CanaryMetricComparison) DeepCopy() *CanaryMetricComparison {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricComparison)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricPolicy) DeepCopyInto(out *CanaryMetricPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricPolicy.
+func (in *CanaryMetricPolicy) DeepCopy() *CanaryMetricPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricStatus) DeepCopyInto(out *CanaryMetricStatus) {
 	*out = *in
-	out.ProviderRef = in.ProviderRef
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(float64)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAlert.
-func (in *CanaryAlert) DeepCopy() *CanaryAlert {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricStatus.
+func (in *CanaryMetricStatus) DeepCopy() *CanaryMetricStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryAlert)
+	out := new(CanaryMetricStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
+func (in *CanaryPodSelector) DeepCopyInto(out *CanaryPodSelector) {
 	*out = *in
-	if in.Alerts != nil {
-		in, out := &in.Alerts, &out.Alerts
-		*out = make([]CanaryAlert, len(*in))
+	if in.DistinguishLabels != nil {
+		in, out := &in.DistinguishLabels, &out.DistinguishLabels
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Metrics != nil {
-		in, out := &in.Metrics, &out.Metrics
-		*out = make([]CanaryMetric, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Webhooks != nil {
-		in, out := &in.Webhooks, &out.Webhooks
-		*out = make([]CanaryWebhook, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Match != nil {
-		in, out := &in.Match, &out.Match
-		*out = make([]v1alpha3.HTTPMatchRequest, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.GeneralLabels != nil {
+		in, out := &in.GeneralLabels, &out.GeneralLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysis.
-func (in *CanaryAnalysis) DeepCopy() *CanaryAnalysis {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryPodSelector.
+func (in *CanaryPodSelector) DeepCopy() *CanaryPodSelector {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryAnalysis)
+	out := new(CanaryPodSelector)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryCondition) DeepCopyInto(out *CanaryCondition) {
+func (in *CanaryReplicaScaling) DeepCopyInto(out *CanaryReplicaScaling) {
 	*out = *in
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCondition.
-func (in *CanaryCondition) DeepCopy() *CanaryCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryReplicaScaling.
+func (in *CanaryReplicaScaling) DeepCopy() *CanaryReplicaScaling {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryCondition)
+	out := new(CanaryReplicaScaling)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryList) DeepCopyInto(out *CanaryList) {
+func (in *CanaryRunStatus) DeepCopyInto(out *CanaryRunStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Canary, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryList.
-func (in *CanaryList) DeepCopy() *CanaryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRunStatus.
+func (in *CanaryRunStatus) DeepCopy() *CanaryRunStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryList)
+	out := new(CanaryRunStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CanaryList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
+func (in *CanarySchedule) DeepCopyInto(out *CanarySchedule) {
 	*out = *in
-	if in.ThresholdRange != nil {
-		in, out := &in.ThresholdRange, &out.ThresholdRange
-		*out = new(CanaryThresholdRange)
-		(*in).DeepCopyInto(*out)
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]CanaryTimeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.TemplateRef != nil {
-		in, out := &in.TemplateRef, &out.TemplateRef
-		*out = new(CrossNamespaceObjectReference)
-		**out = **in
+	if in.Blackout != nil {
+		in, out := &in.Blackout, &out.Blackout
+		*out = make([]CanaryTimeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetric.
-func (in *CanaryMetric) DeepCopy() *CanaryMetric {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySchedule.
+func (in *CanarySchedule) DeepCopy() *CanarySchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(CanaryMetric)
+	out := new(CanarySchedule)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -427,6 +975,16 @@ func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ReplicaScaling != nil {
+		in, out := &in.ReplicaScaling, &out.ReplicaScaling
+		*out = new(CanaryReplicaScaling)
+		**out = **in
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(CanaryPodSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -443,6 +1001,15 @@ func (in *CanarySpec) DeepCopy() *CanarySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 	*out = *in
+	if in.CurrentStepIndex != nil {
+		in, out := &in.CurrentStepIndex, &out.CurrentStepIndex
+		*out = new(int)
+		**out = **in
+	}
+	if in.CurrentStepStartTime != nil {
+		in, out := &in.CurrentStepStartTime, &out.CurrentStepStartTime
+		*out = (*in).DeepCopy()
+	}
 	if in.TrackedConfigs != nil {
 		in, out := &in.TrackedConfigs, &out.TrackedConfigs
 		*out = new(map[string]string)
@@ -462,6 +1029,29 @@ func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Analysis != nil {
+		in, out := &in.Analysis, &out.Analysis
+		*out = new(CanaryAnalysisStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.AnalysisHistory != nil {
+		in, out := &in.AnalysisHistory, &out.AnalysisHistory
+		*out = make([]CanaryAnalysisRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = make([]CanaryRunStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -475,6 +1065,22 @@ func (in *CanaryStatus) DeepCopy() *CanaryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStep) DeepCopyInto(out *CanaryStep) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStep.
+func (in *CanaryStep) DeepCopy() *CanaryStep {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryThresholdRange) DeepCopyInto(out *CanaryThresholdRange) {
 	*out = *in
@@ -501,6 +1107,27 @@ func (in *CanaryThresholdRange) DeepCopy() *CanaryThresholdRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryTimeWindow) DeepCopyInto(out *CanaryTimeWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryTimeWindow.
+func (in *CanaryTimeWindow) DeepCopy() *CanaryTimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryTimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryWebhook) DeepCopyInto(out *CanaryWebhook) {
 	*out = *in
@@ -551,6 +1178,82 @@ func (in *CanaryWebhookPayload) DeepCopy() *CanaryWebhookPayload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryWebhookStatus) DeepCopyInto(out *CanaryWebhookStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryWebhookStatus.
+func (in *CanaryWebhookStatus) DeepCopy() *CanaryWebhookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryWebhookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAnalysisTemplate) DeepCopyInto(out *ClusterAnalysisTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAnalysisTemplate.
+func (in *ClusterAnalysisTemplate) DeepCopy() *ClusterAnalysisTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAnalysisTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterAnalysisTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAnalysisTemplateList) DeepCopyInto(out *ClusterAnalysisTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterAnalysisTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAnalysisTemplateList.
+func (in *ClusterAnalysisTemplateList) DeepCopy() *ClusterAnalysisTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAnalysisTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterAnalysisTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossNamespaceObjectReference) DeepCopyInto(out *CrossNamespaceObjectReference) {
 	*out = *in
@@ -643,6 +1346,38 @@ func (in *MetricTemplateCondition) DeepCopy() *MetricTemplateCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplateElasticsearchRequest) DeepCopyInto(out *MetricTemplateElasticsearchRequest) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplateElasticsearchRequest.
+func (in *MetricTemplateElasticsearchRequest) DeepCopy() *MetricTemplateElasticsearchRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplateElasticsearchRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplateGraphiteRequest) DeepCopyInto(out *MetricTemplateGraphiteRequest) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplateGraphiteRequest.
+func (in *MetricTemplateGraphiteRequest) DeepCopy() *MetricTemplateGraphiteRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplateGraphiteRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricTemplateList) DeepCopyInto(out *MetricTemplateList) {
 	*out = *in
@@ -700,6 +1435,26 @@ func (in *MetricTemplateProvider) DeepCopyInto(out *MetricTemplateProvider) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.Web != nil {
+		in, out := &in.Web, &out.Web
+		*out = new(MetricTemplateWebRequest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Elasticsearch != nil {
+		in, out := &in.Elasticsearch, &out.Elasticsearch
+		*out = new(MetricTemplateElasticsearchRequest)
+		**out = **in
+	}
+	if in.Graphite != nil {
+		in, out := &in.Graphite, &out.Graphite
+		*out = new(MetricTemplateGraphiteRequest)
+		**out = **in
+	}
+	if in.SLS != nil {
+		in, out := &in.SLS, &out.SLS
+		*out = new(MetricTemplateSLSRequest)
+		**out = **in
+	}
 	return
 }
 
@@ -713,6 +1468,22 @@ func (in *MetricTemplateProvider) DeepCopy() *MetricTemplateProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplateSLSRequest) DeepCopyInto(out *MetricTemplateSLSRequest) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplateSLSRequest.
+func (in *MetricTemplateSLSRequest) DeepCopy() *MetricTemplateSLSRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplateSLSRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricTemplateSpec) DeepCopyInto(out *MetricTemplateSpec) {
 	*out = *in
@@ -752,3 +1523,26 @@ func (in *MetricTemplateStatus) DeepCopy() *MetricTemplateStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTemplateWebRequest) DeepCopyInto(out *MetricTemplateWebRequest) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTemplateWebRequest.
+func (in *MetricTemplateWebRequest) DeepCopy() *MetricTemplateWebRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTemplateWebRequest)
+	in.DeepCopyInto(out)
+	return out
+}
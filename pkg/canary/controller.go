@@ -12,6 +12,10 @@ type Controller interface {
 	SetStatusFailedChecks(canary *flaggerv1.Canary, val int) error
 	SetStatusWeight(canary *flaggerv1.Canary, val int) error
 	SetStatusIterations(canary *flaggerv1.Canary, val int) error
+	SetStatusStep(canary *flaggerv1.Canary, index int) error
+	SetStatusAnalysis(canary *flaggerv1.Canary, record flaggerv1.CanaryAnalysisRecord) error
+	SetStatusSuspended(canary *flaggerv1.Canary, suspended bool) error
+	SetStatusStalled(canary *flaggerv1.Canary, message string) error
 	SetStatusPhase(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error
 	Initialize(canary *flaggerv1.Canary) error
 	Promote(canary *flaggerv1.Canary) error
@@ -20,4 +24,5 @@ type Controller interface {
 	ScaleToZero(canary *flaggerv1.Canary) error
 	ScaleFromZero(canary *flaggerv1.Canary) error
 	Finalize(canary *flaggerv1.Canary) error
+	RevertTarget(canary *flaggerv1.Canary) error
 }
@@ -298,6 +298,11 @@ func (c *DaemonSetController) HaveDependenciesChanged(cd *flaggerv1.Canary) (boo
 	return c.configTracker.HasConfigChanged(cd)
 }
 
+// RevertTarget is not supported for the DaemonSet kind
+func (c *DaemonSetController) RevertTarget(cd *flaggerv1.Canary) error {
+	return fmt.Errorf("reverting %s %s.%s is not supported", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+}
+
 //Finalize scale the reference instance from zero
 func (c *DaemonSetController) Finalize(cd *flaggerv1.Canary) error {
 	if err := c.ScaleFromZero(cd); err != nil {
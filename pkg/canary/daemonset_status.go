@@ -51,6 +51,26 @@ func (c *DaemonSetController) SetStatusIterations(cd *flaggerv1.Canary, val int)
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusStep updates the canary status step index and weight
+func (c *DaemonSetController) SetStatusStep(cd *flaggerv1.Canary, index int) error {
+	return setStatusStep(c.flaggerClient, cd, index)
+}
+
+// SetStatusAnalysis appends the record to the canary status analysis history
+func (c *DaemonSetController) SetStatusAnalysis(cd *flaggerv1.Canary, record flaggerv1.CanaryAnalysisRecord) error {
+	return setStatusAnalysis(c.flaggerClient, cd, record)
+}
+
+// SetStatusSuspended updates the canary status suspended condition
+func (c *DaemonSetController) SetStatusSuspended(cd *flaggerv1.Canary, suspended bool) error {
+	return setStatusSuspended(c.flaggerClient, cd, suspended)
+}
+
+// SetStatusStalled sets the canary status stalled condition
+func (c *DaemonSetController) SetStatusStalled(cd *flaggerv1.Canary, message string) error {
+	return setStatusStalled(c.flaggerClient, cd, message)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *DaemonSetController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
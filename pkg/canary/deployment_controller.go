@@ -3,6 +3,7 @@ package canary
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
@@ -18,6 +19,8 @@ import (
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
 // DeploymentController is managing the operations for Kubernetes Deployment kind
 type DeploymentController struct {
 	kubeClient    kubernetes.Interface
@@ -378,6 +381,51 @@ func (c *DeploymentController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bo
 	return c.configTracker.HasConfigChanged(cd)
 }
 
+// RevertTarget restores the canary deployment pod spec from the revision that preceded the current one,
+// the reverted spec is detected as a new revision and goes through the canary analysis
+func (c *DeploymentController) RevertTarget(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s selector error: %w", targetName, cd.Namespace, err)
+	}
+	rsList, err := c.kubeClient.AppsV1().ReplicaSets(cd.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("replicasets %s.%s list query error: %w", targetName, cd.Namespace, err)
+	}
+
+	current := deploymentRevision(&dep.ObjectMeta)
+	var previous *appsv1.ReplicaSet
+	for i := range rsList.Items {
+		rs := &rsList.Items[i]
+		if !metav1.IsControlledBy(rs, dep) {
+			continue
+		}
+		revision := deploymentRevision(&rs.ObjectMeta)
+		if revision < current && (previous == nil || revision > deploymentRevision(&previous.ObjectMeta)) {
+			previous = rs
+		}
+	}
+	if previous == nil {
+		return fmt.Errorf("deployment %s.%s has no revision before %d", targetName, cd.Namespace, current)
+	}
+
+	depCopy := dep.DeepCopy()
+	depCopy.Spec.Template = *previous.Spec.Template.DeepCopy()
+	delete(depCopy.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	_, err = c.kubeClient.AppsV1().Deployments(cd.Namespace).Update(context.TODO(), depCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s update query error: %w", targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
 // Finalize will set the replica count from the primary to the reference instance.  This method is used
 // during a delete to attempt to revert the deployment back to the original state.  Error is returned if unable
 // update the reference deployment replicas to the primary replicas
@@ -428,3 +476,12 @@ func (c *DeploymentController) scale(cd *flaggerv1.Canary, replicas int32) error
 	}
 	return nil
 }
+
+// deploymentRevision returns the revision set by the deployment controller
+func deploymentRevision(meta *metav1.ObjectMeta) int64 {
+	revision, err := strconv.ParseInt(meta.Annotations[deploymentRevisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
@@ -2,13 +2,19 @@ package canary
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sTesting "k8s.io/client-go/testing"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/weaveworks/flagger/pkg/client/clientset/versioned/fake"
 )
 
 func TestDeploymentController_SyncStatus(t *testing.T) {
@@ -57,3 +63,149 @@ func TestDeploymentController_SetState(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, res.Status.Phase)
 }
+
+func TestDeploymentController_SetStatusAnalysis(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.initializeCanary(t)
+
+	for i := 0; i < AnalysisHistoryLimit+2; i++ {
+		cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		record := flaggerv1.CanaryAnalysisRecord{
+			Iteration: i,
+			Metrics:   &flaggerv1.CanaryAnalysisStatus{Score: 100, Passed: true},
+		}
+		require.NoError(t, mocks.controller.SetStatusAnalysis(cd, record))
+	}
+
+	res, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, res.Status.AnalysisHistory, AnalysisHistoryLimit)
+	assert.Equal(t, 2, res.Status.AnalysisHistory[0].Iteration)
+	assert.Equal(t, AnalysisHistoryLimit+1, res.Status.AnalysisHistory[AnalysisHistoryLimit-1].Iteration)
+	require.NotNil(t, res.Status.Analysis)
+	assert.True(t, res.Status.Analysis.Passed)
+}
+
+func TestDeploymentController_CompleteRun(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.initializeCanary(t)
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.controller.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}))
+
+	cd, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, cd.Status.StartTime)
+	require.NoError(t, mocks.controller.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded))
+
+	res, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, res.Status.Runs, 1)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, res.Status.Runs[0].Phase)
+	assert.Equal(t, res.Status.LastPromotedSpec, res.Status.Runs[0].Revision)
+
+	// the phase is already completed
+	require.NoError(t, mocks.controller.SetStatusPhase(res, flaggerv1.CanaryPhaseSucceeded))
+	res, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, res.Status.Runs, 1)
+}
+
+func TestDeploymentController_StatusConditions(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.initializeCanary(t)
+
+	conditionStatus := func(cd *flaggerv1.Canary) map[flaggerv1.CanaryConditionType]corev1.ConditionStatus {
+		result := make(map[flaggerv1.CanaryConditionType]corev1.ConditionStatus)
+		for _, c := range cd.Status.Conditions {
+			result[c.Type] = c.Status
+		}
+		return result
+	}
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Generation = 2
+	require.NoError(t, mocks.controller.SetStatusPhase(cd, flaggerv1.CanaryPhaseWaiting))
+
+	res, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Status.ObservedGeneration)
+	assert.Equal(t, map[flaggerv1.CanaryConditionType]corev1.ConditionStatus{
+		flaggerv1.PromotedType:         corev1.ConditionUnknown,
+		flaggerv1.ReadyType:            corev1.ConditionFalse,
+		flaggerv1.ProgressingType:      corev1.ConditionTrue,
+		flaggerv1.AwaitingApprovalType: corev1.ConditionTrue,
+		flaggerv1.RolledBackType:       corev1.ConditionFalse,
+		flaggerv1.StalledType:          corev1.ConditionFalse,
+	}, conditionStatus(res))
+	assert.Equal(t, flaggerv1.ConditionReasonWaitingForApproval, getStatusCondition(res.Status, flaggerv1.AwaitingApprovalType).Reason)
+
+	require.NoError(t, mocks.controller.SyncStatus(res, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed}))
+
+	res, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[flaggerv1.CanaryConditionType]corev1.ConditionStatus{
+		flaggerv1.PromotedType:         corev1.ConditionFalse,
+		flaggerv1.ReadyType:            corev1.ConditionFalse,
+		flaggerv1.ProgressingType:      corev1.ConditionFalse,
+		flaggerv1.AwaitingApprovalType: corev1.ConditionFalse,
+		flaggerv1.RolledBackType:       corev1.ConditionTrue,
+		flaggerv1.StalledType:          corev1.ConditionFalse,
+	}, conditionStatus(res))
+	assert.Equal(t, flaggerv1.ConditionReasonAnalysisFailed, getStatusCondition(res.Status, flaggerv1.RolledBackType).Reason)
+
+	// the progress deadline stalls the canary until the next run
+	require.NoError(t, mocks.controller.SetStatusStalled(res, "Progress deadline exceeded"))
+
+	res, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, conditionStatus(res)[flaggerv1.StalledType])
+	assert.Equal(t, flaggerv1.ConditionReasonProgressDeadlineExceeded, getStatusCondition(res.Status, flaggerv1.StalledType).Reason)
+
+	require.NoError(t, mocks.controller.SyncStatus(res, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed}))
+	res, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, conditionStatus(res)[flaggerv1.StalledType])
+
+	require.NoError(t, mocks.controller.SetStatusPhase(res, flaggerv1.CanaryPhaseSucceeded))
+
+	res, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, conditionStatus(res)[flaggerv1.ReadyType])
+	assert.Equal(t, corev1.ConditionFalse, conditionStatus(res)[flaggerv1.RolledBackType])
+	assert.Equal(t, corev1.ConditionFalse, conditionStatus(res)[flaggerv1.StalledType])
+}
+
+func TestDeploymentController_ObservedGenerationRetry(t *testing.T) {
+	mocks := newDeploymentFixture()
+	mocks.initializeCanary(t)
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	cd.Generation = 2
+
+	// the spec is changed while the status is written
+	cs := mocks.flaggerClient.(*fakeFlagger.Clientset)
+	conflict := true
+	cs.PrependReactor("update", "canaries", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" || !conflict {
+			return false, nil, nil
+		}
+		conflict = false
+		latest := cd.DeepCopy()
+		latest.Generation = 3
+		require.NoError(t, cs.Tracker().Update(action.GetResource(), latest, latest.Namespace))
+		return true, nil, errors.NewConflict(action.GetResource().GroupResource(), cd.Name, fmt.Errorf("modified"))
+	})
+
+	require.NoError(t, mocks.controller.SetStatusWeight(cd, 10))
+
+	res, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 10, res.Status.CanaryWeight)
+	assert.Equal(t, int64(3), res.Generation)
+	assert.Equal(t, int64(2), res.Status.ObservedGeneration)
+}
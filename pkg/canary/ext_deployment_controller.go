@@ -72,7 +72,9 @@ func (rdc *ExtDeploymentController) Promote(canary *flaggerv1.Canary) error {
 		if new, err := rdc.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Get(ctx, canary.Name, metav1.GetOptions{}); err != nil {
 			return fmt.Errorf("canary %s.%s get query failed: %w", canary.Name, canary.Namespace, err)
 		} else {
-			new.DeepCopyInto(canary)
+			// keep the analysis merged with the templates
+			canary.ObjectMeta = new.ObjectMeta
+			canary.Status = new.Status
 		}
 	}
 	return nil
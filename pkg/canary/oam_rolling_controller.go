@@ -91,8 +91,12 @@ func NewRollingController(factory *Factory, canary *flaggerv1.Canary) (*OAMRollo
 }
 
 func (orc *OAMRolloutController) Initialize(canary *flaggerv1.Canary) (err error) {
+	maxReplicas, err := getMaxReplicas(canary)
+	if err != nil {
+		return err
+	}
 	if orc.SourceWorkload == nil {
-		return orc.Scale(orc.TargetWorkload.GetName(), int32(canary.Spec.Analysis.MaxReplicas))
+		return orc.Scale(orc.TargetWorkload.GetName(), maxReplicas)
 	}
 	if canary.Status.Phase == "" || canary.Status.Phase == flaggerv1.CanaryPhaseInitializing {
 		if !canary.SkipAnalysis() {
@@ -108,13 +112,13 @@ func (orc *OAMRolloutController) Initialize(canary *flaggerv1.Canary) (err error
 		orc.logger.Infof("scaling down canary resource %s.%s to zero succeed", canary.Spec.TargetRef.Name,
 			canary.Namespace)
 		// scale the source resource to canary setting
-		if err := orc.Scale(orc.SourceWorkload.GetName(), int32(canary.Spec.Analysis.MaxReplicas)); err != nil {
+		if err := orc.Scale(orc.SourceWorkload.GetName(), maxReplicas); err != nil {
 			return fmt.Errorf("scaling down canary resource %s.%s failed: %w", orc.SourceWorkload.GetName(),
 				canary.Namespace, err)
 		}
 		orc.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 			Infof("scaling primary resource %s.%s to %d succeed", orc.SourceWorkload.GetName(),
-				canary.Namespace, canary.Spec.Analysis.MaxReplicas)
+				canary.Namespace, maxReplicas)
 	}
 	return nil
 }
@@ -178,7 +182,11 @@ func (orc *OAMRolloutController) ScaleToZero(canary *flaggerv1.Canary) error {
 		return orc.Scale(orc.SourceWorkload.GetName(), 0)
 	}
 	// in other cases, it's a rollback, we
-	return orc.Scale(orc.SourceWorkload.GetName(), int32(canary.Spec.Analysis.MaxReplicas))
+	maxReplicas, err := getMaxReplicas(canary)
+	if err != nil {
+		return err
+	}
+	return orc.Scale(orc.SourceWorkload.GetName(), maxReplicas)
 }
 
 func (orc *OAMRolloutController) ScaleFromZero(_ *flaggerv1.Canary) error {
@@ -269,6 +277,22 @@ func (orc *OAMRolloutController) SetStatusIterations(cd *flaggerv1.Canary, val i
 	return setStatusIterations(orc.flaggerClient, cd, val)
 }
 
+func (orc *OAMRolloutController) SetStatusStep(cd *flaggerv1.Canary, index int) error {
+	return setStatusStep(orc.flaggerClient, cd, index)
+}
+
+func (orc *OAMRolloutController) SetStatusAnalysis(cd *flaggerv1.Canary, record flaggerv1.CanaryAnalysisRecord) error {
+	return setStatusAnalysis(orc.flaggerClient, cd, record)
+}
+
+func (orc *OAMRolloutController) SetStatusSuspended(cd *flaggerv1.Canary, suspended bool) error {
+	return setStatusSuspended(orc.flaggerClient, cd, suspended)
+}
+
+func (orc *OAMRolloutController) SetStatusStalled(cd *flaggerv1.Canary, message string) error {
+	return setStatusStalled(orc.flaggerClient, cd, message)
+}
+
 func (orc *OAMRolloutController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(orc.flaggerClient, cd, phase)
 }
@@ -292,12 +316,44 @@ func (orc *OAMRolloutController) HaveDependenciesChanged(canary *flaggerv1.Canar
 	return changed, err
 }
 
+// RevertTarget scales the source workload back to the max replicas and the target workload to zero,
+// the same way the replicas are rolled back when the analysis fails
+func (orc *OAMRolloutController) RevertTarget(cd *flaggerv1.Canary) error {
+	if orc.SourceWorkload == nil {
+		return fmt.Errorf("reverting %s %s.%s failed: source workload not found", cd.Spec.TargetRef.Kind,
+			cd.Spec.TargetRef.Name, cd.Namespace)
+	}
+	maxReplicas, err := getMaxReplicas(cd)
+	if err != nil {
+		return err
+	}
+	if err := orc.Scale(orc.SourceWorkload.GetName(), maxReplicas); err != nil {
+		return fmt.Errorf("scaling up source resource %s.%s failed: %w", orc.SourceWorkload.GetName(), cd.Namespace, err)
+	}
+	if err := orc.Scale(cd.Spec.TargetRef.Name, 0); err != nil {
+		return fmt.Errorf("scaling down target resource %s.%s failed: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+	}
+	return nil
+}
+
 // Finalize will revert rolling update back, we just scale up here.
 func (orc *OAMRolloutController) Finalize(canary *flaggerv1.Canary) error {
 	if orc.SourceWorkload == nil {
 		return nil
 	}
-	return orc.Scale(orc.SourceWorkload.GetName(), int32(canary.Spec.Analysis.MaxReplicas))
+	maxReplicas, err := getMaxReplicas(canary)
+	if err != nil {
+		return err
+	}
+	return orc.Scale(orc.SourceWorkload.GetName(), maxReplicas)
+}
+
+// getMaxReplicas returns the number of replicas the source and target workloads are scaled to
+func getMaxReplicas(canary *flaggerv1.Canary) (int32, error) {
+	if canary.GetAnalysis() == nil {
+		return 0, fmt.Errorf("canary %s.%s analysis is not set, maxReplicas is required", canary.Name, canary.Namespace)
+	}
+	return int32(canary.GetAnalysis().MaxReplicas), nil
 }
 
 // Scale sets the canary workload replicas
@@ -37,6 +37,26 @@ func (c *ServiceController) SetStatusIterations(cd *flaggerv1.Canary, val int) e
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusStep updates the canary status step index and weight
+func (c *ServiceController) SetStatusStep(cd *flaggerv1.Canary, index int) error {
+	return setStatusStep(c.flaggerClient, cd, index)
+}
+
+// SetStatusAnalysis appends the record to the canary status analysis history
+func (c *ServiceController) SetStatusAnalysis(cd *flaggerv1.Canary, record flaggerv1.CanaryAnalysisRecord) error {
+	return setStatusAnalysis(c.flaggerClient, cd, record)
+}
+
+// SetStatusSuspended updates the canary status suspended condition
+func (c *ServiceController) SetStatusSuspended(cd *flaggerv1.Canary, suspended bool) error {
+	return setStatusSuspended(c.flaggerClient, cd, suspended)
+}
+
+// SetStatusStalled sets the canary status stalled condition
+func (c *ServiceController) SetStatusStalled(cd *flaggerv1.Canary, message string) error {
+	return setStatusStalled(c.flaggerClient, cd, message)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *ServiceController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
@@ -223,6 +243,11 @@ func (c *ServiceController) IsCanaryReady(_ *flaggerv1.Canary) (bool, error) {
 	return true, nil
 }
 
+// RevertTarget is not supported for the Service kind
+func (c *ServiceController) RevertTarget(cd *flaggerv1.Canary) error {
+	return fmt.Errorf("reverting %s %s.%s is not supported", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
+}
+
 func (c *ServiceController) Finalize(_ *flaggerv1.Canary) error {
 	return nil
 }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 
@@ -13,9 +15,18 @@ import (
 	clientset "github.com/weaveworks/flagger/pkg/client/clientset/versioned"
 )
 
+const (
+	// AnalysisHistoryLimit is the number of advancement records kept in the canary status
+	AnalysisHistoryLimit = 10
+	// RunHistoryLimit is the number of run summaries kept in the canary status
+	RunHistoryLimit = 5
+)
+
 func syncCanaryStatus(flaggerClient clientset.Interface, cd *flaggerv1.Canary, status flaggerv1.CanaryStatus, canaryResource interface{}, setAll func(cdCopy *flaggerv1.Canary)) error {
 	hash := computeHash(canaryResource)
 
+	// the retries keep the generation the controller acted on
+	generation := cd.Generation
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
@@ -31,14 +42,22 @@ func syncCanaryStatus(flaggerClient clientset.Interface, cd *flaggerv1.Canary, s
 		cdCopy.Status.CanaryWeight = status.CanaryWeight
 		cdCopy.Status.FailedChecks = status.FailedChecks
 		cdCopy.Status.Iterations = status.Iterations
+		cdCopy.Status.CurrentStepIndex = status.CurrentStepIndex
+		cdCopy.Status.CurrentStepStartTime = status.CurrentStepStartTime
 		cdCopy.Status.LastAppliedSpec = hash
 		cdCopy.Status.LastTransitionTime = metav1.Now()
 		setAll(cdCopy)
 
+		if status.Phase == flaggerv1.CanaryPhaseProgressing {
+			cdCopy.Status.StartTime = &cdCopy.Status.LastTransitionTime
+		}
+		completeRun(cd, cdCopy)
+
 		if ok, conditions := MakeStatusConditions(cd, status.Phase); ok {
 			cdCopy.Status.Conditions = conditions
 		}
 
+		cdCopy.Status.ObservedGeneration = generation
 		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
 		firstTry = false
 		return
@@ -51,6 +70,7 @@ func syncCanaryStatus(flaggerClient clientset.Interface, cd *flaggerv1.Canary, s
 }
 
 func setStatusFailedChecks(flaggerClient clientset.Interface, cd *flaggerv1.Canary, val int) error {
+	generation := cd.Generation
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
@@ -64,6 +84,7 @@ func setStatusFailedChecks(flaggerClient clientset.Interface, cd *flaggerv1.Cana
 		cdCopy.Status.FailedChecks = val
 		cdCopy.Status.LastTransitionTime = metav1.Now()
 
+		cdCopy.Status.ObservedGeneration = generation
 		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
 		firstTry = false
 		return
@@ -75,6 +96,7 @@ func setStatusFailedChecks(flaggerClient clientset.Interface, cd *flaggerv1.Cana
 }
 
 func setStatusWeight(flaggerClient clientset.Interface, cd *flaggerv1.Canary, val int) error {
+	generation := cd.Generation
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
@@ -88,6 +110,7 @@ func setStatusWeight(flaggerClient clientset.Interface, cd *flaggerv1.Canary, va
 		cdCopy.Status.CanaryWeight = val
 		cdCopy.Status.LastTransitionTime = metav1.Now()
 
+		cdCopy.Status.ObservedGeneration = generation
 		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
 		firstTry = false
 		return
@@ -99,6 +122,7 @@ func setStatusWeight(flaggerClient clientset.Interface, cd *flaggerv1.Canary, va
 }
 
 func setStatusIterations(flaggerClient clientset.Interface, cd *flaggerv1.Canary, val int) error {
+	generation := cd.Generation
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
@@ -113,6 +137,7 @@ func setStatusIterations(flaggerClient clientset.Interface, cd *flaggerv1.Canary
 		cdCopy.Status.Iterations = val
 		cdCopy.Status.LastTransitionTime = metav1.Now()
 
+		cdCopy.Status.ObservedGeneration = generation
 		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
 		firstTry = false
 		return
@@ -124,7 +149,162 @@ func setStatusIterations(flaggerClient clientset.Interface, cd *flaggerv1.Canary
 	return nil
 }
 
+// setStatusStep records the analysis step the canary has reached along with its traffic weight
+func setStatusStep(flaggerClient clientset.Interface, cd *flaggerv1.Canary, index int) error {
+	if cd.GetAnalysis() == nil || index < 0 || index >= len(cd.GetAnalysis().Steps) {
+		return fmt.Errorf("canary %s.%s step %d out of range", cd.GetName(), cd.GetNamespace(), index)
+	}
+	weight := cd.GetAnalysis().Steps[index].Weight
+
+	generation := cd.Generation
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		now := metav1.Now()
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.CurrentStepIndex = &index
+		cdCopy.Status.CurrentStepStartTime = &now
+		cdCopy.Status.CanaryWeight = weight
+		cdCopy.Status.LastTransitionTime = now
+
+		cdCopy.Status.ObservedGeneration = generation
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+// setStatusAnalysis appends the record to the analysis history
+// and records the outcome of the metric checks if the record has any,
+// the observed generation is left to the status updates of the advancement
+func setStatusAnalysis(flaggerClient clientset.Interface, cd *flaggerv1.Canary, record flaggerv1.CanaryAnalysisRecord) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		if record.Metrics != nil {
+			cdCopy.Status.Analysis = record.Metrics.DeepCopy()
+		}
+		cdCopy.Status.AnalysisHistory = append(cdCopy.Status.AnalysisHistory, *record.DeepCopy())
+		if n := len(cdCopy.Status.AnalysisHistory); n > AnalysisHistoryLimit {
+			cdCopy.Status.AnalysisHistory = cdCopy.Status.AnalysisHistory[n-AnalysisHistoryLimit:]
+		}
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+// completeRun appends the summary of the run to the status when the canary is promoted or rolled back
+func completeRun(cd *flaggerv1.Canary, cdCopy *flaggerv1.Canary) {
+	phase := cdCopy.Status.Phase
+	if phase != flaggerv1.CanaryPhaseSucceeded && phase != flaggerv1.CanaryPhaseFailed {
+		return
+	}
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaiting,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+	default:
+		return
+	}
+
+	run := flaggerv1.CanaryRunStatus{
+		Phase:          phase,
+		Revision:       cdCopy.Status.LastAppliedSpec,
+		StartTime:      cd.Status.StartTime,
+		CompletionTime: metav1.Now(),
+		FailedChecks:   cd.Status.FailedChecks,
+	}
+	if run.StartTime != nil {
+		run.Duration = &metav1.Duration{Duration: run.CompletionTime.Sub(run.StartTime.Time).Round(time.Second)}
+	}
+	cdCopy.Status.Runs = append(cdCopy.Status.Runs, run)
+	if n := len(cdCopy.Status.Runs); n > RunHistoryLimit {
+		cdCopy.Status.Runs = cdCopy.Status.Runs[n-RunHistoryLimit:]
+	}
+}
+
+func setStatusSuspended(flaggerClient clientset.Interface, cd *flaggerv1.Canary, suspended bool) error {
+	generation := cd.Generation
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		if ok, conditions := MakeSuspendedCondition(cd, suspended); ok {
+			cdCopy := cd.DeepCopy()
+			cdCopy.Status.Conditions = conditions
+			cdCopy.Status.ObservedGeneration = generation
+			err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		}
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+func setStatusStalled(flaggerClient clientset.Interface, cd *flaggerv1.Canary, message string) error {
+	generation := cd.Generation
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		if ok, conditions := MakeStalledCondition(cd, message); ok {
+			cdCopy := cd.DeepCopy()
+			cdCopy.Status.Conditions = conditions
+			cdCopy.Status.ObservedGeneration = generation
+			err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		}
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
 func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	generation := cd.Generation
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
@@ -142,17 +322,21 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 		if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting {
 			cdCopy.Status.CanaryWeight = 0
 			cdCopy.Status.Iterations = 0
+			cdCopy.Status.CurrentStepIndex = nil
+			cdCopy.Status.CurrentStepStartTime = nil
 		}
 
 		// on promotion set primary spec hash
 		if phase == flaggerv1.CanaryPhaseInitialized || phase == flaggerv1.CanaryPhaseSucceeded {
 			cdCopy.Status.LastPromotedSpec = cd.Status.LastAppliedSpec
 		}
+		completeRun(cd, cdCopy)
 
 		if ok, conditions := MakeStatusConditions(cdCopy, phase); ok {
 			cdCopy.Status.Conditions = conditions
 		}
 
+		cdCopy.Status.ObservedGeneration = generation
 		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
 		firstTry = false
 		return
@@ -174,11 +358,34 @@ func getStatusCondition(status flaggerv1.CanaryStatus, conditionType flaggerv1.C
 	return nil
 }
 
-// MakeStatusCondition updates the canary status conditions based on canary phase
+// MakeStatusConditions updates the canary status conditions based on canary phase
 func MakeStatusConditions(cd *flaggerv1.Canary,
 	phase flaggerv1.CanaryPhase) (bool, []flaggerv1.CanaryCondition) {
-	currentCondition := getStatusCondition(cd.Status, flaggerv1.PromotedType)
+	changed := false
+	conditions := cd.Status.Conditions
+	for _, condition := range makePhaseConditions(cd, phase) {
+		currentCondition := getStatusCondition(flaggerv1.CanaryStatus{Conditions: conditions}, condition.Type)
+		if currentCondition != nil &&
+			currentCondition.Status == condition.Status &&
+			currentCondition.Reason == condition.Reason {
+			continue
+		}
+		if currentCondition != nil && currentCondition.Status == condition.Status {
+			condition.LastTransitionTime = currentCondition.LastTransitionTime
+		}
+		conditions = mergeStatusCondition(conditions, condition)
+		changed = true
+	}
+
+	if !changed {
+		return false, nil
+	}
+	return true, conditions
+}
 
+// makePhaseConditions returns the Promoted, Ready, Progressing, AwaitingApproval and RolledBack
+// conditions of the canary phase, the Stalled condition is cleared by the phases other than failed
+func makePhaseConditions(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) []flaggerv1.CanaryCondition {
 	message := fmt.Sprintf("New %s detected, starting initialization.", cd.Spec.TargetRef.Kind)
 	status := corev1.ConditionUnknown
 	switch phase {
@@ -208,26 +415,158 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 		message = fmt.Sprintf("Canary analysis failed, %s scaled to zero.", cd.Spec.TargetRef.Kind)
 	}
 
-	newCondition := &flaggerv1.CanaryCondition{
-		Type:               flaggerv1.PromotedType,
+	reason := string(phase)
+	if phase == flaggerv1.CanaryPhaseWaiting {
+		reason = waitingReason(cd)
+		if reason == flaggerv1.ConditionReasonReleaseWindowClosed {
+			message = "Waiting for a release window."
+		}
+	}
+
+	ready := corev1.ConditionFalse
+	if phase == flaggerv1.CanaryPhaseInitialized || phase == flaggerv1.CanaryPhaseSucceeded {
+		ready = corev1.ConditionTrue
+	}
+
+	progressing := corev1.ConditionFalse
+	switch phase {
+	case flaggerv1.CanaryPhaseInitializing, flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaiting,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		progressing = corev1.ConditionTrue
+	}
+
+	awaitingApproval := corev1.ConditionFalse
+	if reason == flaggerv1.ConditionReasonWaitingForApproval {
+		awaitingApproval = corev1.ConditionTrue
+	}
+
+	failedReason := reason
+	failedMessage := message
+	failed := corev1.ConditionFalse
+	if phase == flaggerv1.CanaryPhaseFailed {
+		failed = corev1.ConditionTrue
+		failedReason = flaggerv1.ConditionReasonAnalysisFailed
+		failedMessage = fmt.Sprintf("Canary analysis failed, all traffic routed to primary, "+
+			"waiting for a new %s revision or a retry action.", cd.Spec.TargetRef.Kind)
+	}
+
+	conditions := []flaggerv1.CanaryCondition{
+		makeCondition(flaggerv1.PromotedType, status, reason, message),
+		makeCondition(flaggerv1.ReadyType, ready, reason, message),
+		makeCondition(flaggerv1.ProgressingType, progressing, reason, message),
+		makeCondition(flaggerv1.AwaitingApprovalType, awaitingApproval, reason, message),
+		makeCondition(flaggerv1.RolledBackType, failed, failedReason, failedMessage),
+	}
+	// the rollback keeps the Stalled condition set when the progress deadline was exceeded
+	if phase != flaggerv1.CanaryPhaseFailed {
+		conditions = append(conditions, makeCondition(flaggerv1.StalledType, corev1.ConditionFalse, reason, message))
+	}
+	return conditions
+}
+
+// waitingReason returns the reason of the waiting phase, a closed release window or a confirm-rollout webhook
+func waitingReason(cd *flaggerv1.Canary) string {
+	if analysis := cd.GetAnalysis(); analysis != nil && analysis.Schedule != nil {
+		if open, err := analysis.Schedule.IsOpen(time.Now()); err == nil && !open {
+			return flaggerv1.ConditionReasonReleaseWindowClosed
+		}
+	}
+	return flaggerv1.ConditionReasonWaitingForApproval
+}
+
+func makeCondition(conditionType flaggerv1.CanaryConditionType, status corev1.ConditionStatus,
+	reason string, message string) flaggerv1.CanaryCondition {
+	return flaggerv1.CanaryCondition{
+		Type:               conditionType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Message:            message,
+		Reason:             reason,
+	}
+}
+
+// MakeSuspendedCondition updates the canary status conditions based on spec.suspend
+func MakeSuspendedCondition(cd *flaggerv1.Canary, suspended bool) (bool, []flaggerv1.CanaryCondition) {
+	currentCondition := getStatusCondition(cd.Status, flaggerv1.SuspendedType)
+
+	status := corev1.ConditionFalse
+	reason := "Resumed"
+	message := "Canary analysis resumed."
+	if suspended {
+		status = corev1.ConditionTrue
+		reason = "Suspended"
+		message = fmt.Sprintf("Canary analysis suspended at weight %d iteration %d.",
+			cd.Status.CanaryWeight, cd.Status.Iterations)
+	}
+
+	// a canary that was never suspended doesn't need the condition
+	if currentCondition == nil && !suspended {
+		return false, nil
+	}
+	if currentCondition != nil && currentCondition.Status == status {
+		return false, nil
+	}
+
+	newCondition := flaggerv1.CanaryCondition{
+		Type:               flaggerv1.SuspendedType,
 		Status:             status,
 		LastUpdateTime:     metav1.Now(),
 		LastTransitionTime: metav1.Now(),
 		Message:            message,
-		Reason:             string(phase),
+		Reason:             reason,
 	}
+	return true, mergeStatusCondition(cd.Status.Conditions, newCondition)
+}
 
-	if currentCondition != nil &&
-		currentCondition.Status == newCondition.Status &&
-		currentCondition.Reason == newCondition.Reason {
+// MakeStalledCondition sets the Stalled condition of a canary whose workload
+// didn't become ready within the progress deadline
+func MakeStalledCondition(cd *flaggerv1.Canary, message string) (bool, []flaggerv1.CanaryCondition) {
+	currentCondition := getStatusCondition(cd.Status, flaggerv1.StalledType)
+	if currentCondition != nil && currentCondition.Status == corev1.ConditionTrue &&
+		currentCondition.Message == message {
 		return false, nil
 	}
 
-	if currentCondition != nil && currentCondition.Status == newCondition.Status {
+	newCondition := flaggerv1.CanaryCondition{
+		Type:               flaggerv1.StalledType,
+		Status:             corev1.ConditionTrue,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Message:            message,
+		Reason:             flaggerv1.ConditionReasonProgressDeadlineExceeded,
+	}
+	if currentCondition != nil && currentCondition.Status == corev1.ConditionTrue {
 		newCondition.LastTransitionTime = currentCondition.LastTransitionTime
 	}
+	return true, mergeStatusCondition(cd.Status.Conditions, newCondition)
+}
+
+// mergeStatusCondition replaces the condition of the same type in place and keeps the others
+func mergeStatusCondition(conditions []flaggerv1.CanaryCondition, condition flaggerv1.CanaryCondition) []flaggerv1.CanaryCondition {
+	result := make([]flaggerv1.CanaryCondition, 0, len(conditions)+1)
+	replaced := false
+	for _, c := range conditions {
+		if c.Type == condition.Type {
+			result = append(result, condition)
+			replaced = true
+			continue
+		}
+		result = append(result, c)
+	}
+	if !replaced {
+		result = append(result, condition)
+	}
+	return result
+}
 
-	return true, []flaggerv1.CanaryCondition{*newCondition}
+// IsResumedWithin returns true if the canary analysis was resumed during the given duration
+func IsResumedWithin(cd *flaggerv1.Canary, duration time.Duration) bool {
+	condition := getStatusCondition(cd.Status, flaggerv1.SuspendedType)
+	if condition == nil || condition.Status != corev1.ConditionFalse {
+		return false
+	}
+	return condition.LastTransitionTime.Add(duration).After(time.Now())
 }
 
 // updateStatusWithUpgrade tries to update the status sub-resource
@@ -245,7 +584,8 @@ func updateStatusWithUpgrade(flaggerClient clientset.Interface, cd *flaggerv1.Ca
 		_, err = flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).UpdateStatus(context.TODO(), cd, metav1.UpdateOptions{})
 	}
 
-	if err != nil {
+	// the conflicts are returned as is to be retried by the callers
+	if err != nil && !errors.IsConflict(err) {
 		return fmt.Errorf("updating canary %s.%s status failed: %w", cd.Name, cd.Namespace, err)
 	}
 	return err
@@ -32,10 +32,22 @@ func (c *FakeFlaggerV1beta1) AlertProviders(namespace string) v1beta1.AlertProvi
 	return &FakeAlertProviders{c, namespace}
 }
 
+func (c *FakeFlaggerV1beta1) AnalysisTemplates(namespace string) v1beta1.AnalysisTemplateInterface {
+	return &FakeAnalysisTemplates{c, namespace}
+}
+
 func (c *FakeFlaggerV1beta1) Canaries(namespace string) v1beta1.CanaryInterface {
 	return &FakeCanaries{c, namespace}
 }
 
+func (c *FakeFlaggerV1beta1) CanaryGroups(namespace string) v1beta1.CanaryGroupInterface {
+	return &FakeCanaryGroups{c, namespace}
+}
+
+func (c *FakeFlaggerV1beta1) ClusterAnalysisTemplates() v1beta1.ClusterAnalysisTemplateInterface {
+	return &FakeClusterAnalysisTemplates{c}
+}
+
 func (c *FakeFlaggerV1beta1) MetricTemplates(namespace string) v1beta1.MetricTemplateInterface {
 	return &FakeMetricTemplates{c, namespace}
 }
@@ -27,7 +27,10 @@ import (
 type FlaggerV1beta1Interface interface {
 	RESTClient() rest.Interface
 	AlertProvidersGetter
+	AnalysisTemplatesGetter
 	CanariesGetter
+	CanaryGroupsGetter
+	ClusterAnalysisTemplatesGetter
 	MetricTemplatesGetter
 }
 
@@ -40,10 +43,22 @@ func (c *FlaggerV1beta1Client) AlertProviders(namespace string) AlertProviderInt
 	return newAlertProviders(c, namespace)
 }
 
+func (c *FlaggerV1beta1Client) AnalysisTemplates(namespace string) AnalysisTemplateInterface {
+	return newAnalysisTemplates(c, namespace)
+}
+
 func (c *FlaggerV1beta1Client) Canaries(namespace string) CanaryInterface {
 	return newCanaries(c, namespace)
 }
 
+func (c *FlaggerV1beta1Client) CanaryGroups(namespace string) CanaryGroupInterface {
+	return newCanaryGroups(c, namespace)
+}
+
+func (c *FlaggerV1beta1Client) ClusterAnalysisTemplates() ClusterAnalysisTemplateInterface {
+	return newClusterAnalysisTemplates(c)
+}
+
 func (c *FlaggerV1beta1Client) MetricTemplates(namespace string) MetricTemplateInterface {
 	return newMetricTemplates(c, namespace)
 }
@@ -20,6 +20,12 @@ package v1beta1
 
 type AlertProviderExpansion interface{}
 
+type AnalysisTemplateExpansion interface{}
+
 type CanaryExpansion interface{}
 
+type CanaryGroupExpansion interface{}
+
+type ClusterAnalysisTemplateExpansion interface{}
+
 type MetricTemplateExpansion interface{}
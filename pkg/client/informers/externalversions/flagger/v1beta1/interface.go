@@ -26,8 +26,14 @@ import (
 type Interface interface {
 	// AlertProviders returns a AlertProviderInformer.
 	AlertProviders() AlertProviderInformer
+	// AnalysisTemplates returns a AnalysisTemplateInformer.
+	AnalysisTemplates() AnalysisTemplateInformer
 	// Canaries returns a CanaryInformer.
 	Canaries() CanaryInformer
+	// CanaryGroups returns a CanaryGroupInformer.
+	CanaryGroups() CanaryGroupInformer
+	// ClusterAnalysisTemplates returns a ClusterAnalysisTemplateInformer.
+	ClusterAnalysisTemplates() ClusterAnalysisTemplateInformer
 	// MetricTemplates returns a MetricTemplateInformer.
 	MetricTemplates() MetricTemplateInformer
 }
@@ -48,11 +54,26 @@ func (v *version) AlertProviders() AlertProviderInformer {
 	return &alertProviderInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// AnalysisTemplates returns a AnalysisTemplateInformer.
+func (v *version) AnalysisTemplates() AnalysisTemplateInformer {
+	return &analysisTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Canaries returns a CanaryInformer.
 func (v *version) Canaries() CanaryInformer {
 	return &canaryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CanaryGroups returns a CanaryGroupInformer.
+func (v *version) CanaryGroups() CanaryGroupInformer {
+	return &canaryGroupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// ClusterAnalysisTemplates returns a ClusterAnalysisTemplateInformer.
+func (v *version) ClusterAnalysisTemplates() ClusterAnalysisTemplateInformer {
+	return &clusterAnalysisTemplateInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // MetricTemplates returns a MetricTemplateInformer.
 func (v *version) MetricTemplates() MetricTemplateInformer {
 	return &metricTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
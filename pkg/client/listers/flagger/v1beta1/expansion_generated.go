@@ -26,6 +26,14 @@ type AlertProviderListerExpansion interface{}
 // AlertProviderNamespaceLister.
 type AlertProviderNamespaceListerExpansion interface{}
 
+// AnalysisTemplateListerExpansion allows custom methods to be added to
+// AnalysisTemplateLister.
+type AnalysisTemplateListerExpansion interface{}
+
+// AnalysisTemplateNamespaceListerExpansion allows custom methods to be added to
+// AnalysisTemplateNamespaceLister.
+type AnalysisTemplateNamespaceListerExpansion interface{}
+
 // CanaryListerExpansion allows custom methods to be added to
 // CanaryLister.
 type CanaryListerExpansion interface{}
@@ -34,6 +42,18 @@ type CanaryListerExpansion interface{}
 // CanaryNamespaceLister.
 type CanaryNamespaceListerExpansion interface{}
 
+// CanaryGroupListerExpansion allows custom methods to be added to
+// CanaryGroupLister.
+type CanaryGroupListerExpansion interface{}
+
+// CanaryGroupNamespaceListerExpansion allows custom methods to be added to
+// CanaryGroupNamespaceLister.
+type CanaryGroupNamespaceListerExpansion interface{}
+
+// ClusterAnalysisTemplateListerExpansion allows custom methods to be added to
+// ClusterAnalysisTemplateLister.
+type ClusterAnalysisTemplateListerExpansion interface{}
+
 // MetricTemplateListerExpansion allows custom methods to be added to
 // MetricTemplateLister.
 type MetricTemplateListerExpansion interface{}
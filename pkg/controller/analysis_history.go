@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// analysisRecord returns the record of the checks run during the current advancement of the canary
+func (c *Controller) analysisRecord(cd *flaggerv1.Canary) *flaggerv1.CanaryAnalysisRecord {
+	record := &flaggerv1.CanaryAnalysisRecord{
+		Time:         metav1.Now(),
+		CanaryWeight: cd.Status.CanaryWeight,
+		Iteration:    cd.Status.Iterations,
+	}
+	if c.analysisRecords == nil {
+		return record
+	}
+	value, _ := c.analysisRecords.LoadOrStore(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), record)
+	return value.(*flaggerv1.CanaryAnalysisRecord)
+}
+
+// recordWebhook adds the outcome of the webhook call to the analysis record
+func (c *Controller) recordWebhook(cd *flaggerv1.Canary, webhook flaggerv1.CanaryWebhook, err error) {
+	status := flaggerv1.CanaryWebhookStatus{
+		Name:   webhook.Name,
+		Type:   webhook.Type,
+		Passed: err == nil,
+	}
+	if status.Type == "" {
+		status.Type = flaggerv1.RolloutHook
+	}
+	if err != nil {
+		status.Message = err.Error()
+	}
+	record := c.analysisRecord(cd)
+	record.Webhooks = append(record.Webhooks, status)
+}
+
+// saveAnalysisRecord appends the record of the current advancement to the analysis history of the canary
+func (c *Controller) saveAnalysisRecord(cd *flaggerv1.Canary, canaryController canary.Controller) {
+	if c.analysisRecords == nil {
+		return
+	}
+	key := fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+	value, ok := c.analysisRecords.Load(key)
+	if !ok {
+		return
+	}
+	c.analysisRecords.Delete(key)
+
+	// the status may have changed during the advancement
+	current, err := c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).Get(context.TODO(), cd.Name, metav1.GetOptions{})
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
+	}
+	if err := canaryController.SetStatusAnalysis(current, *value.(*flaggerv1.CanaryAnalysisRecord)); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+	}
+}
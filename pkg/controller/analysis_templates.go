@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// resolveAnalysisTemplates merges the analysis templates referenced by the canary into the analysis
+// returned by GetAnalysis
+func (c *Controller) resolveAnalysisTemplates(cd *flaggerv1.Canary) error {
+	analysis := cd.GetAnalysis()
+	if analysis == nil || len(analysis.TemplateRefs) == 0 {
+		return nil
+	}
+
+	templates := make([]flaggerv1.CanaryAnalysis, 0, len(analysis.TemplateRefs))
+	for _, ref := range analysis.TemplateRefs {
+		if ref.IsCluster() {
+			if c.flaggerInformers.ClusterTemplateInformer == nil {
+				return fmt.Errorf("cluster analysis template %s error: informer not configured", ref.Name)
+			}
+			template, err := c.flaggerInformers.ClusterTemplateInformer.Lister().Get(ref.Name)
+			if err != nil {
+				return fmt.Errorf("cluster analysis template %s error: %w", ref.Name, err)
+			}
+			templates = append(templates, template.Spec.Analysis)
+			continue
+		}
+
+		if c.flaggerInformers.TemplateInformer == nil {
+			return fmt.Errorf("analysis template %s.%s error: informer not configured", ref.Name, cd.Namespace)
+		}
+		template, err := c.flaggerInformers.TemplateInformer.Lister().AnalysisTemplates(cd.Namespace).Get(ref.Name)
+		if err != nil {
+			return fmt.Errorf("analysis template %s.%s error: %w", ref.Name, cd.Namespace, err)
+		}
+		templates = append(templates, template.Spec.Analysis)
+	}
+
+	cd.SetAnalysisTemplates(templates)
+	return nil
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestScheduler_DeploymentAnalysisTemplates(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	template := &flaggerv1.AnalysisTemplate{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "slo",
+		},
+		Spec: flaggerv1.AnalysisTemplateSpec{
+			Analysis: flaggerv1.CanaryAnalysis{
+				Threshold: 5,
+				Metrics:   cd.Spec.Analysis.Metrics,
+			},
+		},
+	}
+	clusterTemplate := &flaggerv1.ClusterAnalysisTemplate{
+		TypeMeta:   metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "load-test"},
+		Spec: flaggerv1.AnalysisTemplateSpec{
+			Analysis: flaggerv1.CanaryAnalysis{
+				Webhooks: []flaggerv1.CanaryWebhook{
+					{Name: "load-test", Type: flaggerv1.RolloutHook, URL: "http://flagger-loadtester.test/"},
+				},
+			},
+		},
+	}
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:           "request-success-rate",
+			ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(99), Max: toFloatPtr(100)},
+			Interval:       "1m",
+		},
+	}
+	cd.Spec.Analysis.TemplateRefs = []flaggerv1.AnalysisTemplateReference{
+		{Name: "slo"},
+		{Name: "load-test", Kind: flaggerv1.ClusterAnalysisTemplateKind},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// the canary is not initialized while a template is missing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	require.NoError(t, mocks.ctrl.flaggerInformers.TemplateInformer.Informer().GetIndexer().Add(template))
+	require.NoError(t, mocks.ctrl.flaggerInformers.ClusterTemplateInformer.Informer().GetIndexer().Add(clusterTemplate))
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.resolveAnalysisTemplates(c))
+
+	analysis := c.GetAnalysis()
+	// the inline fields override the template fields
+	assert.Equal(t, cd.Spec.Analysis.Threshold, analysis.Threshold)
+	assert.Equal(t, 10, analysis.StepWeight)
+	require.Len(t, analysis.Metrics, 3)
+	assert.Equal(t, float64(99), *analysis.Metrics[0].ThresholdRange.Min)
+	require.Len(t, analysis.Webhooks, 1)
+	assert.Equal(t, "load-test", analysis.Webhooks[0].Name)
+
+	// the merged analysis is not persisted
+	assert.Len(t, c.Spec.Analysis.Metrics, 1)
+	assert.Empty(t, c.Spec.Analysis.Webhooks)
+}
@@ -39,15 +39,18 @@ type Controller struct {
 	flaggerSynced    cache.InformerSynced
 	flaggerWindow    time.Duration
 	workqueue        workqueue.RateLimitingInterface
+	groupQueue       workqueue.RateLimitingInterface
 	eventRecorder    record.EventRecorder
 	logger           *zap.SugaredLogger
 	canaries         *sync.Map
+	analysisRecords  *sync.Map
 	jobs             map[string]CanaryJob
 	recorder         metrics.Recorder
 	notifier         notifier.Interface
 	canaryFactory    *canary.Factory
 	routerFactory    *router.Factory
 	observerFactory  *observers.Factory
+	metricCache      *metricCache
 	meshProvider     string
 	eventWebhook     string
 }
@@ -56,6 +59,10 @@ type Informers struct {
 	CanaryInformer flaggerinformers.CanaryInformer
 	MetricInformer flaggerinformers.MetricTemplateInformer
 	AlertInformer  flaggerinformers.AlertProviderInformer
+	GroupInformer  flaggerinformers.CanaryGroupInformer
+
+	TemplateInformer        flaggerinformers.AnalysisTemplateInformer
+	ClusterTemplateInformer flaggerinformers.ClusterAnalysisTemplateInformer
 }
 
 func NewController(
@@ -90,12 +97,15 @@ func NewController(
 		flaggerInformers: flaggerInformers,
 		flaggerSynced:    flaggerInformers.CanaryInformer.Informer().HasSynced,
 		workqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName),
+		groupQueue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName+"-groups"),
 		eventRecorder:    eventRecorder,
 		logger:           logger,
 		canaries:         new(sync.Map),
+		analysisRecords:  new(sync.Map),
 		jobs:             map[string]CanaryJob{},
 		flaggerWindow:    flaggerWindow,
 		observerFactory:  observerFactory,
+		metricCache:      newMetricCache(flaggerWindow),
 		recorder:         recorder,
 		notifier:         notifier,
 		canaryFactory:    canaryFactory,
@@ -153,6 +163,22 @@ func NewController(
 		},
 	})
 
+	if flaggerInformers.GroupInformer != nil {
+		flaggerInformers.GroupInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: ctrl.enqueueGroup,
+			UpdateFunc: func(old, new interface{}) {
+				ctrl.enqueueGroup(new)
+			},
+		})
+		flaggerInformers.CanaryInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: ctrl.enqueueCanaryGroups,
+			UpdateFunc: func(old, new interface{}) {
+				ctrl.enqueueCanaryGroups(new)
+			},
+			DeleteFunc: ctrl.enqueueCanaryGroups,
+		})
+	}
+
 	return ctrl
 }
 
@@ -160,6 +186,7 @@ func NewController(
 func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 	defer c.workqueue.ShutDown()
+	defer c.groupQueue.ShutDown()
 
 	c.logger.Info("Starting operator")
 
@@ -170,6 +197,13 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 		}, time.Second, stopCh)
 	}
 
+	if c.flaggerInformers.GroupInformer != nil {
+		go wait.Until(func() {
+			for c.processNextGroup() {
+			}
+		}, time.Second, stopCh)
+	}
+
 	c.logger.Info("Started operator workers")
 
 	tickChan := time.NewTicker(c.flaggerWindow).C
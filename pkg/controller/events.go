@@ -3,12 +3,14 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	corev1 "k8s.io/api/core/v1"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/internal"
 	"github.com/weaveworks/flagger/pkg/notifier"
 )
 
@@ -30,6 +32,19 @@ func (c *Controller) recordEventWarningf(r *flaggerv1.Canary, template string, a
 	c.sendEventToWebhook(r, corev1.EventTypeWarning, template, args)
 }
 
+// recordDeprecatedAnnotations emits a warning for each deprecated annotation read as a fallback for a typed field
+func (c *Controller) recordDeprecatedAnnotations(r *flaggerv1.Canary) {
+	deprecated := internal.DeprecatedAnnotations(r)
+	annotations := make([]string, 0, len(deprecated))
+	for annotation := range deprecated {
+		annotations = append(annotations, annotation)
+	}
+	sort.Strings(annotations)
+	for _, annotation := range annotations {
+		c.recordEventWarningf(r, "Annotation %s is deprecated, use %s instead", annotation, deprecated[annotation])
+	}
+}
+
 func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template string, args []interface{}) {
 	webhookOverride := false
 	for _, canaryWebhook := range r.GetAnalysis().Webhooks {
@@ -53,7 +68,7 @@ func (c *Controller) sendEventToWebhook(r *flaggerv1.Canary, eventType, template
 func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bool, severity flaggerv1.AlertSeverity) {
 	var fields []notifier.Field
 	if metadata {
-		fields = alertMetadata(canary)
+		fields = alertMetadata(canary, c.canaryProvider(canary))
 	}
 
 	// send alert with the global notifier
@@ -148,7 +163,7 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 	}
 }
 
-func alertMetadata(canary *flaggerv1.Canary) []notifier.Field {
+func alertMetadata(canary *flaggerv1.Canary, provider string) []notifier.Field {
 	var fields []notifier.Field
 	fields = append(fields,
 		notifier.Field{
@@ -178,7 +193,7 @@ func alertMetadata(canary *flaggerv1.Canary) []notifier.Field {
 				canary.GetAnalysis().StepWeight,
 				canary.GetAnalysis().MaxWeight),
 		})
-	} else if len(canary.GetAnalysis().Match) > 0 {
+	} else if canary.HasMatchConditions(provider) {
 		fields = append(fields, notifier.Field{
 			Name:  "Traffic routing",
 			Value: "A/B Testing",
@@ -0,0 +1,296 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+)
+
+// enqueueGroup adds the canary group to the group work queue
+func (c *Controller) enqueueGroup(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.groupQueue.Add(key)
+}
+
+// enqueueCanaryGroups adds the groups having the canary as member to the group work queue,
+// the group status follows the phase of its members
+func (c *Controller) enqueueCanaryGroups(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	cd, ok := obj.(*flaggerv1.Canary)
+	if !ok {
+		return
+	}
+	groups, err := c.flaggerInformers.GroupInformer.Lister().CanaryGroups(cd.Namespace).List(labels.Everything())
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("Canary groups list query failed: %v", err)
+		return
+	}
+	for _, group := range groups {
+		if _, ok := group.GetMember(cd.Name); ok {
+			c.enqueueGroup(group)
+		}
+	}
+}
+
+func (c *Controller) processNextGroup() bool {
+	obj, shutdown := c.groupQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.groupQueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.groupQueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in group workqueue but got %#v", obj))
+		return true
+	}
+	if err := c.syncGroup(key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("error syncing canary group '%s': %w", key, err))
+		c.groupQueue.AddRateLimited(key)
+		return true
+	}
+	c.groupQueue.Forget(obj)
+	return true
+}
+
+// syncGroup computes the status of the canary group
+// and reverts the members promoted during a failed group release
+func (c *Controller) syncGroup(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	group, err := c.flaggerInformers.GroupInformer.Lister().CanaryGroups(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("canary group %s.%s get query failed: %w", name, namespace, err)
+	}
+	return c.reconcileGroup(group.DeepCopy())
+}
+
+func (c *Controller) reconcileGroup(group *flaggerv1.CanaryGroup) error {
+	order, err := group.SortMembers()
+	if err != nil {
+		status := flaggerv1.CanaryGroupStatus{
+			Phase:   flaggerv1.CanaryPhaseFailed,
+			Message: err.Error(),
+			Members: group.Status.Members,
+		}
+		return c.setGroupStatus(group, status)
+	}
+
+	previous := make(map[string]flaggerv1.CanaryGroupMemberStatus, len(group.Status.Members))
+	for _, s := range group.Status.Members {
+		previous[s.Name] = s
+	}
+
+	members := make(map[string]*flaggerv1.Canary, len(order))
+	for _, name := range order {
+		cd, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(group.Namespace).Get(name)
+		if err != nil {
+			return fmt.Errorf("canary %s.%s get query failed: %w", name, group.Namespace, err)
+		}
+		members[name] = cd
+	}
+
+	status := flaggerv1.CanaryGroupStatus{
+		Phase:   flaggerv1.CanaryPhaseSucceeded,
+		Message: "All members succeeded.",
+		Members: make([]flaggerv1.CanaryGroupMemberStatus, 0, len(order)),
+	}
+	var failed, pending []string
+	for _, name := range order {
+		cd := members[name]
+		member, _ := group.GetMember(name)
+		held := false
+		for _, dep := range member.DependsOn {
+			if !c.isMemberSettled(members[dep]) {
+				held = true
+			}
+		}
+
+		s := previous[name]
+		s.Name = name
+		s.Phase = cd.Status.Phase
+		s.CanaryWeight = cd.Status.CanaryWeight
+		s.Held = held
+		status.Members = append(status.Members, s)
+
+		switch {
+		case cd.Status.Phase == flaggerv1.CanaryPhaseFailed:
+			failed = append(failed, name)
+		case held || !c.isMemberSettled(cd):
+			pending = append(pending, name)
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		status.Phase = flaggerv1.CanaryPhaseFailed
+		status.Message = fmt.Sprintf("Members %s failed.", strings.Join(failed, ", "))
+	case len(pending) > 0:
+		status.Phase = flaggerv1.CanaryPhaseProgressing
+		status.Message = fmt.Sprintf("Members %s are progressing.", strings.Join(pending, ", "))
+	}
+
+	// a group release starts, record the promoted spec of every member
+	if status.Phase == flaggerv1.CanaryPhaseProgressing && group.Status.Phase != flaggerv1.CanaryPhaseProgressing {
+		for i := range status.Members {
+			status.Members[i].BaselineSpec = members[status.Members[i].Name].Status.LastPromotedSpec
+			status.Members[i].RolledBack = false
+		}
+		c.recordGroupEventf(group, corev1.EventTypeNormal, "Starting release of group %s.%s", group.Name, group.Namespace)
+	}
+
+	// revert the members promoted during the release in reverse dependency order
+	if status.Phase == flaggerv1.CanaryPhaseFailed {
+		for i := len(status.Members) - 1; i >= 0; i-- {
+			s := &status.Members[i]
+			cd := members[s.Name]
+			if s.RolledBack || s.BaselineSpec == "" || cd.Status.Phase != flaggerv1.CanaryPhaseSucceeded ||
+				cd.Status.LastPromotedSpec == s.BaselineSpec {
+				continue
+			}
+			if err := c.revertGroupMember(cd.DeepCopy()); err != nil {
+				c.recordGroupEventf(group, corev1.EventTypeWarning, "Rolling back %s.%s failed: %v", cd.Name, cd.Namespace, err)
+				continue
+			}
+			s.RolledBack = true
+			c.recordGroupEventf(group, corev1.EventTypeWarning, "Rolling back %s.%s after members %s failed",
+				cd.Name, cd.Namespace, strings.Join(failed, ", "))
+			c.recordEventWarningf(cd, "Rolling back %s.%s after members %s of group %s failed",
+				cd.Name, cd.Namespace, strings.Join(failed, ", "), group.Name)
+		}
+	}
+
+	if status.Phase != group.Status.Phase {
+		switch status.Phase {
+		case flaggerv1.CanaryPhaseSucceeded:
+			c.recordGroupEventf(group, corev1.EventTypeNormal, "Release of group %s.%s succeeded", group.Name, group.Namespace)
+		case flaggerv1.CanaryPhaseFailed:
+			c.recordGroupEventf(group, corev1.EventTypeWarning, "Release of group %s.%s failed: %s", group.Name, group.Namespace, status.Message)
+		}
+	}
+
+	return c.setGroupStatus(group, status)
+}
+
+// heldByGroup returns the name of the group that holds the canary until its dependencies succeed
+func (c *Controller) heldByGroup(cd *flaggerv1.Canary) (string, bool) {
+	if c.flaggerInformers.GroupInformer == nil {
+		return "", false
+	}
+	groups, err := c.flaggerInformers.GroupInformer.Lister().CanaryGroups(cd.Namespace).List(labels.Everything())
+	if err != nil {
+		c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("Canary groups list query failed: %v", err)
+		return "", false
+	}
+	for _, group := range groups {
+		member, ok := group.GetMember(cd.Name)
+		if !ok {
+			continue
+		}
+		// the admission webhook rejects invalid groups, ignore the ones created without it
+		if _, err := group.SortMembers(); err != nil {
+			continue
+		}
+		for _, dep := range member.DependsOn {
+			depCanary, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(cd.Namespace).Get(dep)
+			if err != nil || !c.isMemberSettled(depCanary) {
+				return group.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isMemberSettled returns true if the canary is promoted and has no pending revision
+func (c *Controller) isMemberSettled(cd *flaggerv1.Canary) bool {
+	// every OAM revision is a new target workload, the scheduler starts its analysis
+	// when the target differs from the revision observed by the last promotion
+	if c.meshProvider == flaggerv1.OAMProvider {
+		return cd.Status.Phase == flaggerv1.CanaryPhaseSucceeded && cd.Status.ObservedRevision == cd.Spec.TargetRef.Name
+	}
+	if cd.Status.Phase != flaggerv1.CanaryPhaseSucceeded && cd.Status.Phase != flaggerv1.CanaryPhaseInitialized {
+		return false
+	}
+
+	canaryController := c.canaryFactory.Controller(cd.Spec.TargetRef.Kind)
+	if changed, err := canaryController.HasTargetChanged(cd); err != nil || changed {
+		return false
+	}
+	if changed, err := canaryController.HaveDependenciesChanged(cd); err != nil || changed {
+		return false
+	}
+	return true
+}
+
+func (c *Controller) revertGroupMember(cd *flaggerv1.Canary) error {
+	if c.meshProvider == flaggerv1.OAMProvider {
+		rollingController, err := canary.NewRollingController(c.canaryFactory, cd)
+		if err != nil {
+			return err
+		}
+		return rollingController.RevertTarget(cd)
+	}
+	return c.canaryFactory.Controller(cd.Spec.TargetRef.Kind).RevertTarget(cd)
+}
+
+func (c *Controller) setGroupStatus(group *flaggerv1.CanaryGroup, status flaggerv1.CanaryGroupStatus) error {
+	if cmp.Equal(group.Status, status, cmpopts.IgnoreFields(flaggerv1.CanaryGroupStatus{}, "LastTransitionTime")) {
+		return nil
+	}
+
+	firstTry := true
+	name, ns := group.GetName(), group.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			group, err = c.flaggerClient.FlaggerV1beta1().CanaryGroups(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary group %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		groupCopy := group.DeepCopy()
+		groupCopy.Status = status
+		groupCopy.Status.LastTransitionTime = group.Status.LastTransitionTime
+		if status.Phase != group.Status.Phase {
+			groupCopy.Status.LastTransitionTime = metav1.Now()
+		}
+		_, err = c.flaggerClient.FlaggerV1beta1().CanaryGroups(ns).UpdateStatus(context.TODO(), groupCopy, metav1.UpdateOptions{})
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("updating canary group %s.%s status failed: %w", name, ns, err)
+	}
+	return nil
+}
+
+func (c *Controller) recordGroupEventf(group *flaggerv1.CanaryGroup, eventType string, template string, args ...interface{}) {
+	c.logger.With("canarygroup", fmt.Sprintf("%s.%s", group.Name, group.Namespace)).Infof(template, args...)
+	c.eventRecorder.Event(group, eventType, "Synced", fmt.Sprintf(template, args...))
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newTestCanaryGroup() *flaggerv1.CanaryGroup {
+	return &flaggerv1.CanaryGroup{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "release",
+		},
+		Spec: flaggerv1.CanaryGroupSpec{
+			Members: []flaggerv1.CanaryGroupMember{
+				{Name: "podinfo", DependsOn: []string{"backend"}},
+				{Name: "backend"},
+			},
+		},
+	}
+}
+
+func newTestGroupReplicaSet(dep *appsv1.Deployment, revision string, image string) *appsv1.ReplicaSet {
+	template := dep.Spec.Template.DeepCopy()
+	template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = revision
+	template.Spec.Containers[0].Image = image
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        dep.Name + "-" + revision,
+			Labels:      template.Labels,
+			Annotations: map[string]string{deploymentRevisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(dep, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: dep.Spec.Selector,
+			Template: *template,
+		},
+	}
+}
+
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+func TestScheduler_DeploymentGroupHold(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	backend := newDeploymentTestCanary()
+	backend.Name = "backend"
+	backend.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	backend, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), backend, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(backend))
+
+	group := newTestCanaryGroup()
+	_, err = mocks.flaggerClient.FlaggerV1beta1().CanaryGroups("default").Create(context.TODO(), group, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.GroupInformer.Informer().GetIndexer().Add(group))
+
+	// hold while the backend is progressing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Conditions, 1)
+	assert.Equal(t, flaggerv1.SuspendedType, c.Status.Conditions[0].Type)
+	assert.Equal(t, corev1.ConditionTrue, c.Status.Conditions[0].Status)
+
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	require.NoError(t, mocks.ctrl.reconcileGroup(group))
+	g, err := mocks.flaggerClient.FlaggerV1beta1().CanaryGroups("default").Get(context.TODO(), "release", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, g.Status.Phase)
+	require.Len(t, g.Status.Members, 2)
+	assert.Equal(t, "backend", g.Status.Members[0].Name)
+	assert.True(t, g.Status.Members[1].Held)
+
+	// start after the backend succeeded
+	backend, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "backend", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.deployer.SyncStatus(backend, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseSucceeded}))
+	backend, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "backend", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(backend))
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, c.Status.Conditions[0].Status)
+
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestScheduler_DeploymentGroupRollback(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	dep := newDeploymentTestDeploymentV2()
+	dep.Name = "backend"
+	dep.UID = types.UID("backend")
+	dep.Annotations = map[string]string{deploymentRevisionAnnotation: "2"}
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(), dep, metav1.CreateOptions{})
+	require.NoError(t, err)
+	for _, rs := range []*appsv1.ReplicaSet{
+		newTestGroupReplicaSet(dep, "1", "quay.io/stefanprodan/podinfo:1.2.0"),
+		newTestGroupReplicaSet(dep, "2", "quay.io/stefanprodan/podinfo:1.2.1"),
+	} {
+		_, err = mocks.kubeClient.AppsV1().ReplicaSets("default").Create(context.TODO(), rs, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	backend := newDeploymentTestCanary()
+	backend.Name = "backend"
+	backend.Spec.TargetRef.Name = "backend"
+	backend.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	backend.Status.LastPromotedSpec = "v2"
+	backend, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Create(context.TODO(), backend, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(backend))
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	c.Status.Phase = flaggerv1.CanaryPhaseFailed
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(c))
+
+	group := newTestCanaryGroup()
+	group.Status = flaggerv1.CanaryGroupStatus{
+		Phase: flaggerv1.CanaryPhaseProgressing,
+		Members: []flaggerv1.CanaryGroupMemberStatus{
+			{Name: "backend", BaselineSpec: "v1"},
+			{Name: "podinfo", BaselineSpec: "v1"},
+		},
+	}
+	group, err = mocks.flaggerClient.FlaggerV1beta1().CanaryGroups("default").Create(context.TODO(), group, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, mocks.ctrl.reconcileGroup(group))
+
+	// the backend promoted during the release is reverted to the previous revision
+	reverted, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "backend", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", reverted.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, reverted.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	g, err := mocks.flaggerClient.FlaggerV1beta1().CanaryGroups("default").Get(context.TODO(), "release", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, g.Status.Phase)
+	assert.Equal(t, "Members podinfo failed.", g.Status.Message)
+	require.Len(t, g.Status.Members, 2)
+	assert.True(t, g.Status.Members[0].RolledBack)
+	assert.False(t, g.Status.Members[1].RolledBack)
+}
+
+func TestController_isMemberSettledOAM(t *testing.T) {
+	ctrl := &Controller{meshProvider: flaggerv1.OAMProvider}
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.TargetRef.Name = "podinfo-v2"
+	cd.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	cd.Status.ObservedRevision = "podinfo-v2"
+	assert.True(t, ctrl.isMemberSettled(cd))
+
+	// a new revision is pending until its analysis succeeds
+	cd.Spec.TargetRef.Name = "podinfo-v3"
+	assert.False(t, ctrl.isMemberSettled(cd))
+
+	cd.Status.ObservedRevision = "podinfo-v3"
+	cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	assert.False(t, ctrl.isMemberSettled(cd))
+}
+
+func TestController_syncGroupOnMemberChange(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.groupQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName+"-groups")
+	defer mocks.ctrl.groupQueue.ShutDown()
+
+	backend := newDeploymentTestCanary()
+	backend.Name = "backend"
+	backend.Status.Phase = flaggerv1.CanaryPhaseFailed
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(backend))
+
+	group := newTestCanaryGroup()
+	_, err := mocks.flaggerClient.FlaggerV1beta1().CanaryGroups("default").Create(context.TODO(), group, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, mocks.ctrl.flaggerInformers.GroupInformer.Informer().GetIndexer().Add(group))
+
+	// the groups of the changed canary are queued
+	other := newDeploymentTestCanary()
+	other.Name = "frontend"
+	mocks.ctrl.enqueueCanaryGroups(other)
+	assert.Equal(t, 0, mocks.ctrl.groupQueue.Len())
+	mocks.ctrl.enqueueCanaryGroups(cache.DeletedFinalStateUnknown{Key: "default/backend", Obj: backend})
+	require.Equal(t, 1, mocks.ctrl.groupQueue.Len())
+
+	require.True(t, mocks.ctrl.processNextGroup())
+	g, err := mocks.flaggerClient.FlaggerV1beta1().CanaryGroups("default").Get(context.TODO(), "release", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, g.Status.Phase)
+	assert.Equal(t, "Members backend failed.", g.Status.Message)
+}
@@ -1,26 +1,48 @@
 package controller
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // CanaryJob holds the reference to a canary deployment schedule
 type CanaryJob struct {
 	Name             string
 	Namespace        string
-	function         func(name string, namespace string)
+	function         func(ctx context.Context, name string, namespace string)
 	done             chan bool
 	ticker           *time.Ticker
 	analysisInterval time.Duration
+	ctx              context.Context
+	cancel           context.CancelFunc
+}
+
+// newCanaryJob returns a job that runs the function on the analysis interval
+// until it is stopped
+func newCanaryJob(name string, namespace string, analysisInterval time.Duration,
+	function func(ctx context.Context, name string, namespace string)) CanaryJob {
+	ctx, cancel := context.WithCancel(context.Background())
+	return CanaryJob{
+		Name:             name,
+		Namespace:        namespace,
+		function:         function,
+		done:             make(chan bool),
+		ticker:           time.NewTicker(analysisInterval),
+		analysisInterval: analysisInterval,
+		ctx:              ctx,
+		cancel:           cancel,
+	}
 }
 
 // Start runs the canary analysis on a schedule
 func (j CanaryJob) Start() {
 	go func() {
 		// run the infra bootstrap on job creation
-		j.function(j.Name, j.Namespace)
+		j.run()
 		for {
 			select {
 			case <-j.ticker.C:
-				j.function(j.Name, j.Namespace)
+				j.run()
 			case <-j.done:
 				return
 			}
@@ -28,8 +50,17 @@ func (j CanaryJob) Start() {
 	}()
 }
 
-// Stop closes the job channel and stops the ticker
+// run calls the job function with a context that is cancelled
+// when the analysis interval elapses or when the job is stopped
+func (j CanaryJob) run() {
+	ctx, cancel := context.WithTimeout(j.ctx, j.analysisInterval)
+	defer cancel()
+	j.function(ctx, j.Name, j.Namespace)
+}
+
+// Stop cancels the running analysis, closes the job channel and stops the ticker
 func (j CanaryJob) Stop() {
+	j.cancel()
 	close(j.done)
 	j.ticker.Stop()
 }
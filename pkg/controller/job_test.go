@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanaryJob_Stop(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	job := newCanaryJob("podinfo", "default", time.Minute, func(ctx context.Context, name string, namespace string) {
+		close(started)
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+	})
+	job.Start()
+
+	<-started
+	job.Stop()
+	select {
+	case err := <-cancelled:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("the running analysis was not cancelled")
+	}
+}
+
+func TestCanaryJob_AnalysisInterval(t *testing.T) {
+	deadlines := make(chan time.Time, 1)
+	job := newCanaryJob("podinfo", "default", time.Minute, func(ctx context.Context, name string, namespace string) {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+	})
+	begin := time.Now()
+	job.Start()
+	defer job.Stop()
+
+	deadline := <-deadlines
+	require.False(t, deadline.IsZero())
+	assert.WithinDuration(t, begin.Add(time.Minute), deadline, time.Second)
+}
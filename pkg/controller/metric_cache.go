@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/weaveworks/flagger/pkg/metrics/observers"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// metricSecretTTL is the time a metric template secret is reused before being fetched again,
+// the providers are rebuilt only if the secret resource version changed
+const metricSecretTTL = time.Minute
+
+// metricProviderTTL is the time a provider is kept in the cache after its last use,
+// the providers of the deleted templates and canaries are evicted once it elapsed
+const metricProviderTTL = 10 * time.Minute
+
+// metricCache is shared by the canary jobs, it holds the metric template secrets and providers
+// and the results of the queries executed during the last control loop interval
+type metricCache struct {
+	queryTTL time.Duration
+
+	mu        sync.Mutex
+	secrets   map[string]cachedSecret
+	providers map[string]cachedProvider
+	queries   map[string]*cachedQuery
+	pruned    time.Time
+}
+
+type cachedSecret struct {
+	secret  *corev1.Secret
+	expires time.Time
+}
+
+type cachedProvider struct {
+	version  string
+	provider providers.Interface
+	used     time.Time
+}
+
+// cachedQuery is the result of a query, the expiration is zero while the query is in flight
+type cachedQuery struct {
+	done    chan struct{}
+	val     float64
+	err     error
+	expires time.Time
+}
+
+// newMetricCache returns a cache whose query results are reused for the query TTL
+func newMetricCache(queryTTL time.Duration) *metricCache {
+	return &metricCache{
+		queryTTL:  queryTTL,
+		secrets:   make(map[string]cachedSecret),
+		providers: make(map[string]cachedProvider),
+		queries:   make(map[string]*cachedQuery),
+	}
+}
+
+// secret returns the secret from the cache or fetches it from the API server if it expired,
+// a nil cache always fetches the secret
+func (mc *metricCache) secret(ctx context.Context, kubeClient kubernetes.Interface, namespace string, name string) (*corev1.Secret, error) {
+	if mc == nil {
+		return kubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	mc.mu.Lock()
+	cached, ok := mc.secrets[key]
+	mc.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.secret, nil
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	mc.mu.Lock()
+	mc.secrets[key] = cachedSecret{secret: secret, expires: time.Now().Add(metricSecretTTL)}
+	mc.prune(time.Now())
+	mc.mu.Unlock()
+	return secret, nil
+}
+
+// provider returns the cached provider if its version matches or builds a new one,
+// the version is made of the resource versions of the template and of its secret
+func (mc *metricCache) provider(key string, version string, build func() (providers.Interface, error)) (providers.Interface, error) {
+	if mc == nil {
+		return build()
+	}
+
+	now := time.Now()
+	mc.mu.Lock()
+	cached, ok := mc.providers[key]
+	if ok && cached.version == version {
+		cached.used = now
+		mc.providers[key] = cached
+		mc.prune(now)
+		mc.mu.Unlock()
+		return cached.provider, nil
+	}
+	mc.mu.Unlock()
+
+	provider, err := build()
+	if err != nil {
+		return nil, err
+	}
+	mc.mu.Lock()
+	mc.providers[key] = cachedProvider{version: version, provider: provider, used: time.Now()}
+	mc.prune(time.Now())
+	mc.mu.Unlock()
+	return provider, nil
+}
+
+// prune evicts the expired secrets and the providers not used within the provider TTL,
+// it runs at most once per secret TTL and must be called with the lock held
+func (mc *metricCache) prune(now time.Time) {
+	if now.Before(mc.pruned.Add(metricSecretTTL)) {
+		return
+	}
+	mc.pruned = now
+	for k, v := range mc.secrets {
+		if now.After(v.expires) {
+			delete(mc.secrets, k)
+		}
+	}
+	for k, v := range mc.providers {
+		if now.After(v.used.Add(metricProviderTTL)) {
+			delete(mc.providers, k)
+		}
+	}
+}
+
+// observerFactory returns the observer factory of the metrics server address,
+// its Prometheus client is built once and shared by the canaries using the same address
+func (mc *metricCache) observerFactory(address string) (*observers.Factory, error) {
+	client, err := mc.provider("metrics-server/"+address, "", func() (providers.Interface, error) {
+		factory, err := observers.NewFactory(address)
+		if err != nil {
+			return nil, err
+		}
+		return factory.Client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &observers.Factory{Client: client}, nil
+}
+
+// query runs the query once for all the callers using the same key during the query TTL,
+// the failed queries are not cached and a caller whose leader was cancelled runs the query itself
+func (mc *metricCache) query(ctx context.Context, key string, run func(ctx context.Context) (float64, error)) (float64, error) {
+	if mc == nil {
+		return run(ctx)
+	}
+
+	now := time.Now()
+	mc.mu.Lock()
+	if q, ok := mc.queries[key]; ok && (q.expires.IsZero() || now.Before(q.expires)) {
+		mc.mu.Unlock()
+		select {
+		case <-q.done:
+			if errors.Is(q.err, context.Canceled) || errors.Is(q.err, context.DeadlineExceeded) {
+				return run(ctx)
+			}
+			return q.val, q.err
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	q := &cachedQuery{done: make(chan struct{})}
+	mc.queries[key] = q
+	for k, v := range mc.queries {
+		if !v.expires.IsZero() && now.After(v.expires) {
+			delete(mc.queries, k)
+		}
+	}
+	mc.mu.Unlock()
+
+	q.val, q.err = run(ctx)
+	mc.mu.Lock()
+	if q.err != nil {
+		delete(mc.queries, key)
+	} else {
+		q.expires = time.Now().Add(mc.queryTTL)
+	}
+	mc.mu.Unlock()
+	close(q.done)
+	return q.val, q.err
+}
+
+// cachingProvider de-duplicates the queries of a provider shared by the builtin metrics
+type cachingProvider struct {
+	key      string
+	provider providers.Interface
+	cache    *metricCache
+}
+
+func (p *cachingProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+func (p *cachingProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
+	return p.cache.query(ctx, p.key+"\n"+query, func(ctx context.Context) (float64, error) {
+		return providers.RunQuery(ctx, p.provider, query)
+	})
+}
+
+func (p *cachingProvider) IsOnline() (bool, error) {
+	return p.provider.IsOnline()
+}
+
+func (p *cachingProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	return providers.IsOnline(ctx, p.provider)
+}
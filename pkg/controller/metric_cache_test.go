@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+func TestMetricCache_Query(t *testing.T) {
+	mc := newMetricCache(time.Minute)
+
+	var calls int32
+	release := make(chan struct{})
+	run := func(ctx context.Context) (float64, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 1, nil
+	}
+
+	// the concurrent callers share the query in flight
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := mc.query(context.TODO(), "vector(1)", run)
+			assert.NoError(t, err)
+			assert.Equal(t, float64(1), val)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// the result is reused until it expires
+	_, err := mc.query(context.TODO(), "vector(1)", run)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	mc.queries["vector(1)"].expires = time.Now().Add(-time.Second)
+	_, err = mc.query(context.TODO(), "vector(1)", run)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestMetricCache_QueryError(t *testing.T) {
+	mc := newMetricCache(time.Minute)
+
+	var calls int32
+	run := func(ctx context.Context) (float64, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, providers.ErrNoValuesFound
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := mc.query(context.TODO(), "vector(1)", run)
+		assert.True(t, errors.Is(err, providers.ErrNoValuesFound))
+	}
+	assert.Equal(t, int32(2), calls, "the failed queries are not cached")
+
+	// a nil cache runs the query
+	var nilCache *metricCache
+	_, err := nilCache.query(context.TODO(), "vector(1)", run)
+	assert.True(t, errors.Is(err, providers.ErrNoValuesFound))
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestMetricCache_Provider(t *testing.T) {
+	mc := newMetricCache(time.Minute)
+
+	var builds int
+	build := func() (providers.Interface, error) {
+		builds++
+		return providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: "http://prometheus:9090"}, nil)
+	}
+
+	p1, err := mc.provider("default/latency/1m", "1/", build)
+	require.NoError(t, err)
+	p2, err := mc.provider("default/latency/1m", "1/", build)
+	require.NoError(t, err)
+	assert.Same(t, p1, p2)
+	assert.Equal(t, 1, builds)
+
+	// the template or the secret changed
+	p3, err := mc.provider("default/latency/1m", "1/2", build)
+	require.NoError(t, err)
+	assert.NotSame(t, p1, p3)
+	assert.Equal(t, 2, builds)
+}
+
+func TestMetricCache_Prune(t *testing.T) {
+	mc := newMetricCache(time.Minute)
+	build := func() (providers.Interface, error) {
+		return providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: "http://prometheus:9090"}, nil)
+	}
+
+	_, err := mc.provider("default/latency/1m", "1/", build)
+	require.NoError(t, err)
+	_, err = mc.provider("default/errors/1m", "1/", build)
+	require.NoError(t, err)
+	mc.secrets["default/prom-auth"] = cachedSecret{secret: &corev1.Secret{}, expires: time.Now().Add(-time.Second)}
+
+	// the latency template is no longer used and the secret expired
+	stale := mc.providers["default/latency/1m"]
+	stale.used = time.Now().Add(-metricProviderTTL - time.Second)
+	mc.providers["default/latency/1m"] = stale
+	mc.pruned = time.Now().Add(-metricSecretTTL)
+
+	_, err = mc.provider("default/errors/1m", "1/", build)
+	require.NoError(t, err)
+	assert.NotContains(t, mc.providers, "default/latency/1m")
+	assert.Contains(t, mc.providers, "default/errors/1m")
+	assert.Empty(t, mc.secrets)
+}
+
+func TestMetricCache_ObserverFactory(t *testing.T) {
+	mc := newMetricCache(time.Minute)
+
+	f1, err := mc.observerFactory("http://prometheus:9090")
+	require.NoError(t, err)
+	f2, err := mc.observerFactory("http://prometheus:9090")
+	require.NoError(t, err)
+	assert.Same(t, f1.Client, f2.Client)
+
+	f3, err := mc.observerFactory("http://thanos:9090")
+	require.NoError(t, err)
+	assert.NotSame(t, f1.Client, f3.Client)
+
+	_, err = mc.observerFactory("://prometheus")
+	assert.Error(t, err)
+}
+
+func TestController_runMetricChecksCache(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`))
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	ctrl := mocks.ctrl
+	ctrl.metricCache = newMetricCache(time.Minute)
+	_, err := ctrl.kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus", ResourceVersion: "1"},
+		Data:       map[string][]byte{"username": []byte("flagger"), "password": []byte("flagger")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "requests", ResourceVersion: "1"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{
+				Type:      "prometheus",
+				Address:   ts.URL,
+				SecretRef: &corev1.LocalObjectReference{Name: "prometheus"},
+			},
+			Query: `sum(rate(requests{namespace="{{ namespace }}"}[{{ interval }}]))`,
+		},
+	}
+	require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	canary := newDeploymentTestCanary()
+	var metrics []flaggerv1.CanaryMetric
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		metrics = append(metrics, flaggerv1.CanaryMetric{
+			Name:           name,
+			Interval:       "1m",
+			TemplateRef:    &flaggerv1.CrossNamespaceObjectReference{Name: "requests"},
+			ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(1000)},
+		})
+	}
+	canary.Spec.Analysis.Metrics = metrics
+
+	results := ctrl.runMetricChecks(context.TODO(), canary)
+	require.Len(t, results, len(metrics))
+	for i, result := range results {
+		assert.Equal(t, metrics[i].Name, result.Name)
+		assert.True(t, result.Passed)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// the canaries sharing the control loop interval reuse the results
+	other := canary.DeepCopy()
+	other.Name = "other"
+	results = ctrl.runMetricChecks(context.TODO(), other)
+	require.Len(t, results, len(metrics))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestRunConcurrently(t *testing.T) {
+	var inFlight, maxInFlight int32
+	var checks []func() flaggerv1.CanaryMetricStatus
+	for i := 0; i < 3*metricChecksConcurrency; i++ {
+		weight := float64(i)
+		checks = append(checks, func() flaggerv1.CanaryMetricStatus {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return flaggerv1.CanaryMetricStatus{Weight: weight}
+		})
+	}
+
+	results := runConcurrently(checks)
+	require.Len(t, results, len(checks))
+	for i, result := range results {
+		assert.Equal(t, float64(i), result.Weight)
+	}
+	assert.LessOrEqual(t, int(maxInFlight), metricChecksConcurrency)
+	assert.Greater(t, int(maxInFlight), 1)
+}
@@ -432,7 +432,7 @@ var _ = Describe("OAM Rolling Upgrade Test Suite", func() {
 						Name:      targetResource.GetName(),
 						Namespace: targetResource.GetNamespace(),
 					}
-					ctrl.advanceCanary(canary.Name, canary.Namespace)
+					ctrl.advanceCanary(context.TODO(), canary.Name, canary.Namespace)
 					gomega.Eventually(
 						func() error {
 							err := k8sClient.Get(context.TODO(), objectKey, &targetDeploy)
@@ -505,7 +505,7 @@ var _ = Describe("OAM Rolling Upgrade Test Suite", func() {
 						Name:      canary.GetName(),
 						Namespace: canary.GetNamespace(),
 					}
-					ctrl.advanceCanary(canary.Name, canary.Namespace)
+					ctrl.advanceCanary(context.TODO(), canary.Name, canary.Namespace)
 					err := k8sClient.Get(context.TODO(), objectKey, &updatedCanary)
 					gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
 					return updatedCanary.Status.Phase
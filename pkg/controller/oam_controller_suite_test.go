@@ -123,6 +123,10 @@ func newTestController(kubeClient *kubernetes.Clientset, flaggerClient clientset
 		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
 		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
 		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		GroupInformer:  flaggerInformerFactory.Flagger().V1beta1().CanaryGroups(),
+
+		TemplateInformer:        flaggerInformerFactory.Flagger().V1beta1().AnalysisTemplates(),
+		ClusterTemplateInformer: flaggerInformerFactory.Flagger().V1beta1().ClusterAnalysisTemplates(),
 	}
 
 	// init router
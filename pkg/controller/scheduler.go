@@ -23,7 +23,9 @@ func (c *Controller) scheduleCanaries() {
 	stats := make(map[string]int)
 
 	c.canaries.Range(func(key interface{}, value interface{}) bool {
-		cn := value.(*flaggerv1.Canary)
+		cn := value.(*flaggerv1.Canary).DeepCopy()
+		// the analysis interval can be set by a template, the errors are reported by advanceCanary
+		_ = c.resolveAnalysisTemplates(cn)
 
 		// format: <name>.<namespace>
 		name := key.(string)
@@ -36,14 +38,7 @@ func (c *Controller) scheduleCanaries() {
 				job.Stop()
 			}
 
-			newJob := CanaryJob{
-				Name:             cn.Name,
-				Namespace:        cn.Namespace,
-				function:         c.advanceCanary,
-				done:             make(chan bool),
-				ticker:           time.NewTicker(cn.GetAnalysisInterval()),
-				analysisInterval: cn.GetAnalysisInterval(),
-			}
+			newJob := newCanaryJob(cn.Name, cn.Namespace, cn.GetAnalysisInterval(), c.advanceCanary)
 
 			c.jobs[name] = newJob
 			newJob.Start()
@@ -83,7 +78,8 @@ func (c *Controller) scheduleCanaries() {
 	}
 }
 
-func (c *Controller) advanceCanary(name string, namespace string) {
+// advanceCanary runs the analysis of the canary, the metric queries are cancelled when the context is done
+func (c *Controller) advanceCanary(ctx context.Context, name string, namespace string) {
 	begin := time.Now()
 	// check if the canary exists
 	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
@@ -93,12 +89,15 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
-	// override the global provider if one is specified in the canary spec
-	provider := c.meshProvider
-	if cd.Spec.Provider != "" {
-		provider = cd.Spec.Provider
+	// merge the analysis templates
+	if err := c.resolveAnalysisTemplates(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return
 	}
 
+	// override the global provider if one is specified in the canary spec
+	provider := c.canaryProvider(cd)
+
 	var canaryController canary.Controller
 	var componentName string
 	var rollingController *canary.OAMRolloutController
@@ -146,6 +145,14 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		// other controllers depends on the resource type
 		canaryController = c.canaryFactory.Controller(cd.Spec.TargetRef.Kind)
 	}
+
+	// save the outcome of the checks run during this advancement
+	defer c.saveAnalysisRecord(cd, canaryController)
+
+	// keep the routes and replicas as they are while the canary is suspended
+	if suspended := c.checkSuspended(cd, canaryController); suspended {
+		return
+	}
 	labelSelector, ports, err := canaryController.GetMetadata(cd)
 	if err != nil {
 		c.recordEventWarningf(cd, "%v", err)
@@ -167,9 +174,14 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// warn about the annotations replaced by typed fields
+	if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
+		c.recordDeprecatedAnnotations(cd)
+	}
+
 	// check metric servers' availability
 	if !cd.SkipAnalysis() && (cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing) {
-		if err := c.checkMetricProviderAvailability(cd); err != nil {
+		if err := c.checkMetricProviderAvailability(ctx, cd); err != nil {
 			c.recordEventErrorf(cd, "Error checking metric providers: %v", err)
 		}
 	}
@@ -215,6 +227,11 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		}
 	}
 
+	// run the action requested with the flagger.app/action annotation
+	if ok := c.runAction(cd, canaryController, meshRouter); !ok {
+		return
+	}
+
 	// check for changes
 	shouldAdvance, err := c.shouldAdvance(cd, canaryController)
 	if err != nil {
@@ -289,6 +306,10 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// check canary status
 	var retriable = true
 	retriable, err = canaryController.IsCanaryReady(cd)
+	if err != nil && !retriable && canary.IsResumedWithin(cd, time.Duration(cd.GetProgressDeadlineSeconds())*time.Second) {
+		// the progress deadline is not consumed while the canary is suspended
+		retriable = true
+	}
 	if err != nil && retriable {
 		c.recordEventWarningf(cd, "%v", err)
 		return
@@ -312,6 +333,9 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 
 	// route traffic back to primary if analysis has succeeded
 	if cd.Status.Phase == flaggerv1.CanaryPhasePromoting {
+		if ok := c.checkSchedule(cd, canaryController); !ok {
+			return
+		}
 		c.recordEventInfof(cd, "Promote %s.%s ", cd.Name, cd.Namespace)
 		if err := canaryController.Promote(cd); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
@@ -323,6 +347,9 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 
 	// scale canary to zero if promotion has finished
 	if cd.Status.Phase == flaggerv1.CanaryPhaseFinalising {
+		if ok := c.checkSchedule(cd, canaryController); !ok {
+			return
+		}
 		if err := canaryController.ScaleToZero(cd); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
 			return
@@ -342,18 +369,24 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	}
 
 	// check if the number of failed checks reached the threshold
-	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing &&
+	if (cd.Status.Phase == flaggerv1.CanaryPhaseProgressing || cd.Status.Phase == flaggerv1.CanaryPhaseWaiting) &&
 		(!retriable || cd.Status.FailedChecks >= cd.GetAnalysisThreshold()) {
 		if !retriable {
 			c.recordEventWarningf(cd, "Rolling back %s.%s progress deadline exceeded %v",
 				cd.Name, cd.Namespace, err)
 			c.alert(cd, fmt.Sprintf("Progress deadline exceeded %v", err),
 				false, flaggerv1.SeverityError)
+			c.setStalled(cd, canaryController, fmt.Sprintf("Progress deadline exceeded %v", err))
 		}
 		c.rollback(cd, canaryController, meshRouter)
 		return
 	}
 
+	// hold the advancement outside of the release windows
+	if ok := c.checkSchedule(cd, canaryController); !ok {
+		return
+	}
+
 	// record analysis duration
 	defer func() {
 		c.recorder.SetDuration(cd, time.Since(begin))
@@ -361,7 +394,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 
 	// check if the canary success rate is above the threshold
 	// skip check if no traffic is routed or mirrored to canary
-	if canaryWeight == 0 && cd.Status.Iterations == 0 &&
+	if canaryWeight == 0 && cd.Status.Iterations == 0 && cd.Status.CurrentStepIndex == nil &&
 		!(cd.GetAnalysis().Mirror && mirrored) {
 		c.recordEventInfof(cd, "Starting canary analysis for %s.%s", cd.Spec.TargetRef.Name, cd.Namespace)
 
@@ -373,19 +406,24 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			return
 		}
 	} else {
-		if ok := c.runAnalysis(cd); !ok {
+		switch c.runAnalysis(ctx, cd, canaryController) {
+		case analysisFailed:
 			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
 				c.recordEventWarningf(cd, "%v", err)
 			}
 			return
+		case analysisHeld:
+			return
 		}
 	}
 
 	// use blue/green strategy for kubernetes provider
 	if provider == flaggerv1.KubernetesProvider {
-		if len(cd.GetAnalysis().Match) > 0 {
+		if cd.HasMatchConditions(provider) {
 			c.recordEventWarningf(cd, "A/B testing is not supported when using the kubernetes provider")
 			cd.GetAnalysis().Match = nil
+			cd.GetAnalysis().DubboMatch = nil
+			cd.GetAnalysis().SpringCloudMatch = nil
 		}
 		if cd.GetAnalysis().Iterations < 1 {
 			c.recordEventWarningf(cd, "Progressive traffic is not supported when using the kubernetes provider")
@@ -395,7 +433,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	}
 
 	// strategy: A/B testing
-	if len(cd.GetAnalysis().Match) > 0 && cd.GetAnalysis().Iterations > 0 {
+	if cd.HasMatchConditions(provider) && cd.GetAnalysis().Iterations > 0 {
 		c.runAB(cd, canaryController, meshRouter)
 		return
 	}
@@ -406,6 +444,12 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		return
 	}
 
+	// strategy: Canary explicit steps
+	if len(cd.GetAnalysis().Steps) > 0 {
+		c.runCanarySteps(cd, canaryController, meshRouter)
+		return
+	}
+
 	// strategy: Canary progressive traffic increase
 	if cd.GetAnalysis().StepWeight >= 0 {
 		c.runCanary(cd, canaryController, meshRouter, mirrored, canaryWeight, primaryWeight, maxWeight)
@@ -424,7 +468,7 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 	}
 
 	// route all traffic to primary in one go when promotion step wight is not set
-	if canary.Spec.Analysis.StepWeightPromotion == 0 {
+	if canary.GetAnalysis().StepWeightPromotion == 0 {
 		c.recordEventInfof(canary, "Promoting the traffic to the new targe in one shot")
 		if err := meshRouter.SetRoutes(canary, 100, 0, false); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
@@ -529,6 +573,76 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 	}
 }
 
+func (c *Controller) runCanarySteps(canary *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface) {
+	steps := canary.GetAnalysis().Steps
+	next := 0
+
+	// wait for the bake duration and the approval of the current step
+	if canary.Status.CurrentStepIndex != nil {
+		current := *canary.Status.CurrentStepIndex
+		if current >= len(steps) {
+			current = len(steps) - 1
+		}
+		step := steps[current]
+
+		if canary.Status.CurrentStepStartTime != nil {
+			if remaining := step.GetPause() - time.Since(canary.Status.CurrentStepStartTime.Time); remaining > 0 {
+				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+					Infof("Pausing at step %d/%d for another %v", current+1, len(steps), remaining.Round(time.Second))
+				return
+			}
+		}
+
+		if step.Approval {
+			if approved := c.runConfirmStepHooks(canary, current); !approved {
+				return
+			}
+		}
+		next = current + 1
+	}
+
+	// promote canary - all steps completed
+	if next >= len(steps) {
+		// check promotion gate
+		if promote := c.runConfirmPromotionHooks(canary); !promote {
+			return
+		}
+		// update status phase
+		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromoting); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+		}
+		return
+	}
+
+	if ok := c.setCanaryStep(canary, canaryController, meshRouter, next); ok {
+		c.recordEventInfof(canary, "Advance %s.%s canary step %d/%d weight %v",
+			canary.Name, canary.Namespace, next+1, len(steps), steps[next].Weight)
+	}
+}
+
+// setCanaryStep routes the traffic to the canary with the weight of the given step and starts the step
+func (c *Controller) setCanaryStep(canary *flaggerv1.Canary, canaryController canary.Controller,
+	meshRouter router.Interface, index int) bool {
+	// the scalable routers read the step replicas from status
+	canaryWeight := canary.GetAnalysis().Steps[index].Weight
+	primaryWeight := 100 - canaryWeight
+	canaryStep := canary.DeepCopy()
+	canaryStep.Status.CurrentStepIndex = &index
+	if err := meshRouter.SetRoutes(canaryStep, primaryWeight, canaryWeight, false); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return false
+	}
+
+	if err := canaryController.SetStatusStep(canary, index); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return false
+	}
+
+	c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
+	return true
+}
+
 func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Controller,
 	meshRouter router.Interface) {
 	// route traffic to canary and increment iterations
@@ -624,30 +738,52 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 
 }
 
-func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
+// canaryProvider returns the provider of the canary, the global provider is overridden by the canary spec
+func (c *Controller) canaryProvider(cd *flaggerv1.Canary) string {
+	if cd.Spec.Provider != "" {
+		return cd.Spec.Provider
+	}
+	return c.meshProvider
+}
+
+// analysisResult is the outcome of an analysis run
+type analysisResult int
+
+const (
+	// analysisPassed advances the canary
+	analysisPassed analysisResult = iota
+	// analysisHeld halts the advancement without counting a failed check
+	analysisHeld
+	// analysisFailed halts the advancement and counts a failed check
+	analysisFailed
+)
+
+func (c *Controller) runAnalysis(ctx context.Context, canary *flaggerv1.Canary, canaryController canary.Controller) analysisResult {
 	// run external checks
 	for _, webhook := range canary.GetAnalysis().Webhooks {
 		if webhook.Type == "" || webhook.Type == flaggerv1.RolloutHook {
 			err := CallWebhook(canary.Name, canary.Namespace, flaggerv1.CanaryPhaseProgressing, webhook)
+			c.recordWebhook(canary, webhook, err)
 			if err != nil {
 				c.recordEventWarningf(canary, "Halt %s.%s advancement external check %s failed %v",
 					canary.Name, canary.Namespace, webhook.Name, err)
-				return false
+				return analysisFailed
 			}
 		}
 	}
 
-	ok := c.runBuiltinMetricChecks(canary)
-	if !ok {
-		return ok
-	}
-
-	ok = c.runMetricChecks(canary)
-	if !ok {
-		return ok
+	// run all the metric checks and score them
+	results := append(c.runBuiltinMetricChecks(ctx, canary), c.runMetricChecks(ctx, canary)...)
+	results = append(results, c.runPodMetricChecks(ctx, canary, canaryController)...)
+	if len(results) > 0 {
+		status, result := c.scoreMetrics(canary, results)
+		c.analysisRecord(canary).Metrics = &status
+		if result != analysisPassed {
+			return result
+		}
 	}
 
-	return true
+	return c.runJudge(ctx, canary)
 }
 
 func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) bool {
@@ -724,6 +860,7 @@ func (c *Controller) shouldAdvance(canary *flaggerv1.Canary, canaryController ca
 func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryController canary.Controller, shouldAdvance bool) bool {
 	c.recorder.SetStatus(canary, canary.Status.Phase)
 	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
+		canary.Status.Phase == flaggerv1.CanaryPhaseWaiting && canary.GetAnalysis().Schedule != nil ||
 		canary.Status.Phase == flaggerv1.CanaryPhasePromoting ||
 		canary.Status.Phase == flaggerv1.CanaryPhaseFinalising {
 		return true
@@ -755,6 +892,7 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 		c.recordEventInfof(canaryPhaseProgressing, "New revision detected! Scaling up %s.%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace)
 		c.alert(canaryPhaseProgressing, "New revision detected, progressing canary analysis.",
 			true, flaggerv1.SeverityInfo)
+		c.recordDeprecatedAnnotations(canaryPhaseProgressing)
 
 		if err := canaryController.ScaleFromZero(canary); err != nil {
 			c.recordEventErrorf(canary, "%v", err)
@@ -770,8 +908,99 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 	return false
 }
 
+// checkSchedule moves the canary to the waiting phase outside of the release windows
+// and back to progressing once a window opens, it returns false if the analysis must not advance.
+// A promotion in progress keeps its phase and is held until a window opens.
+func (c *Controller) checkSchedule(cd *flaggerv1.Canary, canaryController canary.Controller) bool {
+	open := true
+	schedule := cd.GetAnalysis().Schedule
+	if schedule != nil {
+		var err error
+		if open, err = schedule.IsOpen(time.Now()); err != nil {
+			c.recordEventWarningf(cd, "Halt %s.%s advancement invalid schedule %v", cd.Name, cd.Namespace, err)
+			return false
+		}
+	}
+
+	if !open && (cd.Status.Phase == flaggerv1.CanaryPhasePromoting || cd.Status.Phase == flaggerv1.CanaryPhaseFinalising) {
+		c.recordEventInfof(cd, "Halt %s.%s promotion outside of the release windows", cd.Name, cd.Namespace)
+		return false
+	}
+
+	if !open {
+		if cd.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+			if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseWaiting); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+				return false
+			}
+			if next, ok := schedule.NextOpen(time.Now()); ok {
+				c.recordEventInfof(cd, "Halt %s.%s advancement outside of the release windows, next window opens at %s",
+					cd.Name, cd.Namespace, next.UTC().Format(time.RFC3339))
+			} else {
+				c.recordEventWarningf(cd, "Halt %s.%s advancement outside of the release windows, no window opens in the next week",
+					cd.Name, cd.Namespace)
+			}
+			c.alert(cd, "Canary is waiting for a release window.", false, flaggerv1.SeverityInfo)
+		}
+		return false
+	}
+
+	if cd.Status.Phase == flaggerv1.CanaryPhaseWaiting {
+		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseProgressing); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return false
+		}
+		cd.Status.Phase = flaggerv1.CanaryPhaseProgressing
+		c.recordEventInfof(cd, "Release window open, resuming %s.%s advancement at weight %d",
+			cd.Name, cd.Namespace, cd.Status.CanaryWeight)
+	}
+	return true
+}
+
+// checkSuspended records the transitions of spec.suspend and of the canary group hold in the canary status
+// and returns true if the analysis must not advance
+func (c *Controller) checkSuspended(cd *flaggerv1.Canary, canaryController canary.Controller) bool {
+	group, held := c.heldByGroup(cd)
+	suspended := cd.Spec.Suspend || held
+	if changed, conditions := canary.MakeSuspendedCondition(cd, suspended); changed {
+		if err := canaryController.SetStatusSuspended(cd, suspended); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return true
+		}
+		cd.Status.Conditions = conditions
+		switch {
+		case cd.Spec.Suspend:
+			c.recordEventInfof(cd, "Suspended %s.%s advancement at weight %d iteration %d",
+				cd.Name, cd.Namespace, cd.Status.CanaryWeight, cd.Status.Iterations)
+		case held:
+			c.recordEventInfof(cd, "Holding %s.%s advancement until its dependencies in group %s succeed",
+				cd.Name, cd.Namespace, group)
+		default:
+			c.recordEventInfof(cd, "Resumed %s.%s advancement at weight %d iteration %d",
+				cd.Name, cd.Namespace, cd.Status.CanaryWeight, cd.Status.Iterations)
+		}
+	}
+
+	if suspended {
+		c.recorder.SetStatus(cd, cd.Status.Phase)
+		return true
+	}
+	return false
+}
+
+// setStalled records in the canary status that the canary workload didn't become ready in time
+func (c *Controller) setStalled(cd *flaggerv1.Canary, canaryController canary.Controller, message string) {
+	if changed, conditions := canary.MakeStalledCondition(cd, message); changed {
+		if err := canaryController.SetStatusStalled(cd, message); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+		cd.Status.Conditions = conditions
+	}
+}
+
 func (c *Controller) hasCanaryRevisionChanged(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
-	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing {
+	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing || canary.Status.Phase == flaggerv1.CanaryPhaseWaiting {
 		if diff, _ := canaryController.HasTargetChanged(canary); diff {
 			return true
 		}
@@ -823,6 +1052,7 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 
 func (c *Controller) setPhaseInitializing(cd *flaggerv1.Canary) error {
 	phase := flaggerv1.CanaryPhaseInitializing
+	generation := cd.Generation
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
@@ -838,6 +1068,7 @@ func (c *Controller) setPhaseInitializing(cd *flaggerv1.Canary) error {
 			cdCopy.Status.Conditions = conditions
 			cdCopy.Status.LastTransitionTime = metav1.Now()
 			cdCopy.Status.Phase = phase
+			cdCopy.Status.ObservedGeneration = generation
 			_, err = c.flaggerClient.FlaggerV1beta1().Canaries(cd.Namespace).UpdateStatus(context.TODO(), cdCopy, metav1.UpdateOptions{})
 		}
 		firstTry = false
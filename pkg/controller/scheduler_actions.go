@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/router"
+)
+
+// runAction executes the action requested with the flagger.app/action annotation and removes the request,
+// it returns false if an action was executed and the analysis must not advance in this run
+func (c *Controller) runAction(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) bool {
+	action, actor, ok := cd.GetAction()
+	if !ok {
+		return true
+	}
+
+	// the request is removed before running the action to execute it only once
+	if err := c.clearAction(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+
+	phase := cd.Status.Phase
+	switch {
+	case action == flaggerv1.CanaryActionPromote && (phase == flaggerv1.CanaryPhaseProgressing ||
+		phase == flaggerv1.CanaryPhaseWaiting || phase == flaggerv1.CanaryPhaseFailed):
+		c.recordEventInfof(cd, "Action %s requested by %s, promoting %s.%s", action, actor, cd.Name, cd.Namespace)
+		c.alert(cd, fmt.Sprintf("Promotion requested by %s", actor), false, flaggerv1.SeverityInfo)
+		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhasePromoting); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+		return false
+	case action == flaggerv1.CanaryActionAbort && (phase == flaggerv1.CanaryPhaseProgressing ||
+		phase == flaggerv1.CanaryPhaseWaiting):
+		c.recordEventWarningf(cd, "Action %s requested by %s, rolling back %s.%s", action, actor, cd.Name, cd.Namespace)
+		c.alert(cd, fmt.Sprintf("Rolling back, abort requested by %s", actor), false, flaggerv1.SeverityWarn)
+		c.rollback(cd, canaryController, meshRouter)
+		return false
+	case action == flaggerv1.CanaryActionRetry && phase == flaggerv1.CanaryPhaseFailed:
+		c.recordEventInfof(cd, "Action %s requested by %s, restarting analysis for %s.%s", action, actor, cd.Name, cd.Namespace)
+		c.alert(cd, fmt.Sprintf("Analysis restart requested by %s", actor), false, flaggerv1.SeverityInfo)
+		if err := canaryController.ScaleFromZero(cd); err != nil {
+			c.recordEventErrorf(cd, "%v", err)
+			return false
+		}
+		if err := canaryController.SyncStatus(cd, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return false
+		}
+		c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseProgressing)
+		return false
+	case action == flaggerv1.CanaryActionSkipStep && phase == flaggerv1.CanaryPhaseProgressing:
+		c.recordEventInfof(cd, "Action %s requested by %s, skipping the current step of %s.%s", action, actor, cd.Name, cd.Namespace)
+		c.skipStep(cd, canaryController, meshRouter)
+		return false
+	}
+
+	c.recordEventWarningf(cd, "Action %s requested by %s is not allowed for %s.%s in phase %s",
+		action, actor, cd.Name, cd.Namespace, phase)
+	return true
+}
+
+// skipStep advances the canary to the next iteration, step or weight depending on the analysis strategy
+// and promotes it if the current step is the last one
+func (c *Controller) skipStep(cd *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface) {
+	analysis := cd.GetAnalysis()
+	promote := false
+	switch {
+	case analysis.Iterations > 0:
+		if cd.Status.Iterations+1 >= analysis.Iterations {
+			promote = true
+			break
+		}
+		if err := canaryController.SetStatusIterations(cd, cd.Status.Iterations+1); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+		c.recordEventInfof(cd, "Advance %s.%s canary iteration %v/%v",
+			cd.Name, cd.Namespace, cd.Status.Iterations+1, analysis.Iterations)
+	case len(analysis.Steps) > 0:
+		next := 0
+		if cd.Status.CurrentStepIndex != nil {
+			next = *cd.Status.CurrentStepIndex + 1
+		}
+		if next >= len(analysis.Steps) {
+			promote = true
+			break
+		}
+		if ok := c.setCanaryStep(cd, canaryController, meshRouter, next); ok {
+			c.recordEventInfof(cd, "Advance %s.%s canary step %d/%d weight %v",
+				cd.Name, cd.Namespace, next+1, len(analysis.Steps), analysis.Steps[next].Weight)
+		}
+	default:
+		maxWeight := 100
+		if analysis.MaxWeight > 0 {
+			maxWeight = analysis.MaxWeight
+		}
+		canaryWeight := cd.Status.CanaryWeight + analysis.StepWeight
+		if canaryWeight >= maxWeight {
+			promote = true
+			break
+		}
+		primaryWeight := 100 - canaryWeight
+		if err := meshRouter.SetRoutes(cd, primaryWeight, canaryWeight, false); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+		if err := canaryController.SetStatusWeight(cd, canaryWeight); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return
+		}
+		c.recorder.SetWeight(cd, primaryWeight, canaryWeight)
+		c.recordEventInfof(cd, "Advance %s.%s canary weight %v", cd.Name, cd.Namespace, canaryWeight)
+	}
+
+	if promote {
+		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhasePromoting); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+	}
+}
+
+// clearAction removes the action annotations from the canary
+// and updates the canary metadata so that the following status updates don't restore them
+func (c *Controller) clearAction(cd *flaggerv1.Canary) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	current := cd
+	var updated *flaggerv1.Canary
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			current, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := current.DeepCopy()
+		delete(cdCopy.Annotations, flaggerv1.ActionAnnotation)
+		delete(cdCopy.Annotations, flaggerv1.ActionByAnnotation)
+		updated, err = c.flaggerClient.FlaggerV1beta1().Canaries(ns).Update(context.TODO(), cdCopy, metav1.UpdateOptions{})
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("removing the action annotations of canary %s.%s failed: %w", name, ns, err)
+	}
+	cd.ObjectMeta = updated.ObjectMeta
+	return nil
+}
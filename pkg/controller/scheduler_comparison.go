@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/internal"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// runMetricComparison runs the metric query for the canary and the primary workloads
+// and fails the check if the canary value deviates from the primary value by more than the tolerance
+func (c *Controller) runMetricComparison(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	query func(model flaggerv1.MetricTemplateModel) (float64, error)) flaggerv1.CanaryMetricStatus {
+	canaryModel, primaryModel := toComparisonMetricModels(canary, metric.Interval)
+
+	values := make([]float64, 0, 2)
+	for _, model := range []flaggerv1.MetricTemplateModel{canaryModel, primaryModel} {
+		val, err := query(model)
+		if err != nil {
+			if errors.Is(err, providers.ErrNoValuesFound) {
+				return c.metricNoData(canary, metric, "Halt advancement no values found for %s metric %s: %v",
+					model.Variant, metric.Name, err)
+			}
+			return c.metricError(canary, metric, "Metric query failed for %s %s: %v", model.Variant, metric.Name, err)
+		}
+		values = append(values, val)
+	}
+
+	comparison := metric.Comparison
+	deviation, ok := comparison.Compare(values[0], values[1])
+	if !ok {
+		return c.metricFailed(canary, metric, &values[0],
+			"Halt %s.%s advancement %s canary %.2f primary %.2f deviation %s exceeds tolerance %s",
+			canary.Name, canary.Namespace, metric.Name, values[0], values[1],
+			comparison.FormatDeviation(deviation), comparison.FormatDeviation(comparison.Tolerance))
+	}
+	c.recordEventInfof(canary, "Metric %s canary %.2f primary %.2f deviation %s within tolerance %s",
+		metric.Name, values[0], values[1], comparison.FormatDeviation(deviation), comparison.FormatDeviation(comparison.Tolerance))
+	return metricPassed(metric, values[0])
+}
+
+// toComparisonMetricModels returns the query models of the canary and the primary workloads
+func toComparisonMetricModels(r *flaggerv1.Canary, interval string) (flaggerv1.MetricTemplateModel, flaggerv1.MetricTemplateModel) {
+	_, primaryName, canaryName := r.GetServiceNames()
+
+	canaryModel := toMetricModel(r, interval)
+	canaryModel.Service = canaryName
+	canaryModel.Variant = flaggerv1.CanaryVariant
+
+	primaryModel := toMetricModel(r, interval)
+	primaryModel.Target = fmt.Sprintf("%s-primary", r.Spec.TargetRef.Name)
+	// the OAM canaries compare the target against the source workload
+	if internal.HasSourceTargetRef(r) {
+		primaryModel.Target = r.Spec.SourceRef.Name
+	}
+	primaryModel.Service = primaryName
+	primaryModel.Variant = flaggerv1.PrimaryVariant
+
+	return canaryModel, primaryModel
+}
@@ -73,6 +73,10 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
 		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
 		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		GroupInformer:  flaggerInformerFactory.Flagger().V1beta1().CanaryGroups(),
+
+		TemplateInformer:        flaggerInformerFactory.Flagger().V1beta1().AnalysisTemplates(),
+		ClusterTemplateInformer: flaggerInformerFactory.Flagger().V1beta1().ClusterAnalysisTemplates(),
 	}
 
 	// init router
@@ -98,6 +102,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		eventRecorder:    &record.FakeRecorder{},
 		logger:           logger,
 		canaries:         new(sync.Map),
+		analysisRecords:  new(sync.Map),
 		flaggerWindow:    time.Second,
 		canaryFactory:    canaryFactory,
 		observerFactory:  observerFactory,
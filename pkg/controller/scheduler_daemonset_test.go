@@ -20,7 +20,7 @@ import (
 
 func TestScheduler_DaemonSetInit(t *testing.T) {
 	mocks := newDaemonSetFixture(nil)
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	_, err := mocks.kubeClient.AppsV1().DaemonSets("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -28,7 +28,7 @@ func TestScheduler_DaemonSetInit(t *testing.T) {
 
 func TestScheduler_DaemonSetNewRevision(t *testing.T) {
 	mocks := newDaemonSetFixture(nil)
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// update
 	dae2 := newDaemonSetTestDaemonSetV2()
@@ -36,7 +36,7 @@ func TestScheduler_DaemonSetNewRevision(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	_, err = mocks.kubeClient.AppsV1().DaemonSets("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -45,7 +45,7 @@ func TestScheduler_DaemonSetNewRevision(t *testing.T) {
 func TestScheduler_DaemonSetRollback(t *testing.T) {
 	mocks := newDaemonSetFixture(nil)
 	// init
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// update failed checks to max
 	err := mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10})
@@ -69,10 +69,10 @@ func TestScheduler_DaemonSetRollback(t *testing.T) {
 	require.NoError(t, err)
 
 	// run metric checks
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// finalise analysis
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -83,7 +83,7 @@ func TestScheduler_DaemonSetRollback(t *testing.T) {
 func TestScheduler_DaemonSetSkipAnalysis(t *testing.T) {
 	mocks := newDaemonSetFixture(nil)
 	// init
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// enable skip
 	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -99,9 +99,9 @@ func TestScheduler_DaemonSetSkipAnalysis(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -112,7 +112,7 @@ func TestScheduler_DaemonSetSkipAnalysis(t *testing.T) {
 func TestScheduler_DaemonSetNewRevisionReset(t *testing.T) {
 	mocks := newDaemonSetFixture(nil)
 	// init
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// first update
 	dae2 := newDaemonSetTestDaemonSetV2()
@@ -120,9 +120,9 @@ func TestScheduler_DaemonSetNewRevisionReset(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -136,7 +136,7 @@ func TestScheduler_DaemonSetNewRevisionReset(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	primaryWeight, canaryWeight, mirrored, err = mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -149,7 +149,7 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 	mocks := newDaemonSetFixture(nil)
 
 	// init
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check initialized status
 	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -162,7 +162,7 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect pod spec changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	config2 := newDaemonSetTestConfigMapV2()
 	_, err = mocks.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(), config2, metav1.UpdateOptions{})
@@ -173,7 +173,7 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect configs changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	_, _, _, err = mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -185,7 +185,7 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 	require.NoError(t, err)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check progressing status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -193,7 +193,7 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
 
 	// promote
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check promoting status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -201,7 +201,7 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
 
 	// finalise
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	primaryWeight, canaryWeight, mirrored, err = mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -230,7 +230,7 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseFinalising, c.Status.Phase)
 
 	// scale canary to zero
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -240,7 +240,7 @@ func TestScheduler_DaemonSetPromotion(t *testing.T) {
 func TestScheduler_DaemonSetMirroring(t *testing.T) {
 	mocks := newDaemonSetFixture(newDaemonSetTestCanaryMirror())
 	// init
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// update
 	dae2 := newDaemonSetTestDaemonSetV2()
@@ -248,10 +248,10 @@ func TestScheduler_DaemonSetMirroring(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect pod spec changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check if traffic is mirrored to canary
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
@@ -261,7 +261,7 @@ func TestScheduler_DaemonSetMirroring(t *testing.T) {
 	assert.True(t, mirrored)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check if traffic is mirrored to canary
 	primaryWeight, canaryWeight, mirrored, err = mocks.router.GetRoutes(mocks.canary)
@@ -274,7 +274,7 @@ func TestScheduler_DaemonSetMirroring(t *testing.T) {
 func TestScheduler_DaemonSetABTesting(t *testing.T) {
 	mocks := newDaemonSetFixture(newDaemonSetTestCanaryAB())
 	// init
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// update
 	dae2 := newDaemonSetTestDaemonSetV2()
@@ -282,10 +282,10 @@ func TestScheduler_DaemonSetABTesting(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect pod spec changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check if traffic is routed to canary
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
@@ -302,10 +302,10 @@ func TestScheduler_DaemonSetABTesting(t *testing.T) {
 	require.NoError(t, err)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// finalising
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check finalising status
 	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -321,7 +321,7 @@ func TestScheduler_DaemonSetABTesting(t *testing.T) {
 	assert.Equal(t, canaryImage, primaryImage)
 
 	// shutdown canary
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check rollout status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -339,7 +339,7 @@ func TestScheduler_DaemonSetPortDiscovery(t *testing.T) {
 	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -372,7 +372,7 @@ func TestScheduler_DaemonSetTargetPortNumber(t *testing.T) {
 	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -405,7 +405,7 @@ func TestScheduler_DaemonSetTargetPortName(t *testing.T) {
 	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -466,5 +466,5 @@ func TestScheduler_DaemonSetAlerts(t *testing.T) {
 	require.NoError(t, err)
 
 	// init canary and send alerts
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 }
@@ -101,6 +101,10 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
 		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
 		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		GroupInformer:  flaggerInformerFactory.Flagger().V1beta1().CanaryGroups(),
+
+		TemplateInformer:        flaggerInformerFactory.Flagger().V1beta1().AnalysisTemplates(),
+		ClusterTemplateInformer: flaggerInformerFactory.Flagger().V1beta1().ClusterAnalysisTemplates(),
 	}
 
 	// init router
@@ -126,6 +130,7 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		eventRecorder:    &record.FakeRecorder{},
 		logger:           logger,
 		canaries:         new(sync.Map),
+		analysisRecords:  new(sync.Map),
 		flaggerWindow:    time.Second,
 		canaryFactory:    canaryFactory,
 		observerFactory:  observerFactory,
@@ -292,8 +297,8 @@ func newDeploymentTestCanary() *flaggerv1.Canary {
 					{
 						Name: "request-success-rate",
 						ThresholdRange: &flaggerv1.CanaryThresholdRange{
-							Min: toFloatPtr(0),
-							Max: toFloatPtr(99),
+							Min: toFloatPtr(99),
+							Max: toFloatPtr(100),
 						},
 						Interval: "1m",
 					},
@@ -8,9 +8,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -20,7 +22,7 @@ import (
 
 func TestScheduler_DeploymentInit(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -30,13 +32,13 @@ func TestScheduler_DeploymentNewRevision(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 
 	// initializing ...
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialization done
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// update
 	dep2 := newDeploymentTestDeploymentV2()
@@ -44,7 +46,7 @@ func TestScheduler_DeploymentNewRevision(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	c, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -54,13 +56,13 @@ func TestScheduler_DeploymentNewRevision(t *testing.T) {
 func TestScheduler_DeploymentRollback(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 	// initializing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialized
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// update failed checks to max
 	err := mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing, FailedChecks: 10})
@@ -83,10 +85,10 @@ func TestScheduler_DeploymentRollback(t *testing.T) {
 	require.NoError(t, err)
 
 	// run metric checks
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// finalise analysis
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -98,13 +100,13 @@ func TestScheduler_DeploymentRollback(t *testing.T) {
 func TestScheduler_DeploymentSkipAnalysis(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 	// initializing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialized
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// enable skip
 	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -119,11 +121,11 @@ func TestScheduler_DeploymentSkipAnalysis(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	mocks.makeCanaryReady(t)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -141,13 +143,13 @@ func TestScheduler_DeploymentAnalysisPhases(t *testing.T) {
 	mocks := newDeploymentFixture(cd)
 
 	// initializing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialized
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
 
 	// update
@@ -156,28 +158,28 @@ func TestScheduler_DeploymentAnalysisPhases(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
 	mocks.makeCanaryReady(t)
 
 	// progressing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
 
 	// start promotion
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
 
 	// end promotion
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
 
 	// finalising
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFinalising))
 
 	// succeeded
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseSucceeded))
 }
 
@@ -190,13 +192,13 @@ func TestScheduler_DeploymentBlueGreenAnalysisPhases(t *testing.T) {
 	mocks := newDeploymentFixture(cd)
 
 	// initializing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialized
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
 
 	// update
@@ -205,28 +207,28 @@ func TestScheduler_DeploymentBlueGreenAnalysisPhases(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes (progressing)
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
 	mocks.makeCanaryReady(t)
 
 	// advance (progressing)
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
 
 	// route traffic to primary (progressing)
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
 
 	// promoting
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
 
 	// finalising
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFinalising))
 
 	// succeeded
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseSucceeded))
 }
 
@@ -234,13 +236,13 @@ func TestScheduler_DeploymentNewRevisionReset(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 	// init
 	// initializing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialized
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// first update
 	dep2 := newDeploymentTestDeploymentV2()
@@ -248,11 +250,11 @@ func TestScheduler_DeploymentNewRevisionReset(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	mocks.makeCanaryReady(t)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -266,7 +268,7 @@ func TestScheduler_DeploymentNewRevisionReset(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	primaryWeight, canaryWeight, mirrored, err = mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -279,13 +281,13 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 
 	// initializing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialized
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check initialized status
 	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -298,7 +300,7 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect pod spec changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	mocks.makeCanaryReady(t)
 
 	config2 := newDeploymentTestConfigMapV2()
@@ -310,7 +312,7 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect configs changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	_, _, _, err = mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -321,7 +323,7 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	require.NoError(t, err)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check progressing status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -329,7 +331,7 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
 
 	// promote
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check promoting status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -337,7 +339,7 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
 
 	// finalise
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -366,7 +368,7 @@ func TestScheduler_DeploymentPromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseFinalising, c.Status.Phase)
 
 	// scale canary to zero
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -377,13 +379,13 @@ func TestScheduler_DeploymentMirroring(t *testing.T) {
 	mocks := newDeploymentFixture(newDeploymentTestCanaryMirror())
 
 	// initializing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialized
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// update
 	dep2 := newDeploymentTestDeploymentV2()
@@ -391,11 +393,11 @@ func TestScheduler_DeploymentMirroring(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect pod spec changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	mocks.makeCanaryReady(t)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check if traffic is mirrored to canary
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
@@ -405,7 +407,7 @@ func TestScheduler_DeploymentMirroring(t *testing.T) {
 	assert.True(t, mirrored)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check if traffic is mirrored to canary
 	primaryWeight, canaryWeight, mirrored, err = mocks.router.GetRoutes(mocks.canary)
@@ -418,13 +420,13 @@ func TestScheduler_DeploymentMirroring(t *testing.T) {
 func TestScheduler_DeploymentABTesting(t *testing.T) {
 	mocks := newDeploymentFixture(newDeploymentTestCanaryAB())
 	// initializing
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialized
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// update
 	dep2 := newDeploymentTestDeploymentV2()
@@ -432,11 +434,11 @@ func TestScheduler_DeploymentABTesting(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect pod spec changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 	mocks.makeCanaryReady(t)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check if traffic is routed to canary
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
@@ -453,10 +455,10 @@ func TestScheduler_DeploymentABTesting(t *testing.T) {
 	require.NoError(t, err)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// finalising
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check finalising status
 	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -472,7 +474,7 @@ func TestScheduler_DeploymentABTesting(t *testing.T) {
 	assert.Equal(t, canaryImage, primaryImage)
 
 	// shutdown canary
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check rollout status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -490,7 +492,7 @@ func TestScheduler_DeploymentPortDiscovery(t *testing.T) {
 	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -523,7 +525,7 @@ func TestScheduler_DeploymentTargetPortNumber(t *testing.T) {
 	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -556,7 +558,7 @@ func TestScheduler_DeploymentTargetPortName(t *testing.T) {
 	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), cd, metav1.UpdateOptions{})
 	require.NoError(t, err)
 
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -617,11 +619,486 @@ func TestScheduler_DeploymentAlerts(t *testing.T) {
 	require.NoError(t, err)
 
 	// init canary
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// make primary ready
 	mocks.makePrimaryReady(t)
 
 	// initialization done - now send alert
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+}
+
+func TestScheduler_DeploymentSteps(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.StepWeight = 0
+	cd.Spec.Analysis.Metrics[0].ThresholdRange = &flaggerv1.CanaryThresholdRange{
+		Min: toFloatPtr(99),
+		Max: toFloatPtr(100),
+	}
+	cd.Spec.Analysis.Steps = []flaggerv1.CanaryStep{
+		{Weight: 5},
+		{Weight: 20, Pause: "1h"},
+		{Weight: 50},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// first step
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, c.Status.CurrentStepIndex)
+	assert.Equal(t, 0, *c.Status.CurrentStepIndex)
+	assert.Equal(t, 5, c.Status.CanaryWeight)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 95, primaryWeight)
+	assert.Equal(t, 5, canaryWeight)
+
+	// second step
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, *c.Status.CurrentStepIndex)
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+
+	// pause at the second step
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, *c.Status.CurrentStepIndex)
+
+	// resume after the bake duration from the status recorded before a restart
+	stepStart := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	c.Status.CurrentStepStartTime = &stepStart
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, *c.Status.CurrentStepIndex)
+	assert.Equal(t, 50, c.Status.CanaryWeight)
+
+	primaryWeight, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 50, primaryWeight)
+	assert.Equal(t, 50, canaryWeight)
+
+	// all steps completed
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
+	assert.Nil(t, c.Status.CurrentStepIndex)
+}
+
+func TestScheduler_DeploymentSuspend(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Metrics[0].ThresholdRange = &flaggerv1.CanaryThresholdRange{
+		Min: toFloatPtr(99),
+		Max: toFloatPtr(100),
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// progressing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 10, c.Status.CanaryWeight)
+
+	// suspend
+	c.Spec.Suspend = true
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 10, c.Status.CanaryWeight)
+	assert.Equal(t, 0, c.Status.FailedChecks)
+	suspended := findCondition(c, flaggerv1.SuspendedType)
+	require.NotNil(t, suspended)
+	assert.Equal(t, corev1.ConditionTrue, suspended.Status)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, primaryWeight)
+	assert.Equal(t, 10, canaryWeight)
+
+	// resume
+	c.Spec.Suspend = false
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+	suspended = findCondition(c, flaggerv1.SuspendedType)
+	require.NotNil(t, suspended)
+	assert.Equal(t, corev1.ConditionFalse, suspended.Status)
+}
+
+func TestScheduler_DeploymentSchedule(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Metrics[0].ThresholdRange = &flaggerv1.CanaryThresholdRange{
+		Min: toFloatPtr(99),
+		Max: toFloatPtr(100),
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// progressing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// blackout for the whole week
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	c.Spec.Analysis.Schedule = &flaggerv1.CanarySchedule{
+		Blackout: []flaggerv1.CanaryTimeWindow{{}},
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseWaiting, c.Status.Phase)
+	assert.Equal(t, 10, c.Status.CanaryWeight)
+	progressing := findCondition(c, flaggerv1.ProgressingType)
+	require.NotNil(t, progressing)
+	assert.Equal(t, corev1.ConditionTrue, progressing.Status)
+	assert.Equal(t, flaggerv1.ConditionReasonReleaseWindowClosed, progressing.Reason)
+	assert.Equal(t, corev1.ConditionFalse, findCondition(c, flaggerv1.AwaitingApprovalType).Status)
+
+	// window opens
+	c.Spec.Analysis.Schedule = &flaggerv1.CanarySchedule{
+		Allowed: []flaggerv1.CanaryTimeWindow{{}},
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+
+	// rollback outside of the windows
+	c.Spec.Analysis.Schedule = &flaggerv1.CanarySchedule{
+		Blackout: []flaggerv1.CanaryTimeWindow{{}},
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, flaggerv1.CanaryPhaseWaiting, c.Status.Phase)
+	c.Status.FailedChecks = 10
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").UpdateStatus(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.Equal(t, corev1.ConditionTrue, findCondition(c, flaggerv1.RolledBackType).Status)
+	assert.Equal(t, corev1.ConditionFalse, findCondition(c, flaggerv1.StalledType).Status, "the failed checks do not stall the canary")
+	assert.Equal(t, corev1.ConditionFalse, findCondition(c, flaggerv1.ProgressingType).Status)
+}
+
+func TestScheduler_DeploymentSchedulePromotion(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// analysis succeeded outside of the windows
+	err = mocks.router.SetRoutes(mocks.canary, 60, 40, false)
+	require.NoError(t, err)
+	err = mocks.deployer.SyncStatus(mocks.canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhasePromoting, CanaryWeight: 40})
+	require.NoError(t, err)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	c.Spec.Analysis.Schedule = &flaggerv1.CanarySchedule{
+		Blackout: []flaggerv1.CanaryTimeWindow{{}},
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, primaryWeight)
+	assert.Equal(t, 40, canaryWeight)
+
+	primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, dep2.Spec.Template.Spec.Containers[0].Image, primaryDep.Spec.Template.Spec.Containers[0].Image)
+
+	// window opens
+	c.Spec.Analysis.Schedule = &flaggerv1.CanarySchedule{
+		Allowed: []flaggerv1.CanaryTimeWindow{{}},
+	}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFinalising, c.Status.Phase)
+
+	primaryWeight, canaryWeight, _, err = mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+
+	primaryDep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dep2.Spec.Template.Spec.Containers[0].Image, primaryDep.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestScheduler_DeploymentActions(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Metrics[0].ThresholdRange = &flaggerv1.CanaryThresholdRange{
+		Min: toFloatPtr(99),
+		Max: toFloatPtr(100),
+	}
+	mocks := newDeploymentFixture(cd)
+
+	requestAction := func(action flaggerv1.CanaryAction) {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		c.Annotations = map[string]string{
+			flaggerv1.ActionAnnotation:   string(action),
+			flaggerv1.ActionByAnnotation: "admin",
+		}
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	}
+
+	// initializing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// progressing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// skip to the next weight
+	requestAction(flaggerv1.CanaryActionSkipStep)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 20, c.Status.CanaryWeight)
+	assert.NotContains(t, c.Annotations, flaggerv1.ActionAnnotation)
+	assert.NotContains(t, c.Annotations, flaggerv1.ActionByAnnotation)
+
+	// retry is only allowed for failed canaries
+	requestAction(flaggerv1.CanaryActionRetry)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 30, c.Status.CanaryWeight)
+	assert.NotContains(t, c.Annotations, flaggerv1.ActionAnnotation)
+
+	// abort
+	requestAction(flaggerv1.CanaryActionAbort)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.Equal(t, 0, c.Status.CanaryWeight)
+
+	primaryWeight, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, primaryWeight)
+	assert.Equal(t, 0, canaryWeight)
+
+	// retry
+	requestAction(flaggerv1.CanaryActionRetry)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+
+	// promote
+	requestAction(flaggerv1.CanaryActionPromote)
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
+
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dep2.Spec.Template.Spec.Containers[0].Image, primary.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestScheduler_DeploymentAnalysisHistory(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Metrics[0].ThresholdRange = &flaggerv1.CanaryThresholdRange{
+		Min: toFloatPtr(99),
+		Max: toFloatPtr(100),
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect pod spec changes
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, c.Status.StartTime)
+
+	// progressing
+	for i := 0; i < 3; i++ {
+		mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+	}
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, c.Status.AnalysisHistory)
+	last := c.Status.AnalysisHistory[len(c.Status.AnalysisHistory)-1]
+	require.NotNil(t, last.Metrics)
+	assert.True(t, last.Metrics.Passed)
+	assert.Equal(t, "request-success-rate", last.Metrics.Metrics[0].Name)
+	assert.Equal(t, float64(100), *last.Metrics.Metrics[0].Value)
+	assert.Equal(t, c.Status.Analysis, last.Metrics)
+
+	// abort
+	c.Annotations = map[string]string{flaggerv1.ActionAnnotation: string(flaggerv1.CanaryActionAbort)}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.Status.Runs, 1)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Runs[0].Phase)
+	assert.Equal(t, c.Status.StartTime, c.Status.Runs[0].StartTime)
+	assert.NotNil(t, c.Status.Runs[0].Duration)
 }
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/judge"
+	"github.com/weaveworks/flagger/pkg/metrics/observers"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// isJudgedMetric returns true if the time series of the metric are compared by the analysis judge
+func isJudgedMetric(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) bool {
+	return canary.GetAnalysis().Judge != nil && metric.Comparison != nil && metric.TemplateRef != nil
+}
+
+// runJudge compares the canary and primary time series of the judged metrics over the metric interval,
+// the analysis passes if the weighted score reaches the pass score and is held if it reaches the marginal score.
+// A failed critical metric fails the analysis regardless of the score, the metrics without enough values
+// or with a query error are handled by their onNoData and onError policies like the threshold metrics.
+func (c *Controller) runJudge(ctx context.Context, canary *flaggerv1.Canary) analysisResult {
+	spec := canary.GetAnalysis().Judge
+	if spec == nil {
+		return analysisPassed
+	}
+
+	var checks []func() flaggerv1.CanaryMetricStatus
+	var verdicts []judge.Verdict
+	for _, metric := range canary.GetAnalysis().Metrics {
+		if !isJudgedMetric(canary, metric) {
+			continue
+		}
+		if metric.Interval == "" {
+			metric.Interval = canary.GetMetricInterval()
+		}
+		metric := metric
+		i := len(verdicts)
+		verdicts = append(verdicts, "")
+		checks = append(checks, func() flaggerv1.CanaryMetricStatus {
+			metricCtx, cancel := metricContext(ctx, metric)
+			defer cancel()
+			verdict, status := c.runJudgeCheck(metricCtx, canary, metric, spec)
+			verdicts[i] = verdict
+			return status
+		})
+	}
+	if len(checks) == 0 {
+		return analysisPassed
+	}
+	statuses := runConcurrently(checks)
+
+	// the held metrics are not scored
+	var scored []judge.Verdict
+	var weights []float64
+	var held []string
+	failed := false
+	for i, status := range statuses {
+		if status.Held {
+			held = append(held, status.Name)
+			continue
+		}
+		scored = append(scored, verdicts[i])
+		weights = append(weights, status.Weight)
+		if verdicts[i] == judge.Fail && status.Critical {
+			failed = true
+			c.recordEventWarningf(canary, "Halt %s.%s advancement critical metric %s failed",
+				canary.Name, canary.Namespace, status.Name)
+		}
+	}
+
+	score := judge.Score(scored, weights)
+	c.analysisRecord(canary).Judge = &flaggerv1.CanaryJudgeStatus{Score: score, Metrics: statuses}
+	switch {
+	case failed:
+		return analysisFailed
+	case score < spec.GetMarginalScore():
+		c.recordEventWarningf(canary, "Halt %s.%s advancement judge score %.2f < %v",
+			canary.Name, canary.Namespace, score, spec.GetMarginalScore())
+		return analysisFailed
+	case score < spec.GetPassScore():
+		c.recordEventWarningf(canary, "Halt %s.%s advancement judge score %.2f is marginal < %v",
+			canary.Name, canary.Namespace, score, spec.GetPassScore())
+		return analysisHeld
+	case len(held) > 0:
+		c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for judged metrics: %s",
+			canary.Name, canary.Namespace, strings.Join(held, ", "))
+		return analysisHeld
+	default:
+		c.recordEventInfof(canary, "Judge score %.2f of %s.%s reached the pass score %v",
+			score, canary.Name, canary.Namespace, spec.GetPassScore())
+		return analysisPassed
+	}
+}
+
+// runJudgeCheck judges the metric and applies its policies when the time series can't be compared,
+// the advancement is held by default when a series has not enough values
+func (c *Controller) runJudgeCheck(ctx context.Context, canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	spec *flaggerv1.CanaryJudge) (judge.Verdict, flaggerv1.CanaryMetricStatus) {
+	verdict, status, err := c.judgeMetric(ctx, canary, metric, spec)
+	if err == nil {
+		return verdict, status
+	}
+
+	if errors.Is(err, providers.ErrNoValuesFound) || errors.Is(err, judge.ErrNotEnoughSamples) {
+		if metric.OnNoData == nil {
+			metric.OnNoData = &flaggerv1.CanaryMetricPolicy{Action: flaggerv1.MetricPolicyHold}
+		}
+		status = c.metricNoData(canary, metric, "Halt advancement not enough values found for metric %s: %v", metric.Name, err)
+	} else {
+		status = c.metricError(canary, metric, "Metric judge failed for %s: %v", metric.Name, err)
+	}
+	if status.Passed {
+		return judge.Pass, status
+	}
+	return judge.Fail, status
+}
+
+// judgeMetric fetches the canary and primary time series of the metric and classifies the canary
+func (c *Controller) judgeMetric(ctx context.Context, canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	spec *flaggerv1.CanaryJudge) (judge.Verdict, flaggerv1.CanaryMetricStatus, error) {
+	var status flaggerv1.CanaryMetricStatus
+	template, provider, err := c.metricTemplateProvider(ctx, canary, metric)
+	if err != nil {
+		return "", status, err
+	}
+	rangeProvider, ok := provider.(providers.RangeInterface)
+	if !ok {
+		return "", status, fmt.Errorf("metric template %s.%s provider %s does not support range queries",
+			template.Name, template.Namespace, template.Spec.Provider.Type)
+	}
+
+	interval, err := time.ParseDuration(metric.Interval)
+	if err != nil {
+		return "", status, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+	end := time.Now()
+	start := end.Add(-interval)
+
+	canaryModel, primaryModel := toComparisonMetricModels(canary, metric.Interval)
+	series := make([][]float64, 0, 2)
+	for _, model := range []flaggerv1.MetricTemplateModel{canaryModel, primaryModel} {
+		query, err := observers.RenderQuery(template.Spec.Query, model)
+		if err != nil {
+			return "", status, fmt.Errorf("metric template %s.%s query render error: %w", template.Name, template.Namespace, err)
+		}
+		values, err := rangeProvider.RunRangeQuery(ctx, query, start, end, spec.GetStep())
+		if err != nil {
+			return "", status, fmt.Errorf("%s range query failed: %w", model.Variant, err)
+		}
+		series = append(series, values)
+	}
+
+	result, err := judge.Metric(series[0], series[1], *metric.Comparison, spec.GetConfidence())
+	if err != nil {
+		return "", status, err
+	}
+	msg := fmt.Sprintf("Metric %s is %s canary median %.2f primary median %.2f deviation %s p-value %.3f",
+		metric.Name, result.Verdict, result.CanaryMedian, result.PrimaryMedian,
+		metric.Comparison.FormatDeviation(result.Deviation), result.PValue)
+	c.recordEventInfof(canary, "%s", msg)
+	status = flaggerv1.CanaryMetricStatus{
+		Name:     metric.Name,
+		Value:    &result.CanaryMedian,
+		Passed:   result.Verdict != judge.Fail,
+		Weight:   metric.GetWeight(),
+		Critical: metric.Critical,
+		Message:  msg,
+	}
+	return result.Verdict, status, nil
+}
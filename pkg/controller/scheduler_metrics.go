@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,55 +17,36 @@ import (
 
 const (
 	MetricsProviderServiceSuffix = ":service"
+
+	// metricChecksConcurrency is the maximum number of metric checks of a canary evaluated in parallel
+	metricChecksConcurrency = 5
 )
 
 // to be called during canary initialization
-func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) error {
+func (c *Controller) checkMetricProviderAvailability(ctx context.Context, canary *flaggerv1.Canary) error {
 	for _, metric := range canary.GetAnalysis().Metrics {
 		if metric.Name == "request-success-rate" || metric.Name == "request-duration" {
 			observerFactory := c.observerFactory
 			if canary.Spec.MetricsServer != "" {
 				var err error
-				observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer)
+				observerFactory, err = c.metricCache.observerFactory(canary.Spec.MetricsServer)
 				if err != nil {
 					return fmt.Errorf("error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
 				}
 			}
-			if ok, err := observerFactory.Client.IsOnline(); !ok || err != nil {
+			if ok, err := providers.IsOnline(ctx, observerFactory.Client); !ok || err != nil {
 				return fmt.Errorf("prometheus not avaiable: %v", err)
 			}
 			continue
 		}
 
 		if metric.TemplateRef != nil {
-			namespace := canary.Namespace
-			if metric.TemplateRef.Namespace != "" {
-				namespace = metric.TemplateRef.Namespace
-			}
-
-			template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
-			if err != nil {
-				return fmt.Errorf("metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
-			}
-
-			var credentials map[string][]byte
-			if template.Spec.Provider.SecretRef != nil {
-				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
-				if err != nil {
-					return fmt.Errorf("metric template %s.%s secret %s error: %v",
-						metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
-				}
-				credentials = secret.Data
-			}
-
-			factory := providers.Factory{}
-			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
+			template, provider, err := c.metricTemplateProvider(ctx, canary, metric)
 			if err != nil {
-				return fmt.Errorf("metric template %s.%s provider %s error: %v",
-					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
+				return err
 			}
 
-			if ok, err := provider.IsOnline(); !ok || err != nil {
+			if ok, err := providers.IsOnline(ctx, provider); !ok || err != nil {
 				return fmt.Errorf("%v in metric tempalte %s.%s not avaiable: %v", template.Spec.Provider.Type,
 					template.Name, template.Namespace, err)
 			}
@@ -74,9 +56,11 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 	return nil
 }
 
-func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
+// runBuiltinMetricChecks runs the builtin and the inline query metric checks and returns their outcome
+func (c *Controller) runBuiltinMetricChecks(ctx context.Context, canary *flaggerv1.Canary) []flaggerv1.CanaryMetricStatus {
+	var results []flaggerv1.CanaryMetricStatus
 	if len(canary.GetAnalysis().Metrics) == 0 {
-		return true
+		return results
 	}
 
 	// override the global provider if one is specified in the canary spec
@@ -108,189 +92,430 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 	// override the global metrics server if one is specified in the canary spec
 	if canary.Spec.MetricsServer != "" {
 		var err error
-		observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer)
+		observerFactory, err = c.metricCache.observerFactory(canary.Spec.MetricsServer)
 		if err != nil {
-			c.recordEventErrorf(canary, "Error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
-			return false
+			for _, metric := range canary.GetAnalysis().Metrics {
+				if isBuiltinMetric(metric) {
+					results = append(results, c.metricError(canary, metric,
+						"Error building Prometheus client for %s %v", canary.Spec.MetricsServer, err))
+				}
+			}
+			return results
 		}
 	}
+	// share the queries with the canaries using the same metrics server
+	if c.metricCache != nil {
+		observerFactory = &observers.Factory{Client: &cachingProvider{
+			key:      "metrics-server/" + canary.Spec.MetricsServer,
+			provider: observerFactory.Client,
+			cache:    c.metricCache,
+		}}
+	}
 	observer := observerFactory.Observer(metricsProvider)
 
 	// run metrics checks
+	var checks []func() flaggerv1.CanaryMetricStatus
 	for _, metric := range canary.GetAnalysis().Metrics {
+		if !isBuiltinMetric(metric) {
+			continue
+		}
 		if metric.Interval == "" {
 			metric.Interval = canary.GetMetricInterval()
 		}
+		metric := metric
+		checks = append(checks, func() flaggerv1.CanaryMetricStatus {
+			metricCtx, cancel := metricContext(ctx, metric)
+			defer cancel()
+			return c.runBuiltinMetricCheck(metricCtx, canary, metric, metricsProvider, observerFactory, observer)
+		})
+	}
 
-		if metric.Name == "request-success-rate" {
-			val, err := observer.GetRequestSuccessRate(toMetricModel(canary, metric.Interval))
-			if err != nil {
-				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary,
-						"Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic: %v",
-						metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace, err)
-				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed: %v", err)
-				}
-				return false
+	return runConcurrently(checks)
+}
+
+func (c *Controller) runBuiltinMetricCheck(ctx context.Context, canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	metricsProvider string, observerFactory *observers.Factory, observer observers.ContextInterface) flaggerv1.CanaryMetricStatus {
+	// compare the canary to the primary
+	if metric.Comparison != nil && (metric.Name == "request-success-rate" || metric.Name == "request-duration") {
+		query := func(model flaggerv1.MetricTemplateModel) (float64, error) {
+			return observer.GetRequestSuccessRateContext(ctx, model)
+		}
+		if metric.Name == "request-duration" {
+			query = func(model flaggerv1.MetricTemplateModel) (float64, error) {
+				val, err := observer.GetRequestDurationContext(ctx, model)
+				return float64(val) / float64(time.Millisecond), err
 			}
+		}
+		return c.runMetricComparison(canary, metric, query)
+	}
 
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
-						canary.Name, canary.Namespace, val, *tr.Min)
-					return false
-				}
-				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% > %v%%",
-						canary.Name, canary.Namespace, val, *tr.Max)
-					return false
-				}
-			} else if metric.Threshold > val {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
-					canary.Name, canary.Namespace, val, metric.Threshold)
-				return false
+	if metric.Name == "request-success-rate" {
+		val, err := observer.GetRequestSuccessRateContext(ctx, toMetricModel(canary, metric.Interval))
+		if err != nil {
+			if errors.Is(err, providers.ErrNoValuesFound) {
+				return c.metricNoData(canary, metric,
+					"Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic: %v",
+					metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace, err)
 			}
+			return c.metricError(canary, metric, "Prometheus query failed: %v", err)
 		}
 
-		if metric.Name == "request-duration" {
-			val, err := observer.GetRequestDuration(toMetricModel(canary, metric.Interval))
-			if err != nil {
-				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
-						metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace)
-				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed: %v", err)
-				}
-				return false
+		if metric.ThresholdRange != nil {
+			tr := *metric.ThresholdRange
+			if tr.Min != nil && val < *tr.Min {
+				return c.metricFailed(canary, metric, &val, "Halt %s.%s advancement success rate %.2f%% < %v%%",
+					canary.Name, canary.Namespace, val, *tr.Min)
 			}
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v < %v",
-						canary.Name, canary.Namespace, val, time.Duration(*tr.Min)*time.Millisecond)
-					return false
-				}
-				if tr.Max != nil && val > time.Duration(*tr.Max)*time.Millisecond {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
-						canary.Name, canary.Namespace, val, time.Duration(*tr.Max)*time.Millisecond)
-					return false
-				}
-			} else if val > time.Duration(metric.Threshold)*time.Millisecond {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
-					canary.Name, canary.Namespace, val, time.Duration(metric.Threshold)*time.Millisecond)
-				return false
+			if tr.Max != nil && val > *tr.Max {
+				return c.metricFailed(canary, metric, &val, "Halt %s.%s advancement success rate %.2f%% > %v%%",
+					canary.Name, canary.Namespace, val, *tr.Max)
 			}
+		} else if metric.Threshold > val {
+			return c.metricFailed(canary, metric, &val, "Halt %s.%s advancement success rate %.2f%% < %v%%",
+				canary.Name, canary.Namespace, val, metric.Threshold)
 		}
+		return metricPassed(metric, val)
+	}
 
-		// in-line PromQL
-		if metric.Query != "" {
-			val, err := observerFactory.Client.RunQuery(metric.Query)
-			if err != nil {
-				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for metric: %s",
-						metric.Name)
-				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed for %s: %v", metric.Name, err)
-				}
-				return false
+	if metric.Name == "request-duration" {
+		val, err := observer.GetRequestDurationContext(ctx, toMetricModel(canary, metric.Interval))
+		if err != nil {
+			if errors.Is(err, providers.ErrNoValuesFound) {
+				return c.metricNoData(canary, metric,
+					"Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
+					metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace)
 			}
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
-					return false
-				}
-				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
-					return false
-				}
-			} else if val > metric.Threshold {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
-				return false
+			return c.metricError(canary, metric, "Prometheus query failed: %v", err)
+		}
+		ms := float64(val) / float64(time.Millisecond)
+		if metric.ThresholdRange != nil {
+			tr := *metric.ThresholdRange
+			if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
+				return c.metricFailed(canary, metric, &ms, "Halt %s.%s advancement request duration %v < %v",
+					canary.Name, canary.Namespace, val, time.Duration(*tr.Min)*time.Millisecond)
+			}
+			if tr.Max != nil && val > time.Duration(*tr.Max)*time.Millisecond {
+				return c.metricFailed(canary, metric, &ms, "Halt %s.%s advancement request duration %v > %v",
+					canary.Name, canary.Namespace, val, time.Duration(*tr.Max)*time.Millisecond)
 			}
+		} else if val > time.Duration(metric.Threshold)*time.Millisecond {
+			return c.metricFailed(canary, metric, &ms, "Halt %s.%s advancement request duration %v > %v",
+				canary.Name, canary.Namespace, val, time.Duration(metric.Threshold)*time.Millisecond)
 		}
+		return metricPassed(metric, ms)
 	}
 
-	return true
+	// in-line PromQL
+	val, err := providers.RunQuery(ctx, observerFactory.Client, metric.Query)
+	if err != nil {
+		if errors.Is(err, providers.ErrNoValuesFound) {
+			return c.metricNoData(canary, metric, "Halt advancement no values found for metric: %s",
+				metric.Name)
+		}
+		return c.metricError(canary, metric, "Prometheus query failed for %s: %v", metric.Name, err)
+	}
+	return c.checkMetricThreshold(canary, metric, val)
 }
 
-func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
+// runMetricChecks runs the metric template checks, except the judged ones, and returns their outcome
+func (c *Controller) runMetricChecks(ctx context.Context, canary *flaggerv1.Canary) []flaggerv1.CanaryMetricStatus {
+	var checks []func() flaggerv1.CanaryMetricStatus
 	for _, metric := range canary.GetAnalysis().Metrics {
-		if metric.TemplateRef != nil {
-			namespace := canary.Namespace
-			if metric.TemplateRef.Namespace != "" {
-				namespace = metric.TemplateRef.Namespace
-			}
+		// the time series of the judged metrics are compared by runJudge
+		if metric.TemplateRef == nil || isJudgedMetric(canary, metric) {
+			continue
+		}
+		metric := metric
+		checks = append(checks, func() flaggerv1.CanaryMetricStatus {
+			metricCtx, cancel := metricContext(ctx, metric)
+			defer cancel()
+			return c.runMetricCheck(metricCtx, canary, metric)
+		})
+	}
 
-			template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
-				return false
-			}
+	return runConcurrently(checks)
+}
 
-			var credentials map[string][]byte
-			if template.Spec.Provider.SecretRef != nil {
-				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
-				if err != nil {
-					c.recordEventErrorf(canary, "Metric template %s.%s secret %s error: %v",
-						metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
-					return false
-				}
-				credentials = secret.Data
-			}
+// runConcurrently evaluates the metric checks with at most metricChecksConcurrency checks in flight
+// and returns their outcome in the order of the checks
+func runConcurrently(checks []func() flaggerv1.CanaryMetricStatus) []flaggerv1.CanaryMetricStatus {
+	if len(checks) == 0 {
+		return nil
+	}
 
-			factory := providers.Factory{}
-			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials)
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s provider %s error: %v",
-					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
-				return false
-			}
+	results := make([]flaggerv1.CanaryMetricStatus, len(checks))
+	sem := make(chan struct{}, metricChecksConcurrency)
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, check func() flaggerv1.CanaryMetricStatus) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = check()
+		}(i, check)
+	}
+	wg.Wait()
 
-			query, err := observers.RenderQuery(template.Spec.Query, toMetricModel(canary, metric.Interval))
-			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s query render error: %v",
-					metric.TemplateRef.Name, namespace, err)
-				return false
-			}
+	return results
+}
 
-			val, err := provider.RunQuery(query)
+func (c *Controller) runMetricCheck(ctx context.Context, canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) flaggerv1.CanaryMetricStatus {
+	template, provider, err := c.metricTemplateProvider(ctx, canary, metric)
+	if err != nil {
+		return c.metricError(canary, metric, "%v", err)
+	}
+	namespace := template.Namespace
+
+	// compare the canary to the primary
+	if metric.Comparison != nil {
+		query := func(model flaggerv1.MetricTemplateModel) (float64, error) {
+			query, err := observers.RenderQuery(template.Spec.Query, model)
 			if err != nil {
-				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for custom metric: %s: %v",
-						metric.Name, err)
-				} else {
-					c.recordEventErrorf(canary, "Metric query failed for %s: %v", metric.Name, err)
-				}
-				return false
+				return 0, fmt.Errorf("metric template %s.%s query render error: %w", metric.TemplateRef.Name, namespace, err)
 			}
+			return c.runTemplateQuery(ctx, template, metric, provider, query, model)
+		}
+		return c.runMetricComparison(canary, metric, query)
+	}
 
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
-					return false
-				}
-				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
-					return false
+	model := toMetricModel(canary, metric.Interval)
+	query, err := observers.RenderQuery(template.Spec.Query, model)
+	if err != nil {
+		return c.metricError(canary, metric, "Metric template %s.%s query render error: %v",
+			metric.TemplateRef.Name, namespace, err)
+	}
+
+	val, err := c.runTemplateQuery(ctx, template, metric, provider, query, model)
+	if err != nil {
+		if errors.Is(err, providers.ErrNoValuesFound) {
+			return c.metricNoData(canary, metric, "Halt advancement no values found for custom metric: %s: %v",
+				metric.Name, err)
+		}
+		return c.metricError(canary, metric, "Metric query failed for %s: %v", metric.Name, err)
+	}
+
+	return c.checkMetricThreshold(canary, metric, val)
+}
+
+// runTemplateQuery executes the rendered query, the providers with a templated request
+// render it with the model, the identical queries of the canaries are executed once per control loop interval
+func (c *Controller) runTemplateQuery(ctx context.Context, template *flaggerv1.MetricTemplate, metric flaggerv1.CanaryMetric,
+	provider providers.Interface, query string, model flaggerv1.MetricTemplateModel) (float64, error) {
+	key := fmt.Sprintf("template/%s/%s/%s/%s\n%s", template.Namespace, template.Name, template.ResourceVersion, metric.Interval, query)
+	if p, ok := provider.(providers.ModelInterface); ok {
+		return c.metricCache.query(ctx, fmt.Sprintf("%s\n%+v", key, model), func(ctx context.Context) (float64, error) {
+			return p.RunModelQuery(ctx, query, model)
+		})
+	}
+	return c.metricCache.query(ctx, key, func(ctx context.Context) (float64, error) {
+		return providers.RunQuery(ctx, provider, query)
+	})
+}
+
+// metricContext returns the context of the metric queries, bounded by the metric timeout if set
+func metricContext(ctx context.Context, metric flaggerv1.CanaryMetric) (context.Context, context.CancelFunc) {
+	if timeout := metric.GetTimeout(); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// checkMetricThreshold checks the value against the threshold range or the deprecated threshold
+func (c *Controller) checkMetricThreshold(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric, val float64) flaggerv1.CanaryMetricStatus {
+	if metric.ThresholdRange != nil {
+		tr := *metric.ThresholdRange
+		if tr.Min != nil && val < *tr.Min {
+			return c.metricFailed(canary, metric, &val, "Halt %s.%s advancement %s %.2f < %v",
+				canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
+		}
+		if tr.Max != nil && val > *tr.Max {
+			return c.metricFailed(canary, metric, &val, "Halt %s.%s advancement %s %.2f > %v",
+				canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
+		}
+	} else if val > metric.Threshold {
+		return c.metricFailed(canary, metric, &val, "Halt %s.%s advancement %s %.2f > %v",
+			canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
+	}
+	return metricPassed(metric, val)
+}
+
+// scoreMetrics returns the outcome of the metric checks,
+// they fail if a critical metric failed or if the weight of the passed metrics is below the pass score
+// and they hold the advancement if a metric is held by its policy
+func (c *Controller) scoreMetrics(canary *flaggerv1.Canary, results []flaggerv1.CanaryMetricStatus) (flaggerv1.CanaryAnalysisStatus, analysisResult) {
+	status := flaggerv1.CanaryAnalysisStatus{
+		Score:       100,
+		Passed:      true,
+		Metrics:     results,
+		LastRunTime: metav1.Now(),
+	}
+
+	var total, passed float64
+	var failed, held []string
+	for _, result := range results {
+		// the held metrics are not scored
+		if result.Held {
+			held = append(held, result.Name)
+			continue
+		}
+		total += result.Weight
+		if result.Passed {
+			passed += result.Weight
+			continue
+		}
+		failed = append(failed, result.Name)
+		if result.Critical {
+			status.Passed = false
+			c.recordEventWarningf(canary, "Halt %s.%s advancement critical metric %s failed",
+				canary.Name, canary.Namespace, result.Name)
+		}
+	}
+	if total > 0 {
+		status.Score = passed / total * 100
+	}
+
+	passScore := canary.GetAnalysis().GetPassScore()
+	if status.Score < passScore {
+		status.Passed = false
+		c.recordEventWarningf(canary, "Halt %s.%s advancement metric score %.2f < %v",
+			canary.Name, canary.Namespace, status.Score, passScore)
+	}
+	if !status.Passed {
+		return status, analysisFailed
+	}
+
+	if len(held) > 0 {
+		status.Passed = false
+		status.Held = true
+		c.recordEventWarningf(canary, "Halt %s.%s advancement waiting for metrics: %s",
+			canary.Name, canary.Namespace, strings.Join(held, ", "))
+		return status, analysisHeld
+	}
+
+	if len(failed) > 0 {
+		c.recordEventInfof(canary, "Metric score %.2f of %s.%s reached the pass score %v, failed metrics: %s",
+			status.Score, canary.Name, canary.Namespace, passScore, strings.Join(failed, ", "))
+	}
+	return status, analysisPassed
+}
+
+// metricFailed records the failure of the metric check as a warning event
+func (c *Controller) metricFailed(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric, val *float64,
+	format string, a ...interface{}) flaggerv1.CanaryMetricStatus {
+	msg := fmt.Sprintf(format, a...)
+	c.recordEventWarningf(canary, "%s", msg)
+	return flaggerv1.CanaryMetricStatus{
+		Name:     metric.Name,
+		Value:    val,
+		Weight:   metric.GetWeight(),
+		Critical: metric.Critical,
+		Message:  msg,
+	}
+}
+
+// metricError records the failure of the metric query as an error event and applies the error policy
+func (c *Controller) metricError(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	format string, a ...interface{}) flaggerv1.CanaryMetricStatus {
+	msg := fmt.Sprintf(format, a...)
+	c.recordEventErrorf(canary, "%s", msg)
+	return c.applyMetricPolicy(canary, metric, metric.OnError, msg)
+}
+
+// metricNoData records the missing values of the metric as a warning event and applies the no-data policy
+func (c *Controller) metricNoData(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	format string, a ...interface{}) flaggerv1.CanaryMetricStatus {
+	msg := fmt.Sprintf(format, a...)
+	c.recordEventWarningf(canary, "%s", msg)
+	return c.applyMetricPolicy(canary, metric, metric.OnNoData, msg)
+}
+
+// applyMetricPolicy returns the outcome of a metric check without a value,
+// the retry policy holds the metric until the consecutive runs without a value exceed the retries
+func (c *Controller) applyMetricPolicy(canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	policy *flaggerv1.CanaryMetricPolicy, msg string) flaggerv1.CanaryMetricStatus {
+	status := flaggerv1.CanaryMetricStatus{
+		Name:     metric.Name,
+		Weight:   metric.GetWeight(),
+		Critical: metric.Critical,
+		Message:  msg,
+	}
+
+	switch policy.GetAction() {
+	case flaggerv1.MetricPolicyPass:
+		status.Passed = true
+	case flaggerv1.MetricPolicyHold:
+		status.Held = true
+	case flaggerv1.MetricPolicyRetry:
+		retries := 1
+		if canary.Status.Analysis != nil {
+			for _, previous := range canary.Status.Analysis.Metrics {
+				if previous.Name == metric.Name {
+					retries = previous.Retries + 1
 				}
-			} else if val > metric.Threshold {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
-				return false
 			}
 		}
+		if retries <= policy.Retries {
+			status.Held = true
+			status.Retries = retries
+			c.recordEventInfof(canary, "Retrying metric %s %d/%d", metric.Name, retries, policy.Retries)
+		}
+	}
+	return status
+}
+
+func metricPassed(metric flaggerv1.CanaryMetric, val float64) flaggerv1.CanaryMetricStatus {
+	return flaggerv1.CanaryMetricStatus{
+		Name:     metric.Name,
+		Value:    &val,
+		Passed:   true,
+		Weight:   metric.GetWeight(),
+		Critical: metric.Critical,
+	}
+}
+
+// isBuiltinMetric returns true if the metric is checked by runBuiltinMetricChecks
+func isBuiltinMetric(metric flaggerv1.CanaryMetric) bool {
+	return metric.Name == "request-success-rate" || metric.Name == "request-duration" || metric.Query != ""
+}
+
+// metricTemplateProvider returns the metric template referenced by the metric and its provider,
+// the provider is rebuilt only when the template or its secret changed
+func (c *Controller) metricTemplateProvider(ctx context.Context, canary *flaggerv1.Canary, metric flaggerv1.CanaryMetric) (*flaggerv1.MetricTemplate, providers.Interface, error) {
+	namespace := canary.Namespace
+	if metric.TemplateRef.Namespace != "" {
+		namespace = metric.TemplateRef.Namespace
+	}
+
+	template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
+	}
+
+	var credentials map[string][]byte
+	var secretVersion string
+	if template.Spec.Provider.SecretRef != nil {
+		secret, err := c.metricCache.secret(ctx, c.kubeClient, namespace, template.Spec.Provider.SecretRef.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("metric template %s.%s secret %s error: %v",
+				metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
+		}
+		credentials = secret.Data
+		secretVersion = secret.ResourceVersion
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", namespace, template.Name, metric.Interval)
+	version := template.ResourceVersion + "/" + secretVersion
+	provider, err := c.metricCache.provider(key, version, func() (providers.Interface, error) {
+		factory := providers.Factory{}
+		return factory.Provider(metric.Interval, template.Spec.Provider, credentials)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("metric template %s.%s provider %s error: %v",
+			metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
 	}
 
-	return true
+	return template, provider, nil
 }
 
 func toMetricModel(r *flaggerv1.Canary, interval string) flaggerv1.MetricTemplateModel {
@@ -1,10 +1,18 @@
 package controller
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
@@ -19,16 +27,16 @@ func TestController_checkMetricProviderAvailability(t *testing.T) {
 		obs, err := observers.NewFactory(testMetricsServerURL)
 		require.NoError(t, err)
 		ctrl := Controller{observerFactory: obs, logger: zap.S(), eventRecorder: &record.FakeRecorder{}}
-		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
+		require.NoError(t, ctrl.checkMetricProviderAvailability(context.TODO(), canary))
 
 		// error
 		ctrl.observerFactory, err = observers.NewFactory("http://non-exist")
 		require.NoError(t, err)
-		require.Error(t, ctrl.checkMetricProviderAvailability(canary))
+		require.Error(t, ctrl.checkMetricProviderAvailability(context.TODO(), canary))
 
 		// ok
 		canary.Spec.MetricsServer = testMetricsServerURL
-		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
+		require.NoError(t, ctrl.checkMetricProviderAvailability(context.TODO(), canary))
 	})
 
 	t.Run("templateRef", func(t *testing.T) {
@@ -41,13 +49,567 @@ func TestController_checkMetricProviderAvailability(t *testing.T) {
 			},
 		}}}
 		canary := &flaggerv1.Canary{Spec: flaggerv1.CanarySpec{Analysis: analysis}}
-		require.Error(t, ctrl.checkMetricProviderAvailability(canary))
+		require.Error(t, ctrl.checkMetricProviderAvailability(context.TODO(), canary))
 
 		// ok
 		canary.Spec.Analysis.Metrics[0].TemplateRef = &flaggerv1.CrossNamespaceObjectReference{
 			Name:      "envoy",
 			Namespace: "default",
 		}
-		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
+		require.NoError(t, ctrl.checkMetricProviderAvailability(context.TODO(), canary))
 	})
 }
+
+func TestController_runMetricComparison(t *testing.T) {
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()["query"][0]
+		queries = append(queries, query)
+		value := "100"
+		if strings.Contains(query, `variant="canary"`) {
+			value = "120"
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"` + value + `"]}]}}`))
+	}))
+	defer ts.Close()
+
+	ctrl := newDeploymentFixture(nil).ctrl
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "latency"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+			Query:    `latency{workload="{{ target }}",service="{{ service }}",variant="{{ variant }}"}`,
+		},
+	}
+	require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:        "latency",
+		Interval:    "1m",
+		TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "latency"},
+		Comparison: &flaggerv1.CanaryMetricComparison{
+			Mode:      flaggerv1.ComparisonRatio,
+			Tolerance: 10,
+		},
+	}}
+
+	// the canary is 20% worse than the primary
+	results := ctrl.runMetricChecks(context.TODO(), canary)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+	assert.Equal(t, float64(120), *results[0].Value)
+	require.Len(t, queries, 2)
+	assert.Equal(t, `latency{workload="podinfo",service="podinfo-canary",variant="canary"}`, queries[0])
+	assert.Equal(t, `latency{workload="podinfo-primary",service="podinfo-primary",variant="primary"}`, queries[1])
+
+	canary.Spec.Analysis.Metrics[0].Comparison.Tolerance = 25
+	assert.True(t, ctrl.runMetricChecks(context.TODO(), canary)[0].Passed)
+
+	// higher values are better
+	canary.Spec.Analysis.Metrics[0].Comparison = &flaggerv1.CanaryMetricComparison{
+		Mode:      flaggerv1.ComparisonDifference,
+		Tolerance: 0,
+		Direction: flaggerv1.ComparisonHigherIsBetter,
+	}
+	assert.True(t, ctrl.runMetricChecks(context.TODO(), canary)[0].Passed)
+
+	// the source workload is the primary
+	queries = nil
+	canary.Spec.SourceRef = &flaggerv1.CrossNamespaceObjectReference{Name: "podinfo-v1"}
+	ctrl.runMetricChecks(context.TODO(), canary)
+	require.Len(t, queries, 2)
+	assert.Equal(t, `latency{workload="podinfo-v1",service="podinfo-primary",variant="primary"}`, queries[1])
+}
+
+func TestController_runMetricChecksTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// hang until the query is cancelled
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctrl := newDeploymentFixture(nil).ctrl
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "slow"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+			Query:    `vector(1)`,
+		},
+	}
+	require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:           "slow",
+		Interval:       "1m",
+		Timeout:        "50ms",
+		TemplateRef:    &flaggerv1.CrossNamespaceObjectReference{Name: "slow"},
+		ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(1)},
+	}}
+
+	// the metric timeout bounds the query
+	results := ctrl.runMetricChecks(context.TODO(), canary)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+	assert.Contains(t, results[0].Message, context.DeadlineExceeded.Error())
+
+	// the analysis context cancels the query
+	canary.Spec.Analysis.Metrics[0].Timeout = ""
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = ctrl.runMetricChecks(ctx, canary)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+	assert.Contains(t, results[0].Message, context.Canceled.Error())
+}
+
+func TestController_runMetricChecksSlowQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// answer after the default provider timeout
+		time.Sleep(5500 * time.Millisecond)
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"1"]}]}}`))
+	}))
+	defer ts.Close()
+
+	ctrl := newDeploymentFixture(nil).ctrl
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "slow"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+			Query:    `vector(1)`,
+		},
+	}
+	require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:           "slow",
+		Interval:       "1m",
+		Timeout:        "10s",
+		TemplateRef:    &flaggerv1.CrossNamespaceObjectReference{Name: "slow"},
+		ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(1)},
+	}}
+
+	// the metric timeout allows the query to run longer than the default provider timeout
+	results := ctrl.runMetricChecks(context.TODO(), canary)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Passed, results[0].Message)
+	assert.Equal(t, float64(1), *results[0].Value)
+}
+
+func TestController_runWebMetricComparison(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		value := "99.9"
+		if strings.HasSuffix(r.URL.Path, "/canary") {
+			value = "95"
+		}
+		w.Write([]byte(`{"availability": "` + value + `"}`))
+	}))
+	defer ts.Close()
+
+	ctrl := newDeploymentFixture(nil).ctrl
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "availability"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{
+				Type:    "web",
+				Address: ts.URL + "/slo/{{ namespace }}/{{ target }}/{{ variant }}",
+				Web:     &flaggerv1.MetricTemplateWebRequest{JSONPath: ".availability"},
+			},
+		},
+	}
+	require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:        "availability",
+		Interval:    "1m",
+		TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "availability"},
+		Comparison: &flaggerv1.CanaryMetricComparison{
+			Mode:      flaggerv1.ComparisonDifference,
+			Tolerance: 1,
+			Direction: flaggerv1.ComparisonHigherIsBetter,
+		},
+	}}
+
+	results := ctrl.runMetricChecks(context.TODO(), canary)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+	assert.Equal(t, float64(95), *results[0].Value)
+	assert.Equal(t, []string{"/slo/default/podinfo/canary", "/slo/default/podinfo-primary/primary"}, paths)
+}
+
+func TestController_runPodMetricChecks(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	for name, restarts := range map[string]int32{"podinfo": 3, "podinfo-primary": 1} {
+		_, err := mocks.kubeClient.CoreV1().Pods("default").Create(context.TODO(), &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-1", Namespace: "default", Labels: map[string]string{"app": name}},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "podinfo", RestartCount: restarts}},
+			},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{
+		{Name: "request-success-rate", ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(99)}},
+		{Name: observers.PodRestartsMetric, ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(5)}},
+		{Name: observers.PodCrashLoopBackOffMetric, ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(0)}},
+		{Name: observers.PodOOMKilledMetric, Comparison: &flaggerv1.CanaryMetricComparison{
+			Mode: flaggerv1.ComparisonDifference,
+		}},
+	}
+
+	// the Prometheus metrics are not evaluated from the pods
+	results := mocks.ctrl.runPodMetricChecks(context.TODO(), canary, mocks.deployer)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, float64(3), *results[0].Value)
+	assert.True(t, results[1].Passed)
+	assert.True(t, results[2].Passed)
+
+	// the canary restarted more than the primary
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{
+		{Name: observers.PodRestartsMetric, Comparison: &flaggerv1.CanaryMetricComparison{
+			Mode:      flaggerv1.ComparisonDifference,
+			Tolerance: 1,
+		}},
+	}
+	results = mocks.ctrl.runPodMetricChecks(context.TODO(), canary, mocks.deployer)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+
+	// the metrics API is not available in the fake clientset
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{
+		{Name: observers.CanaryCPUMetric, ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(500)}},
+	}
+	results = mocks.ctrl.runPodMetricChecks(context.TODO(), canary, mocks.deployer)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+	assert.Nil(t, results[0].Value)
+}
+
+func TestController_runJudge(t *testing.T) {
+	canarySeries := `[1545905200,"100"],[1545905215,"101"],[1545905230,"99"],[1545905245,"100"],[1545905260,"102"],[1545905275,"98"]`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query_range", r.URL.Path)
+		series := `[1545905200,"100"],[1545905215,"102"],[1545905230,"98"],[1545905245,"101"],[1545905260,"99"],[1545905275,"100"]`
+		if strings.Contains(r.URL.Query().Get("query"), `variant="canary"`) {
+			series = canarySeries
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[` + series + `]}]}}`))
+	}))
+	defer ts.Close()
+
+	ctrl := newDeploymentFixture(nil).ctrl
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "latency"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+			Query:    `latency{variant="{{ variant }}"}`,
+		},
+	}
+	require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Judge = &flaggerv1.CanaryJudge{MarginalScore: 50}
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:        "latency",
+		Interval:    "1m",
+		TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "latency"},
+		Comparison: &flaggerv1.CanaryMetricComparison{
+			Mode:      flaggerv1.ComparisonRatio,
+			Tolerance: 10,
+		},
+	}}
+
+	// same distributions
+	assert.Empty(t, ctrl.runMetricChecks(context.TODO(), canary))
+	assert.Equal(t, analysisPassed, ctrl.runJudge(context.TODO(), canary))
+
+	// significantly slower within the tolerance
+	canarySeries = `[1545905200,"105"],[1545905215,"106"],[1545905230,"104"],[1545905245,"107"],[1545905260,"105"],[1545905275,"106"]`
+	assert.Equal(t, analysisHeld, ctrl.runJudge(context.TODO(), canary))
+
+	// significantly slower beyond the tolerance
+	canarySeries = `[1545905200,"150"],[1545905215,"160"],[1545905230,"140"],[1545905245,"155"],[1545905260,"145"],[1545905275,"150"]`
+	assert.Equal(t, analysisFailed, ctrl.runJudge(context.TODO(), canary))
+
+	// not enough samples
+	canarySeries = `[1545905200,"100"]`
+	assert.Equal(t, analysisHeld, ctrl.runJudge(context.TODO(), canary))
+}
+
+func TestController_runJudgeCritical(t *testing.T) {
+	series := map[string]string{
+		"latency": `[1545905200,"100"],[1545905215,"102"],[1545905230,"98"],[1545905245,"101"],[1545905260,"99"],[1545905275,"100"]`,
+		"errors":  `[1545905200,"1"],[1545905215,"2"],[1545905230,"1"],[1545905245,"2"],[1545905260,"1"],[1545905275,"2"]`,
+	}
+	canaryErrors := `[1545905200,"50"],[1545905215,"60"],[1545905230,"55"],[1545905245,"50"],[1545905260,"60"],[1545905275,"55"]`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		if strings.HasPrefix(query, "down") {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		name := strings.Split(query, "{")[0]
+		values := series[name]
+		if name == "errors" && strings.Contains(query, `variant="canary"`) {
+			values = canaryErrors
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[` + values + `]}]}}`))
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	ctrl := mocks.ctrl
+	for _, name := range []string{"latency", "errors", "down"} {
+		template := &flaggerv1.MetricTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: flaggerv1.MetricTemplateSpec{
+				Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+				Query:    name + `{variant="{{ variant }}"}`,
+			},
+		}
+		require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+	}
+
+	judged := func(name string) flaggerv1.CanaryMetric {
+		return flaggerv1.CanaryMetric{
+			Name:        name,
+			Interval:    "1m",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: name},
+			Comparison: &flaggerv1.CanaryMetricComparison{
+				Mode:      flaggerv1.ComparisonRatio,
+				Tolerance: 10,
+			},
+		}
+	}
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Judge = &flaggerv1.CanaryJudge{MarginalScore: 50}
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{judged("down"), judged("latency"), judged("errors")}
+	canary.Spec.Analysis.Metrics[1].Weight = toFloatPtr(9)
+
+	// the query error doesn't abort the judge
+	assert.Equal(t, analysisHeld, ctrl.runJudge(context.TODO(), canary))
+	record := ctrl.analysisRecord(canary)
+	require.NotNil(t, record.Judge)
+	require.Len(t, record.Judge.Metrics, 3)
+	assert.False(t, record.Judge.Metrics[0].Passed)
+	assert.True(t, record.Judge.Metrics[1].Passed)
+	assert.False(t, record.Judge.Metrics[2].Passed)
+	assert.InDelta(t, 900.0/11, record.Judge.Score, 0.001)
+
+	// a critical metric fails the analysis regardless of the score
+	canary.Spec.Analysis.Metrics[0] = judged("latency")
+	canary.Spec.Analysis.Metrics[2].Critical = true
+	assert.Equal(t, analysisFailed, ctrl.runJudge(context.TODO(), canary))
+	assert.InDelta(t, 1000.0/11, ctrl.analysisRecord(canary).Judge.Score, 0.001)
+
+	// the missing values hold the advancement
+	canaryErrors = `[1545905200,"50"]`
+	assert.Equal(t, analysisHeld, ctrl.runJudge(context.TODO(), canary))
+	assert.True(t, ctrl.analysisRecord(canary).Judge.Metrics[2].Held)
+}
+
+func TestController_runJudgePolicies(t *testing.T) {
+	series := map[string]string{
+		"latency": `[1545905200,"100"],[1545905215,"102"],[1545905230,"98"],[1545905245,"101"],[1545905260,"99"],[1545905275,"100"]`,
+		"errors":  `[1545905200,"1"],[1545905215,"2"],[1545905230,"1"],[1545905245,"2"],[1545905260,"1"],[1545905275,"2"]`,
+	}
+	canaryErrors := `[1545905200,"50"],[1545905215,"60"],[1545905230,"55"],[1545905245,"50"],[1545905260,"60"],[1545905275,"55"]`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		if strings.HasPrefix(query, "down") {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		name := strings.Split(query, "{")[0]
+		values := series[name]
+		if name == "errors" && strings.Contains(query, `variant="canary"`) {
+			values = canaryErrors
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[` + values + `]}]}}`))
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	ctrl := mocks.ctrl
+	for _, name := range []string{"latency", "errors", "down"} {
+		template := &flaggerv1.MetricTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: flaggerv1.MetricTemplateSpec{
+				Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+				Query:    name + `{variant="{{ variant }}"}`,
+			},
+		}
+		require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+	}
+
+	judged := func(name string) flaggerv1.CanaryMetric {
+		return flaggerv1.CanaryMetric{
+			Name:        name,
+			Interval:    "1m",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: name},
+			Comparison: &flaggerv1.CanaryMetricComparison{
+				Mode:      flaggerv1.ComparisonRatio,
+				Tolerance: 10,
+			},
+		}
+	}
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Judge = &flaggerv1.CanaryJudge{MarginalScore: 50}
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{judged("down"), judged("latency"), judged("errors")}
+	canary.Spec.Analysis.Metrics[0].OnError = &flaggerv1.CanaryMetricPolicy{Action: flaggerv1.MetricPolicyHold}
+	canary.Spec.Analysis.Metrics[1].Weight = toFloatPtr(9)
+
+	// the metric with a query error is held
+	assert.Equal(t, analysisHeld, ctrl.runJudge(context.TODO(), canary))
+	record := ctrl.analysisRecord(canary)
+	require.NotNil(t, record.Judge)
+	require.Len(t, record.Judge.Metrics, 3)
+	assert.True(t, record.Judge.Metrics[0].Held)
+	assert.True(t, record.Judge.Metrics[1].Passed)
+	assert.False(t, record.Judge.Metrics[2].Passed)
+	assert.InDelta(t, 90, record.Judge.Score, 0.001)
+
+	// the error passes the metric and the score reaches the pass score
+	canary.Spec.Analysis.Judge.PassScore = 90
+	canary.Spec.Analysis.Metrics[0].OnError.Action = flaggerv1.MetricPolicyPass
+	assert.Equal(t, analysisPassed, ctrl.runJudge(context.TODO(), canary))
+
+	// the error fails the metric by default
+	canary.Spec.Analysis.Metrics[0].OnError = nil
+	assert.Equal(t, analysisHeld, ctrl.runJudge(context.TODO(), canary))
+	record = ctrl.analysisRecord(canary)
+	assert.False(t, record.Judge.Metrics[0].Held)
+	assert.False(t, record.Judge.Metrics[0].Passed)
+	assert.InDelta(t, 900.0/11, record.Judge.Score, 0.001)
+
+	// the missing values are handled by the no-data policy
+	canaryErrors = `[1545905200,"50"]`
+	canary.Spec.Analysis.Metrics = canary.Spec.Analysis.Metrics[1:]
+	canary.Spec.Analysis.Metrics[1].OnNoData = &flaggerv1.CanaryMetricPolicy{Action: flaggerv1.MetricPolicyFail}
+	canary.Spec.Analysis.Metrics[1].Critical = true
+	assert.Equal(t, analysisFailed, ctrl.runJudge(context.TODO(), canary))
+	canary.Spec.Analysis.Metrics[1].OnNoData.Action = flaggerv1.MetricPolicyPass
+	assert.Equal(t, analysisPassed, ctrl.runJudge(context.TODO(), canary))
+}
+
+func TestController_scoreMetrics(t *testing.T) {
+	ctrl := newDeploymentFixture(nil).ctrl
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{
+		{Name: "request-success-rate", Critical: true, ThresholdRange: &flaggerv1.CanaryThresholdRange{Min: toFloatPtr(99)}},
+		{Name: "request-duration", Weight: toFloatPtr(3), ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(500)}},
+		{Name: "cpu", Query: "vector(1)", ThresholdRange: &flaggerv1.CanaryThresholdRange{Max: toFloatPtr(0)}},
+	}
+
+	// every metric is evaluated
+	results := ctrl.runBuiltinMetricChecks(context.TODO(), canary)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Passed)
+	assert.True(t, results[1].Passed)
+	assert.False(t, results[2].Passed)
+	assert.Equal(t, float64(1), *results[2].Value)
+	assert.NotEmpty(t, results[2].Message)
+
+	// the pass score defaults to 100
+	status, result := ctrl.scoreMetrics(canary, results)
+	assert.InDelta(t, 80, status.Score, 0.001)
+	assert.False(t, status.Passed)
+	assert.Equal(t, analysisFailed, result)
+
+	canary.Spec.Analysis.PassScore = toFloatPtr(80)
+	status, result = ctrl.scoreMetrics(canary, results)
+	assert.True(t, status.Passed)
+	assert.Equal(t, analysisPassed, result)
+
+	// a critical metric fails the checks regardless of the score
+	results[0].Passed = false
+	results[2].Passed = true
+	status, result = ctrl.scoreMetrics(canary, results)
+	assert.InDelta(t, 80, status.Score, 0.001)
+	assert.False(t, status.Passed)
+	assert.Equal(t, analysisFailed, result)
+}
+
+func TestController_metricPolicies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("query"), "error") {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer ts.Close()
+
+	ctrl := newDeploymentFixture(nil).ctrl
+	for _, name := range []string{"no-data", "error"} {
+		template := &flaggerv1.MetricTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: flaggerv1.MetricTemplateSpec{
+				Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+				Query:    name,
+			},
+		}
+		require.NoError(t, ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+	}
+
+	canary := newDeploymentTestCanary()
+	canary.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{
+		{
+			Name:        "no-data",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "no-data"},
+			OnNoData:    &flaggerv1.CanaryMetricPolicy{Action: flaggerv1.MetricPolicyPass},
+		},
+		{
+			Name:        "error",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "error"},
+			OnError:     &flaggerv1.CanaryMetricPolicy{Action: flaggerv1.MetricPolicyRetry, Retries: 2},
+		},
+	}
+
+	// the missing values pass and the error is retried twice
+	for retry := 1; retry <= 2; retry++ {
+		results := ctrl.runMetricChecks(context.TODO(), canary)
+		require.Len(t, results, 2)
+		assert.True(t, results[0].Passed)
+		assert.True(t, results[1].Held)
+		assert.Equal(t, retry, results[1].Retries)
+
+		status, result := ctrl.scoreMetrics(canary, results)
+		assert.True(t, status.Held)
+		assert.Equal(t, analysisHeld, result)
+		canary.Status.Analysis = &status
+	}
+
+	results := ctrl.runMetricChecks(context.TODO(), canary)
+	assert.False(t, results[1].Held)
+	assert.False(t, results[1].Passed)
+	_, result := ctrl.scoreMetrics(canary, results)
+	assert.Equal(t, analysisFailed, result)
+
+	// the advancement is held without limit
+	canary.Spec.Analysis.Metrics[0].OnNoData.Action = flaggerv1.MetricPolicyHold
+	canary.Spec.Analysis.Metrics[1].OnError.Action = flaggerv1.MetricPolicyHold
+	for i := 0; i < 3; i++ {
+		results = ctrl.runMetricChecks(context.TODO(), canary)
+		assert.True(t, results[0].Held)
+		assert.True(t, results[1].Held)
+		status, result := ctrl.scoreMetrics(canary, results)
+		assert.Equal(t, analysisHeld, result)
+		canary.Status.Analysis = &status
+	}
+
+	// the error fails the metric by default
+	canary.Spec.Analysis.Metrics[1].OnError = nil
+	results = ctrl.runMetricChecks(context.TODO(), canary)
+	assert.False(t, results[1].Held)
+	_, result = ctrl.scoreMetrics(canary, results)
+	assert.Equal(t, analysisFailed, result)
+}
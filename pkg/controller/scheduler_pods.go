@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/canary"
+	"github.com/weaveworks/flagger/pkg/metrics/observers"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// runPodMetricChecks evaluates the pod health metrics from the Kubernetes API and returns their outcome,
+// the pods are selected with the label returned by the canary controller
+func (c *Controller) runPodMetricChecks(ctx context.Context, cd *flaggerv1.Canary, canaryController canary.Controller) []flaggerv1.CanaryMetricStatus {
+	var results []flaggerv1.CanaryMetricStatus
+	var observer *observers.PodObserver
+	var label string
+	for _, metric := range cd.GetAnalysis().Metrics {
+		if metric.TemplateRef != nil || !observers.IsPodMetric(metric.Name) {
+			continue
+		}
+		if metric.Interval == "" {
+			metric.Interval = cd.GetMetricInterval()
+		}
+
+		if observer == nil {
+			var err error
+			label, _, err = canaryController.GetMetadata(cd)
+			if err == nil && label == "" {
+				err = errors.New("the target has no pod selector")
+			}
+			if err != nil {
+				results = append(results, c.metricError(cd, metric, "Pod metric %s not available for %s.%s: %v",
+					metric.Name, cd.Spec.TargetRef.Name, cd.Namespace, err))
+				continue
+			}
+			observer = observers.NewPodObserver(c.kubeClient, c.kubeClient.Discovery().RESTClient())
+		}
+
+		metricCtx, cancel := metricContext(ctx, metric)
+		query := func(model flaggerv1.MetricTemplateModel) (float64, error) {
+			return observer.GetMetric(metricCtx, metric.Name, label, model)
+		}
+		results = append(results, c.runPodMetricCheck(cd, metric, query))
+		cancel()
+	}
+
+	return results
+}
+
+func (c *Controller) runPodMetricCheck(cd *flaggerv1.Canary, metric flaggerv1.CanaryMetric,
+	query func(model flaggerv1.MetricTemplateModel) (float64, error)) flaggerv1.CanaryMetricStatus {
+	// compare the canary pods to the primary pods
+	if metric.Comparison != nil {
+		return c.runMetricComparison(cd, metric, query)
+	}
+
+	val, err := query(toMetricModel(cd, metric.Interval))
+	if err != nil {
+		if errors.Is(err, providers.ErrNoValuesFound) {
+			return c.metricNoData(cd, metric, "Halt advancement no values found for pod metric %s: %v",
+				metric.Name, err)
+		}
+		return c.metricError(cd, metric, "Pod metric query failed for %s: %v", metric.Name, err)
+	}
+	return c.checkMetricThreshold(cd, metric, val)
+}
@@ -15,7 +15,7 @@ func TestScheduler_ServicePromotion(t *testing.T) {
 	mocks := newDeploymentFixture(newTestServiceCanary())
 
 	// init
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check initialized status
 	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -28,7 +28,7 @@ func TestScheduler_ServicePromotion(t *testing.T) {
 	require.NoError(t, err)
 
 	// detect service spec changes
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	primaryWeight, canaryWeight, mirrored, err := mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -39,7 +39,7 @@ func TestScheduler_ServicePromotion(t *testing.T) {
 	require.NoError(t, err)
 
 	// advance
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check progressing status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -47,7 +47,7 @@ func TestScheduler_ServicePromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
 
 	// promote
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	// check promoting status
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
@@ -55,7 +55,7 @@ func TestScheduler_ServicePromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhasePromoting, c.Status.Phase)
 
 	// finalise
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	primaryWeight, canaryWeight, mirrored, err = mocks.router.GetRoutes(mocks.canary)
 	require.NoError(t, err)
@@ -76,7 +76,7 @@ func TestScheduler_ServicePromotion(t *testing.T) {
 	assert.Equal(t, flaggerv1.CanaryPhaseFinalising, c.Status.Phase)
 
 	// scale canary to zero
-	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary(context.TODO(), "podinfo", "default")
 
 	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
 	require.NoError(t, err)
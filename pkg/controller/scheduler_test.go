@@ -44,6 +44,15 @@ func assertPhase(flaggerClient clientset.Interface, canary string, phase flagger
 	return nil
 }
 
+func findCondition(c *flaggerv1.Canary, conditionType flaggerv1.CanaryConditionType) *flaggerv1.CanaryCondition {
+	for i := range c.Status.Conditions {
+		if c.Status.Conditions[i].Type == conditionType {
+			return &c.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
 func alwaysReady() bool {
 	return true
 }
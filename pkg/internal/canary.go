@@ -6,18 +6,24 @@ import (
 	"github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 )
 
+// Deprecated: replaced by spec.replicaScaling.enabled
 const OAM_CANARY_EXT_SWITCH = "oam.canary.extension.switch"
 
 func IsRollingUpdate(canary *v1beta1.Canary) bool {
-	return canary.Spec.Analysis.StepReplicas != 0
+	return canary.GetAnalysis() != nil && canary.GetAnalysis().StepReplicas != 0
 }
 
+// IsExtentOn returns true if the replica scaling is enabled,
+// the deprecated annotation is only read when spec.replicaScaling is not set
 func IsExtentOn(canary *v1beta1.Canary) bool {
+	if canary.Spec.ReplicaScaling != nil {
+		return canary.Spec.ReplicaScaling.Enabled
+	}
 	return canary.Annotations[OAM_CANARY_EXT_SWITCH] == "true"
 }
 
 func HasSourceTargetRef(canary *v1beta1.Canary) bool {
-	return canary.Spec.SourceRef.Name != ""
+	return canary.Spec.SourceRef != nil && canary.Spec.SourceRef.Name != ""
 }
 
 //  Whether canary promoted
@@ -7,8 +7,7 @@ import (
 )
 
 const (
-	noopRoute     = "NOOP_ROUTE"
-	canaryHaltUrl = "CANARY_HALT_URL"
+	noopRoute = "NOOP_ROUTE"
 )
 
 func IsNoopRoute() bool {
@@ -21,7 +20,3 @@ func IsNoopRoute() bool {
 	}
 	return false
 }
-
-func defaultCanaryHaltUrl() (string, bool) {
-	return os.LookupEnv(canaryHaltUrl)
-}
@@ -8,28 +8,51 @@ import (
 
 // the value should be seperated by "," , labels combination should distinguish canary and primary workloads.
 // these labels used to select canary and primary as seperated workloads.
+// Deprecated: replaced by spec.podSelector.distinguishLabels
 const OAM_CANARY_DISTINGUISH_LABELS = "oam.canary.distinguish.labels"
 
 // the value should be seperated by "," , labels combination should be same for canary and primary workloads.
 // this labels used to select canary and primary as a whole app.
+// Deprecated: replaced by spec.podSelector.generalLabels
 const OAM_CANARY_GENERAL_LABELS = "oam.canary.general.labels"
 
 // check canary distinguish labels existing, if existed, return true and labels slice, otherwise return false and nil slice.
+// spec.podSelector.distinguishLabels takes precedence over the deprecated annotation.
 func CanaryDistinguishLabelsExisted(canary *v1beta1.Canary) ([]string, bool) {
-	labels, exist := canary.Annotations[OAM_CANARY_DISTINGUISH_LABELS]
-	if !exist || labels == "" {
-		return nil, false
+	if canary.Spec.PodSelector != nil && len(canary.Spec.PodSelector.DistinguishLabels) > 0 {
+		return canary.Spec.PodSelector.DistinguishLabels, true
 	}
+	return annotationLabels(canary, OAM_CANARY_DISTINGUISH_LABELS)
+}
 
-	labelsSlice := strings.Split(labels, ",")
-	if len(labelsSlice) == 0 {
-		return nil, false
+// check canary general labels existing, spec.podSelector.generalLabels takes precedence over the deprecated annotation.
+func CanaryGeneralLabelsExisted(canary *v1beta1.Canary) ([]string, bool) {
+	if canary.Spec.PodSelector != nil && len(canary.Spec.PodSelector.GeneralLabels) > 0 {
+		return canary.Spec.PodSelector.GeneralLabels, true
 	}
-	return labelsSlice, true
+	return annotationLabels(canary, OAM_CANARY_GENERAL_LABELS)
 }
 
-func CanaryGeneralLabelsExisted(canary *v1beta1.Canary) ([]string, bool) {
-	labels, exist := canary.Annotations[OAM_CANARY_GENERAL_LABELS]
+// DeprecatedAnnotations returns the deprecated annotations read as a fallback for the typed fields,
+// the map value is the field replacing the annotation
+func DeprecatedAnnotations(canary *v1beta1.Canary) map[string]string {
+	result := make(map[string]string)
+	if _, ok := canary.Annotations[OAM_CANARY_EXT_SWITCH]; ok && canary.Spec.ReplicaScaling == nil {
+		result[OAM_CANARY_EXT_SWITCH] = "spec.replicaScaling.enabled"
+	}
+	if _, ok := annotationLabels(canary, OAM_CANARY_DISTINGUISH_LABELS); ok &&
+		(canary.Spec.PodSelector == nil || len(canary.Spec.PodSelector.DistinguishLabels) == 0) {
+		result[OAM_CANARY_DISTINGUISH_LABELS] = "spec.podSelector.distinguishLabels"
+	}
+	if _, ok := annotationLabels(canary, OAM_CANARY_GENERAL_LABELS); ok &&
+		(canary.Spec.PodSelector == nil || len(canary.Spec.PodSelector.GeneralLabels) == 0) {
+		result[OAM_CANARY_GENERAL_LABELS] = "spec.podSelector.generalLabels"
+	}
+	return result
+}
+
+func annotationLabels(canary *v1beta1.Canary, annotation string) ([]string, bool) {
+	labels, exist := canary.Annotations[annotation]
 	if !exist || labels == "" {
 		return nil, false
 	}
@@ -0,0 +1,150 @@
+package judge
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// MinSamples is the minimum number of samples of each time series required to judge a metric
+const MinSamples = 3
+
+// ErrNotEnoughSamples is returned when a time series has less than MinSamples samples
+var ErrNotEnoughSamples = errors.New("not enough samples")
+
+// Verdict is the classification of a metric
+type Verdict string
+
+const (
+	// Pass means the canary is not significantly worse than the primary
+	Pass Verdict = "pass"
+	// Marginal means the canary is significantly worse than the primary but within the tolerance
+	Marginal Verdict = "marginal"
+	// Fail means the canary is significantly worse than the primary beyond the tolerance
+	Fail Verdict = "fail"
+)
+
+// Result is the judgement of a metric
+type Result struct {
+	Verdict       Verdict
+	CanaryMedian  float64
+	PrimaryMedian float64
+	// Deviation of the canary median from the primary median
+	Deviation float64
+	// PValue of the two-sided Mann-Whitney U test
+	PValue float64
+}
+
+// Metric compares the canary and primary samples with a Mann-Whitney U test at the given confidence level (0-100),
+// a significant difference is classified as marginal or fail with the tolerance and direction of the comparison
+func Metric(canary []float64, primary []float64, comparison flaggerv1.CanaryMetricComparison, confidence float64) (Result, error) {
+	if len(canary) < MinSamples || len(primary) < MinSamples {
+		return Result{}, fmt.Errorf("canary has %d samples, primary has %d samples: %w",
+			len(canary), len(primary), ErrNotEnoughSamples)
+	}
+
+	result := Result{
+		Verdict:       Pass,
+		CanaryMedian:  median(canary),
+		PrimaryMedian: median(primary),
+		PValue:        MannWhitneyU(canary, primary),
+	}
+
+	var withinTolerance bool
+	result.Deviation, withinTolerance = comparison.Compare(result.CanaryMedian, result.PrimaryMedian)
+	worse := result.Deviation
+	if comparison.Direction == flaggerv1.ComparisonHigherIsBetter {
+		worse = -result.Deviation
+	}
+
+	if result.PValue < 1-confidence/100 && worse > 0 {
+		result.Verdict = Fail
+		if withinTolerance {
+			result.Verdict = Marginal
+		}
+	}
+	return result, nil
+}
+
+// Score returns the weighted score (0-100) of the verdicts,
+// a pass counts fully, a marginal counts half and a fail doesn't count
+func Score(verdicts []Verdict, weights []float64) float64 {
+	var total, score float64
+	for i, verdict := range verdicts {
+		weight := weights[i]
+		total += weight
+		switch verdict {
+		case Pass:
+			score += weight
+		case Marginal:
+			score += weight / 2
+		}
+	}
+	if total == 0 {
+		return 100
+	}
+	return score / total * 100
+}
+
+// MannWhitneyU returns the two-sided p-value of the Mann-Whitney U test of the samples x and y,
+// using the normal approximation with tie and continuity corrections
+func MannWhitneyU(x []float64, y []float64) float64 {
+	type sample struct {
+		value float64
+		fromX bool
+	}
+	samples := make([]sample, 0, len(x)+len(y))
+	for _, v := range x {
+		samples = append(samples, sample{v, true})
+	}
+	for _, v := range y {
+		samples = append(samples, sample{v, false})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].value < samples[j].value })
+
+	// average ranks of the tied values
+	n := float64(len(samples))
+	var rankSumX, ties float64
+	for i := 0; i < len(samples); {
+		j := i
+		for j < len(samples) && samples[j].value == samples[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if samples[k].fromX {
+				rankSumX += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n1, n2 := float64(len(x)), float64(len(y))
+	u := rankSumX - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+
+	z := (math.Abs(u-mean) - 0.5) / sigma
+	if z < 0 {
+		z = 0
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,64 @@
+package judge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	// identical distributions
+	assert.InDelta(t, 1, MannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{1, 2, 3, 4, 5}), 0.001)
+
+	// all values tied
+	assert.Equal(t, float64(1), MannWhitneyU([]float64{5, 5, 5}, []float64{5, 5, 5}))
+
+	// no overlap, U = 0 with n1 = n2 = 8
+	p := MannWhitneyU([]float64{11, 12, 13, 14, 15, 16, 17, 18}, []float64{1, 2, 3, 4, 5, 6, 7, 8})
+	assert.InDelta(t, 0.00094, p, 0.00001)
+}
+
+func TestMetric(t *testing.T) {
+	primary := []float64{100, 102, 98, 101, 99, 100, 103, 97}
+	latency := flaggerv1.CanaryMetricComparison{Mode: flaggerv1.ComparisonRatio, Tolerance: 10}
+
+	result, err := Metric([]float64{101, 99, 100, 98, 102, 100, 97, 103}, primary, latency, 95)
+	require.NoError(t, err)
+	assert.Equal(t, Pass, result.Verdict)
+
+	// significantly slower within the tolerance
+	result, err = Metric([]float64{105, 106, 104, 107, 105, 106, 108, 104}, primary, latency, 95)
+	require.NoError(t, err)
+	assert.Equal(t, Marginal, result.Verdict)
+	assert.InDelta(t, 5.5, result.Deviation, 0.001)
+
+	// significantly slower beyond the tolerance
+	result, err = Metric([]float64{150, 160, 140, 155, 145, 150, 165, 150}, primary, latency, 95)
+	require.NoError(t, err)
+	assert.Equal(t, Fail, result.Verdict)
+
+	// significantly faster
+	result, err = Metric([]float64{50, 52, 48, 51, 49, 50, 53, 47}, primary, latency, 95)
+	require.NoError(t, err)
+	assert.Equal(t, Pass, result.Verdict)
+
+	// significantly lower success rate
+	successRate := flaggerv1.CanaryMetricComparison{Mode: flaggerv1.ComparisonDifference, Tolerance: 1, Direction: flaggerv1.ComparisonHigherIsBetter}
+	result, err = Metric([]float64{90, 91, 89, 90, 92, 90}, []float64{99, 99, 100, 99, 98, 99}, successRate, 95)
+	require.NoError(t, err)
+	assert.Equal(t, Fail, result.Verdict)
+
+	_, err = Metric([]float64{1, 2}, primary, latency, 95)
+	assert.True(t, errors.Is(err, ErrNotEnoughSamples))
+}
+
+func TestScore(t *testing.T) {
+	assert.Equal(t, float64(100), Score(nil, nil))
+	assert.Equal(t, float64(100), Score([]Verdict{Pass, Pass}, []float64{1, 2}))
+	assert.Equal(t, float64(75), Score([]Verdict{Pass, Marginal}, []float64{1, 1}))
+	assert.Equal(t, float64(25), Score([]Verdict{Fail, Pass}, []float64{3, 1}))
+}
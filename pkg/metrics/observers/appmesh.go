@@ -1,6 +1,7 @@
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -48,12 +49,16 @@ type AppMeshObserver struct {
 }
 
 func (ob *AppMeshObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRateContext(context.Background(), model)
+}
+
+func (ob *AppMeshObserver) GetRequestSuccessRateContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
 	query, err := RenderQuery(appMeshQueries["request-success-rate"], model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -62,12 +67,16 @@ func (ob *AppMeshObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateM
 }
 
 func (ob *AppMeshObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDurationContext(context.Background(), model)
+}
+
+func (ob *AppMeshObserver) GetRequestDurationContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
 	query, err := RenderQuery(appMeshQueries["request-duration"], model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -1,6 +1,7 @@
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -47,12 +48,16 @@ type ContourObserver struct {
 }
 
 func (ob *ContourObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRateContext(context.Background(), model)
+}
+
+func (ob *ContourObserver) GetRequestSuccessRateContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
 	query, err := RenderQuery(contourQueries["request-success-rate"], model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -61,12 +66,16 @@ func (ob *ContourObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateM
 }
 
 func (ob *ContourObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDurationContext(context.Background(), model)
+}
+
+func (ob *ContourObserver) GetRequestDurationContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
 	query, err := RenderQuery(contourQueries["request-duration"], model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -3,6 +3,8 @@ package observers
 import (
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/weaveworks/flagger/pkg/metrics/providers"
 )
@@ -12,11 +14,21 @@ type Factory struct {
 }
 
 func NewFactory(metricsServer string) (*Factory, error) {
-	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
-		Type:      "prometheus",
-		Address:   metricsServer,
-		SecretRef: nil,
-	}, nil)
+	return NewFactoryWithCredentials(metricsServer, nil)
+}
+
+// NewFactoryWithCredentials returns a factory whose Prometheus client authenticates with the credentials,
+// the credentials map has the same format as the prometheus metric template secret
+func NewFactoryWithCredentials(metricsServer string, credentials map[string][]byte) (*Factory, error) {
+	provider := flaggerv1.MetricTemplateProvider{
+		Type:    "prometheus",
+		Address: metricsServer,
+	}
+	if credentials != nil {
+		provider.SecretRef = &corev1.LocalObjectReference{Name: "metrics-server"}
+	}
+
+	client, err := providers.NewPrometheusProvider(provider, credentials)
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +38,7 @@ func NewFactory(metricsServer string) (*Factory, error) {
 	}, nil
 }
 
-func (factory Factory) Observer(provider string) Interface {
+func (factory Factory) Observer(provider string) ContextInterface {
 	switch {
 	case strings.HasPrefix(provider, flaggerv1.AppMeshProvider):
 		return &AppMeshObserver{
@@ -1,6 +1,7 @@
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -48,12 +49,16 @@ type HttpObserver struct {
 }
 
 func (ob *HttpObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRateContext(context.Background(), model)
+}
+
+func (ob *HttpObserver) GetRequestSuccessRateContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
 	query, err := RenderQuery(httpQueries["request-success-rate"], model)
 	if err != nil {
 		return 0, err
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, err
 	}
@@ -62,12 +67,16 @@ func (ob *HttpObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateMode
 }
 
 func (ob *HttpObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDurationContext(context.Background(), model)
+}
+
+func (ob *HttpObserver) GetRequestDurationContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
 	query, err := RenderQuery(httpQueries["request-duration"], model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
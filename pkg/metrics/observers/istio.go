@@ -1,6 +1,7 @@
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -51,12 +52,16 @@ type IstioObserver struct {
 }
 
 func (ob *IstioObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRateContext(context.Background(), model)
+}
+
+func (ob *IstioObserver) GetRequestSuccessRateContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
 	query, err := RenderQuery(istioQueries["request-success-rate"], model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -65,12 +70,16 @@ func (ob *IstioObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateMod
 }
 
 func (ob *IstioObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDurationContext(context.Background(), model)
+}
+
+func (ob *IstioObserver) GetRequestDurationContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
 	query, err := RenderQuery(istioQueries["request-duration"], model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
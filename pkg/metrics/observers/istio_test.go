@@ -1,6 +1,8 @@
 package observers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -82,3 +84,32 @@ func TestIstioObserver_GetRequestDuration(t *testing.T) {
 
 	assert.Equal(t, 100*time.Millisecond, val)
 }
+
+func TestIstioObserver_GetRequestSuccessRateContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "prometheus",
+		Address: ts.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	observer := &IstioObserver{
+		client: client,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = observer.GetRequestSuccessRateContext(ctx, flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
@@ -1,6 +1,7 @@
 package observers
 
 import (
+	"context"
 	"time"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
@@ -10,3 +11,11 @@ type Interface interface {
 	GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error)
 	GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error)
 }
+
+// ContextInterface is implemented by the observers whose queries are cancelled when the context is done
+type ContextInterface interface {
+	Interface
+
+	GetRequestSuccessRateContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error)
+	GetRequestDurationContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error)
+}
@@ -0,0 +1,199 @@
+package observers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// Builtin metrics evaluated from the Kubernetes API
+const (
+	// PodRestartsMetric is the number of container restarts since the pods were created
+	PodRestartsMetric = "pod-restarts"
+	// PodOOMKilledMetric is the number of containers terminated by the OOM killer
+	PodOOMKilledMetric = "pod-oom-killed"
+	// PodCrashLoopBackOffMetric is the number of containers waiting in CrashLoopBackOff
+	PodCrashLoopBackOffMetric = "pod-crash-loop-backoff"
+	// PodReadinessFlapsMetric is the number of pods whose readiness changed during the metric interval
+	PodReadinessFlapsMetric = "pod-readiness-flaps"
+	// CanaryCPUMetric is the average CPU usage of the pods in millicores
+	CanaryCPUMetric = "canary-cpu"
+	// CanaryMemoryMetric is the average memory usage of the pods in mebibytes
+	CanaryMemoryMetric = "canary-memory"
+)
+
+const podMetricsPath = "/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods"
+
+// IsPodMetric returns true if the metric is evaluated by the PodObserver
+func IsPodMetric(name string) bool {
+	switch name {
+	case PodRestartsMetric, PodOOMKilledMetric, PodCrashLoopBackOffMetric, PodReadinessFlapsMetric,
+		CanaryCPUMetric, CanaryMemoryMetric:
+		return true
+	}
+	return false
+}
+
+// PodObserver evaluates the health of the pods selected by label from the Kubernetes API,
+// the resource usage is read from the metrics.k8s.io API
+type PodObserver struct {
+	kubeClient    kubernetes.Interface
+	metricsClient rest.Interface
+}
+
+type podMetricsList struct {
+	Items []struct {
+		Containers []struct {
+			Usage map[corev1.ResourceName]resource.Quantity `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// NewPodObserver takes a Kubernetes client and a REST client for the metrics.k8s.io API,
+// the REST client can be nil if the resource usage metrics are not used
+func NewPodObserver(kubeClient kubernetes.Interface, metricsClient rest.Interface) *PodObserver {
+	return &PodObserver{
+		kubeClient:    kubeClient,
+		metricsClient: metricsClient,
+	}
+}
+
+// GetMetric evaluates the metric for the pods labeled with the model target
+func (ob *PodObserver) GetMetric(ctx context.Context, name string, label string, model flaggerv1.MetricTemplateModel) (float64, error) {
+	selector := fmt.Sprintf("%s=%s", label, model.Target)
+	switch name {
+	case CanaryCPUMetric, CanaryMemoryMetric:
+		return ob.getUsage(ctx, name, model.Namespace, selector)
+	}
+
+	pods, err := ob.listPods(ctx, model.Namespace, selector)
+	if err != nil {
+		return 0, err
+	}
+
+	switch name {
+	case PodRestartsMetric:
+		return countContainers(pods, func(cs corev1.ContainerStatus) float64 {
+			return float64(cs.RestartCount)
+		}), nil
+	case PodOOMKilledMetric:
+		return countContainers(pods, func(cs corev1.ContainerStatus) float64 {
+			if isOOMKilled(cs.State) || isOOMKilled(cs.LastTerminationState) {
+				return 1
+			}
+			return 0
+		}), nil
+	case PodCrashLoopBackOffMetric:
+		return countContainers(pods, func(cs corev1.ContainerStatus) float64 {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+				return 1
+			}
+			return 0
+		}), nil
+	case PodReadinessFlapsMetric:
+		interval, err := time.ParseDuration(model.Interval)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing metric interval: %w", err)
+		}
+		return countReadinessFlaps(pods, time.Now().Add(-interval)), nil
+	}
+
+	return 0, fmt.Errorf("pod metric %s not supported", name)
+}
+
+// listPods returns the pods matching the selector that are not being deleted
+func (ob *PodObserver) listPods(ctx context.Context, namespace string, selector string) ([]corev1.Pod, error) {
+	list, err := ob.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("pods %s.%s list query error: %w", selector, namespace, err)
+	}
+
+	pods := make([]corev1.Pod, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if pod.DeletionTimestamp == nil {
+			pods = append(pods, pod)
+		}
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods found for %s.%s: %w", selector, namespace, providers.ErrNoValuesFound)
+	}
+	return pods, nil
+}
+
+// getUsage returns the average CPU or memory usage of the pods matching the selector
+func (ob *PodObserver) getUsage(ctx context.Context, name string, namespace string, selector string) (float64, error) {
+	if ob.metricsClient == nil {
+		return 0, fmt.Errorf("metrics.k8s.io API client not configured")
+	}
+
+	b, err := ob.metricsClient.Get().
+		AbsPath(fmt.Sprintf(podMetricsPath, namespace)).
+		Param("labelSelector", selector).
+		DoRaw(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("pod metrics %s.%s query error: %w", selector, namespace, err)
+	}
+
+	var list podMetricsList
+	if err := json.Unmarshal(b, &list); err != nil {
+		return 0, fmt.Errorf("error unmarshaling pod metrics: %w, '%s'", err, string(b))
+	}
+	if len(list.Items) == 0 {
+		return 0, fmt.Errorf("no pod metrics found for %s.%s: %w", selector, namespace, providers.ErrNoValuesFound)
+	}
+
+	var total float64
+	for _, pod := range list.Items {
+		for _, container := range pod.Containers {
+			if name == CanaryCPUMetric {
+				cpu := container.Usage[corev1.ResourceCPU]
+				total += float64(cpu.MilliValue())
+			} else {
+				memory := container.Usage[corev1.ResourceMemory]
+				total += float64(memory.Value()) / (1024 * 1024)
+			}
+		}
+	}
+	return total / float64(len(list.Items)), nil
+}
+
+func countContainers(pods []corev1.Pod, value func(cs corev1.ContainerStatus) float64) float64 {
+	var total float64
+	for _, pod := range pods {
+		for _, cs := range pod.Status.ContainerStatuses {
+			total += value(cs)
+		}
+	}
+	return total
+}
+
+func isOOMKilled(state corev1.ContainerState) bool {
+	return state.Terminated != nil && state.Terminated.Reason == "OOMKilled"
+}
+
+// countReadinessFlaps counts the pods started before the given time whose Ready condition changed since then,
+// the readiness of the pods started later is ignored as they become ready for the first time
+func countReadinessFlaps(pods []corev1.Pod, since time.Time) float64 {
+	var total float64
+	for _, pod := range pods {
+		if pod.Status.StartTime == nil || pod.Status.StartTime.After(since) {
+			continue
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.LastTransitionTime.After(since) {
+				total++
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,122 @@
+package observers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+func TestPodObserver_GetMetric(t *testing.T) {
+	now := time.Now()
+	kubeClient := fake.NewSimpleClientset(
+		newTestPod("podinfo-1", "podinfo", now.Add(-time.Hour), now.Add(-30*time.Second), corev1.ContainerStatus{
+			RestartCount: 2,
+			LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"},
+			},
+		}, corev1.ContainerStatus{
+			RestartCount: 1,
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+			},
+		}),
+		newTestPod("podinfo-2", "podinfo", now.Add(-time.Hour), now.Add(-time.Hour), corev1.ContainerStatus{
+			RestartCount: 1,
+		}),
+		newTestPod("podinfo-3", "podinfo", now.Add(-10*time.Second), now.Add(-5*time.Second), corev1.ContainerStatus{}),
+		newTestPod("podinfo-primary-1", "podinfo-primary", now.Add(-time.Hour), now.Add(-time.Hour), corev1.ContainerStatus{
+			RestartCount: 5,
+		}),
+	)
+	observer := NewPodObserver(kubeClient, nil)
+	model := flaggerv1.MetricTemplateModel{Namespace: "default", Target: "podinfo", Interval: "1m"}
+
+	for name, expected := range map[string]float64{
+		PodRestartsMetric:         4,
+		PodOOMKilledMetric:        1,
+		PodCrashLoopBackOffMetric: 1,
+		PodReadinessFlapsMetric:   1,
+	} {
+		val, err := observer.GetMetric(context.TODO(), name, "app", model)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, val, name)
+	}
+
+	model.Target = "podinfo-primary"
+	val, err := observer.GetMetric(context.TODO(), PodRestartsMetric, "app", model)
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), val)
+
+	model.Target = "podinfo-missing"
+	_, err = observer.GetMetric(context.TODO(), PodRestartsMetric, "app", model)
+	require.True(t, errors.Is(err, providers.ErrNoValuesFound))
+
+	_, err = observer.GetMetric(context.TODO(), CanaryCPUMetric, "app", model)
+	require.Error(t, err)
+}
+
+func TestPodObserver_GetUsage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/metrics.k8s.io/v1beta1/namespaces/default/pods", r.URL.Path)
+		if r.URL.Query().Get("labelSelector") != "app=podinfo" {
+			w.Write([]byte(`{"items": []}`))
+			return
+		}
+		w.Write([]byte(`{"items": [
+			{"containers": [{"usage": {"cpu": "100m", "memory": "64Mi"}}, {"usage": {"cpu": "50m", "memory": "32Mi"}}]},
+			{"containers": [{"usage": {"cpu": "150m", "memory": "128Mi"}}]}
+		]}`))
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	metricsClient, err := rest.NewRESTClient(u, "", rest.ClientContentConfig{}, nil, http.DefaultClient)
+	require.NoError(t, err)
+
+	observer := NewPodObserver(fake.NewSimpleClientset(), metricsClient)
+	model := flaggerv1.MetricTemplateModel{Namespace: "default", Target: "podinfo", Interval: "1m"}
+
+	val, err := observer.GetMetric(context.TODO(), CanaryCPUMetric, "app", model)
+	require.NoError(t, err)
+	assert.Equal(t, float64(150), val)
+
+	val, err = observer.GetMetric(context.TODO(), CanaryMemoryMetric, "app", model)
+	require.NoError(t, err)
+	assert.Equal(t, float64(112), val)
+
+	model.Target = "podinfo-primary"
+	_, err = observer.GetMetric(context.TODO(), CanaryCPUMetric, "app", model)
+	require.True(t, errors.Is(err, providers.ErrNoValuesFound))
+}
+
+func newTestPod(name string, app string, started time.Time, ready time.Time, containers ...corev1.ContainerStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": app},
+		},
+		Status: corev1.PodStatus{
+			StartTime: &metav1.Time{Time: started},
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: ready}},
+			},
+			ContainerStatuses: containers,
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package observers
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"time"
@@ -29,6 +30,10 @@ type SkipperObserver struct {
 
 // GetRequestSuccessRate return value for Skipper Request Success Rate
 func (ob *SkipperObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+	return ob.GetRequestSuccessRateContext(context.Background(), model)
+}
+
+func (ob *SkipperObserver) GetRequestSuccessRateContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
 
 	model = encodeModelForSkipper(model)
 
@@ -39,7 +44,7 @@ func (ob *SkipperObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateM
 	logger, _ := logger.NewLoggerWithEncoding("debug", "json")
 	logger.Debugf("GetRequestSuccessRate: %s", query)
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -49,6 +54,10 @@ func (ob *SkipperObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateM
 
 // GetRequestDuration return value for Skipper Request Duration
 func (ob *SkipperObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	return ob.GetRequestDurationContext(context.Background(), model)
+}
+
+func (ob *SkipperObserver) GetRequestDurationContext(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
 
 	model = encodeModelForSkipper(model)
 
@@ -59,7 +68,7 @@ func (ob *SkipperObserver) GetRequestDuration(model flaggerv1.MetricTemplateMode
 	logger, _ := logger.NewLoggerWithEncoding("debug", "json")
 	logger.Debugf("GetRequestDuration: %s", query)
 
-	value, err := ob.client.RunQuery(query)
+	value, err := providers.RunQuery(ctx, ob.client, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
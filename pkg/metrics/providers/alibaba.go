@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// https://www.alibabacloud.com/help/doc-detail/29012.htm
+const (
+	alibabaAccessKeyIDSecretKey     = "alibaba_access_key_id"
+	alibabaAccessKeySecretSecretKey = "alibaba_access_key_secret"
+	alibabaSecurityTokenSecretKey   = "alibaba_security_token"
+
+	alibabaSecurityTokenHeaderKey = "x-acs-security-token"
+)
+
+// alibabaCredentials signs the requests to the Alibaba Cloud APIs with an AccessKey pair,
+// the security token is set when the AccessKey is a temporary STS credential
+type alibabaCredentials struct {
+	accessKeyID     string
+	accessKeySecret string
+	securityToken   string
+
+	// now returns the request date, it is replaced in tests
+	now func() time.Time
+}
+
+// newAlibabaCredentials returns the AccessKey found in the credentials map
+// or nil if the map doesn't contain an AccessKey ID
+func newAlibabaCredentials(credentials map[string][]byte) (*alibabaCredentials, error) {
+	id, ok := credentials[alibabaAccessKeyIDSecretKey]
+	if !ok {
+		return nil, nil
+	}
+	secret, ok := credentials[alibabaAccessKeySecretSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("alibaba credentials does not contain %s", alibabaAccessKeySecretSecretKey)
+	}
+
+	return &alibabaCredentials{
+		accessKeyID:     string(id),
+		accessKeySecret: string(secret),
+		securityToken:   string(credentials[alibabaSecurityTokenSecretKey]),
+		now:             time.Now,
+	}, nil
+}
+
+// signSLS sets the Log Service API signature headers
+func (c *alibabaCredentials) signSLS(req *http.Request) {
+	req.Header.Set("Date", c.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-log-apiversion", "0.6.0")
+	req.Header.Set("x-log-signaturemethod", "hmac-sha1")
+	req.Header.Set("x-log-bodyrawsize", "0")
+	if c.securityToken != "" {
+		req.Header.Set(alibabaSecurityTokenHeaderKey, c.securityToken)
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		canonicalizedAlibabaHeaders(req, "x-log-", "x-acs-") + canonicalizedAlibabaResource(req),
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("LOG %s:%s", c.accessKeyID, c.signature(stringToSign)))
+}
+
+// signROA sets the ROA style API signature headers used by the ARMS endpoints
+func (c *alibabaCredentials) signROA(req *http.Request) {
+	now := c.now().UTC()
+	req.Header.Set("Date", now.Format(http.TimeFormat))
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	req.Header.Set("x-acs-signature-method", "HMAC-SHA1")
+	req.Header.Set("x-acs-signature-version", "1.0")
+	req.Header.Set("x-acs-signature-nonce", strconv.FormatInt(now.UnixNano(), 10))
+	if c.securityToken != "" {
+		req.Header.Set(alibabaSecurityTokenHeaderKey, c.securityToken)
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Accept"),
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		canonicalizedAlibabaHeaders(req, "x-acs-") + canonicalizedAlibabaResource(req),
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("acs %s:%s", c.accessKeyID, c.signature(stringToSign)))
+}
+
+func (c *alibabaCredentials) signature(stringToSign string) string {
+	mac := hmac.New(sha1.New, []byte(c.accessKeySecret))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// canonicalizedAlibabaHeaders returns the sorted lowercase headers matching the prefixes, one per line
+func canonicalizedAlibabaHeaders(req *http.Request, prefixes ...string) string {
+	var keys []string
+	for k := range req.Header {
+		key := strings.ToLower(k)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(key + ":" + strings.TrimSpace(req.Header.Get(key)) + "\n")
+	}
+	return sb.String()
+}
+
+// canonicalizedAlibabaResource returns the path followed by the sorted and unescaped query parameters
+func canonicalizedAlibabaResource(req *http.Request) string {
+	params := req.URL.Query()
+	if len(params) == 0 {
+		return req.URL.Path
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+params.Get(k))
+	}
+	return req.URL.Path + "?" + strings.Join(pairs, "&")
+}
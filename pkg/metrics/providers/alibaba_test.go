@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlibabaCredentials_signSLS(t *testing.T) {
+	ac, err := newAlibabaCredentials(map[string][]byte{
+		alibabaAccessKeyIDSecretKey:     []byte("key-id"),
+		alibabaAccessKeySecretSecretKey: []byte("key-secret"),
+		alibabaSecurityTokenSecretKey:   []byte("sts-token"),
+	})
+	require.NoError(t, err)
+	ac.now = func() time.Time { return time.Date(2020, 9, 10, 8, 20, 0, 0, time.UTC) }
+
+	req, err := http.NewRequest("GET", "https://edge.cn-hangzhou.log.aliyuncs.com/logstores/access?type=log&query=%2A+%7C+select+1&from=1&to=2", nil)
+	require.NoError(t, err)
+	ac.signSLS(req)
+
+	stringToSign := "GET\n\n\nThu, 10 Sep 2020 08:20:00 GMT\n" +
+		"x-acs-security-token:sts-token\n" +
+		"x-log-apiversion:0.6.0\n" +
+		"x-log-bodyrawsize:0\n" +
+		"x-log-signaturemethod:hmac-sha1\n" +
+		"/logstores/access?from=1&query=* | select 1&to=2&type=log"
+	assert.Equal(t, "LOG key-id:"+testHMACSHA1("key-secret", stringToSign), req.Header.Get("Authorization"))
+	assert.Equal(t, "sts-token", req.Header.Get("x-acs-security-token"))
+}
+
+func TestAlibabaCredentials_signROA(t *testing.T) {
+	ac, err := newAlibabaCredentials(map[string][]byte{
+		alibabaAccessKeyIDSecretKey:     []byte("key-id"),
+		alibabaAccessKeySecretSecretKey: []byte("key-secret"),
+	})
+	require.NoError(t, err)
+	now := time.Date(2020, 9, 10, 8, 20, 0, 0, time.UTC)
+	ac.now = func() time.Time { return now }
+
+	req, err := http.NewRequest("GET", "https://arms.cn-hangzhou.aliyuncs.com/api/v1/query?query=vector%281%29", nil)
+	require.NoError(t, err)
+	ac.signROA(req)
+
+	stringToSign := "GET\napplication/json\n\n\nThu, 10 Sep 2020 08:20:00 GMT\n" +
+		"x-acs-signature-method:HMAC-SHA1\n" +
+		"x-acs-signature-nonce:1599726000000000000\n" +
+		"x-acs-signature-version:1.0\n" +
+		"/api/v1/query?query=vector(1)"
+	assert.Equal(t, "acs key-id:"+testHMACSHA1("key-secret", stringToSign), req.Header.Get("Authorization"))
+}
+
+func TestNewAlibabaCredentials(t *testing.T) {
+	ac, err := newAlibabaCredentials(map[string][]byte{"username": []byte("flagger")})
+	require.NoError(t, err)
+	assert.Nil(t, ac)
+
+	_, err = newAlibabaCredentials(map[string][]byte{alibabaAccessKeyIDSecretKey: []byte("key-id")})
+	require.Error(t, err)
+}
+
+func testHMACSHA1(secret string, stringToSign string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
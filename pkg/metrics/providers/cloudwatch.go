@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,8 +9,12 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	awscredentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/sts"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 )
@@ -17,6 +22,14 @@ import (
 const (
 	cloudWatchMaxRetries                           = 3
 	cloudWatchStartDeltaMultiplierOnMetricInterval = 10
+
+	cloudWatchAccessKeyIDSecretKey     = "aws_access_key_id"
+	cloudWatchSecretAccessKeySecretKey = "aws_secret_access_key"
+	cloudWatchSessionTokenSecretKey    = "aws_session_token"
+	cloudWatchRoleARNSecretKey         = "aws_role_arn"
+	cloudWatchExternalIDSecretKey      = "aws_external_id"
+
+	cloudWatchRoleSessionName = "flagger"
 )
 
 type CloudWatchProvider struct {
@@ -26,19 +39,35 @@ type CloudWatchProvider struct {
 
 // for the testing purpose
 type cloudWatchClient interface {
-	GetMetricData(input *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error)
+	GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput, opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// newCloudWatchAssumeRoler returns the STS client assuming the cross-account role,
+// for the testing purpose
+var newCloudWatchAssumeRoler = func(sess *session.Session) stscreds.AssumeRoler {
+	return sts.New(sess)
 }
 
 // NewCloudWatchProvider takes a metricInterval, a provider spec and the credentials map, and
-// returns a cloudWatchProvider ready to execute queries against the AWS CloudWatch metrics
-func NewCloudWatchProvider(metricInterval string, provider flaggerv1.MetricTemplateProvider) (*CloudWatchProvider, error) {
+// returns a cloudWatchProvider ready to execute queries against the AWS CloudWatch metrics,
+// the ambient credentials are used when the credentials map doesn't contain an access key
+func NewCloudWatchProvider(metricInterval string, provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*CloudWatchProvider, error) {
 	if provider.Region == "" {
 		return nil, fmt.Errorf("region not specified")
 	}
 
-	sess, err := session.NewSession(aws.NewConfig().
-		WithRegion(provider.Region).WithMaxRetries(cloudWatchMaxRetries))
+	config := aws.NewConfig().WithRegion(provider.Region).WithMaxRetries(cloudWatchMaxRetries)
+	if id, ok := credentials[cloudWatchAccessKeyIDSecretKey]; ok {
+		secret, ok := credentials[cloudWatchSecretAccessKeySecretKey]
+		if !ok {
+			return nil, fmt.Errorf("cloudwatch credentials does not contain %s", cloudWatchSecretAccessKeySecretKey)
+		}
+		config = config.WithCredentials(awscredentials.NewStaticCredentials(
+			string(id), string(secret), string(credentials[cloudWatchSessionTokenSecretKey])))
+	}
 
+	sess, err := session.NewSession(config)
 	if err != nil {
 		return nil, fmt.Errorf("error creating aws session: %s", err.Error())
 	}
@@ -48,15 +77,34 @@ func NewCloudWatchProvider(metricInterval string, provider flaggerv1.MetricTempl
 		return nil, fmt.Errorf("error parsing metric interval: %s", err.Error())
 	}
 
+	// assume the role of the account holding the metrics with the session credentials
+	var clientConfigs []*aws.Config
+	if roleARN, ok := credentials[cloudWatchRoleARNSecretKey]; ok {
+		externalID := credentials[cloudWatchExternalIDSecretKey]
+		roleCredentials := stscreds.NewCredentials(sess, string(roleARN), func(p *stscreds.AssumeRoleProvider) {
+			p.Client = newCloudWatchAssumeRoler(sess)
+			p.RoleSessionName = cloudWatchRoleSessionName
+			if len(externalID) > 0 {
+				p.ExternalID = aws.String(string(externalID))
+			}
+		})
+		clientConfigs = append(clientConfigs, aws.NewConfig().WithCredentials(roleCredentials))
+	}
+
 	return &CloudWatchProvider{
-		client:     cloudwatch.New(sess),
+		client:     cloudwatch.New(sess, clientConfigs...),
 		startDelta: cloudWatchStartDeltaMultiplierOnMetricInterval * md,
 	}, err
 }
 
-// RunQuery executes the aws cloud watch metrics query against GetMetricData endpoint
-// and returns the the first result as float64
+// RunQuery calls RunQueryContext with a background context
 func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext executes the aws cloud watch metrics query against GetMetricData endpoint
+// and returns the the first result as float64
+func (p *CloudWatchProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
 	var cq []*cloudwatch.MetricDataQuery
 	if err := json.Unmarshal([]byte(query), &cq); err != nil {
 		return 0, fmt.Errorf("error unmarshaling query: %s", err.Error())
@@ -64,7 +112,7 @@ func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
 
 	end := time.Now()
 	start := end.Add(-p.startDelta)
-	res, err := p.client.GetMetricData(&cloudwatch.GetMetricDataInput{
+	res, err := p.client.GetMetricDataWithContext(ctx, &cloudwatch.GetMetricDataInput{
 		EndTime:           aws.Time(end),
 		MaxDatapoints:     aws.Int64(20),
 		StartTime:         aws.Time(start),
@@ -88,12 +136,17 @@ func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
 	return aws.Float64Value(vs[0]), nil
 }
 
-// IsOnline calls GetMetricData endpoint with the empty query
+// IsOnline calls IsOnlineContext with a background context
+func (p *CloudWatchProvider) IsOnline() (bool, error) {
+	return p.IsOnlineContext(context.Background())
+}
+
+// IsOnlineContext calls GetMetricData endpoint with the empty query
 // and returns an error if the returned status code is NOT http.StatusBadRequests.
 // For example, if the flagger does not have permission to perform `cloudwatch:GetMetricData`,
 // the returned status code would be http.StatusForbidden
-func (p *CloudWatchProvider) IsOnline() (bool, error) {
-	_, err := p.client.GetMetricData(&cloudwatch.GetMetricDataInput{
+func (p *CloudWatchProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	_, err := p.client.GetMetricDataWithContext(ctx, &cloudwatch.GetMetricDataInput{
 		EndTime:           aws.Time(time.Time{}),
 		MetricDataQueries: []*cloudwatch.MetricDataQuery{},
 		StartTime:         aws.Time(time.Time{}),
@@ -8,8 +8,12 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/costandusagereportservice"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -21,7 +25,7 @@ type cloudWatchClientMock struct {
 	err error
 }
 
-func (c cloudWatchClientMock) GetMetricData(_ *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error) {
+func (c cloudWatchClientMock) GetMetricDataWithContext(_ aws.Context, _ *cloudwatch.GetMetricDataInput, _ ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
 	return c.o, c.err
 }
 
@@ -31,16 +35,76 @@ func TestNewCloudWatchProvider(t *testing.T) {
 			"5m",
 			flaggerv1.MetricTemplateProvider{
 				Region: costandusagereportservice.AWSRegionApEast1,
-			})
+			}, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 5*60*time.Second*cloudWatchStartDeltaMultiplierOnMetricInterval, p.startDelta)
 	})
 
 	t.Run("ng", func(t *testing.T) {
-		_, err := NewCloudWatchProvider("5m", flaggerv1.MetricTemplateProvider{})
+		_, err := NewCloudWatchProvider("5m", flaggerv1.MetricTemplateProvider{}, nil)
 		assert.Error(t, err, "error expected since region was not specified")
 	})
+
+	t.Run("access key", func(t *testing.T) {
+		p, err := NewCloudWatchProvider("5m", flaggerv1.MetricTemplateProvider{
+			Region: costandusagereportservice.AWSRegionApEast1,
+		}, map[string][]byte{
+			cloudWatchAccessKeyIDSecretKey:     []byte("key-id"),
+			cloudWatchSecretAccessKeySecretKey: []byte("secret"),
+			cloudWatchSessionTokenSecretKey:    []byte("token"),
+		})
+		require.NoError(t, err)
+
+		v, err := p.client.(*cloudwatch.CloudWatch).Config.Credentials.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "key-id", v.AccessKeyID)
+		assert.Equal(t, "secret", v.SecretAccessKey)
+		assert.Equal(t, "token", v.SessionToken)
+
+		_, err = NewCloudWatchProvider("5m", flaggerv1.MetricTemplateProvider{
+			Region: costandusagereportservice.AWSRegionApEast1,
+		}, map[string][]byte{cloudWatchAccessKeyIDSecretKey: []byte("key-id")})
+		assert.Error(t, err, "error expected since the secret access key was not specified")
+	})
+
+	t.Run("assume role", func(t *testing.T) {
+		roler := &assumeRolerMock{}
+		defer func(f func(sess *session.Session) stscreds.AssumeRoler) { newCloudWatchAssumeRoler = f }(newCloudWatchAssumeRoler)
+		newCloudWatchAssumeRoler = func(_ *session.Session) stscreds.AssumeRoler { return roler }
+
+		p, err := NewCloudWatchProvider("5m", flaggerv1.MetricTemplateProvider{
+			Region: costandusagereportservice.AWSRegionApEast1,
+		}, map[string][]byte{
+			cloudWatchAccessKeyIDSecretKey:     []byte("key-id"),
+			cloudWatchSecretAccessKeySecretKey: []byte("secret"),
+			cloudWatchRoleARNSecretKey:         []byte("arn:aws:iam::123456789012:role/flagger"),
+			cloudWatchExternalIDSecretKey:      []byte("external-id"),
+		})
+		require.NoError(t, err)
+
+		v, err := p.client.(*cloudwatch.CloudWatch).Config.Credentials.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "assumed-key-id", v.AccessKeyID)
+		require.NotNil(t, roler.input)
+		assert.Equal(t, "arn:aws:iam::123456789012:role/flagger", aws.StringValue(roler.input.RoleArn))
+		assert.Equal(t, "external-id", aws.StringValue(roler.input.ExternalId))
+		assert.Equal(t, cloudWatchRoleSessionName, aws.StringValue(roler.input.RoleSessionName))
+	})
+}
+
+type assumeRolerMock struct {
+	input *sts.AssumeRoleInput
+}
+
+func (m *assumeRolerMock) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	m.input = input
+	return &sts.AssumeRoleOutput{Credentials: &sts.Credentials{
+		AccessKeyId:     aws.String("assumed-key-id"),
+		SecretAccessKey: aws.String("assumed-secret"),
+		SessionToken:    aws.String("assumed-token"),
+		Expiration:      aws.Time(time.Now().Add(time.Hour)),
+	}}, nil
 }
 
 func TestCloudWatchProvider_IsOnline(t *testing.T) {
@@ -57,7 +57,7 @@ func NewDatadogProvider(metricInterval string,
 	}
 
 	dd := DatadogProvider{
-		timeout:                  5 * time.Second,
+		timeout:                  defaultTimeout,
 		metricsQueryEndpoint:     address + datadogMetricsQueryPath,
 		apiKeyValidationEndpoint: address + datadogAPIKeyValidationPath,
 	}
@@ -83,67 +83,107 @@ func NewDatadogProvider(metricInterval string,
 	return &dd, nil
 }
 
-// RunQuery executes the datadog query against DatadogProvider.metricsQueryEndpoint
-// and returns the the first result as float64
+// RunQuery calls RunQueryContext with a background context
 func (p *DatadogProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext executes the datadog query against DatadogProvider.metricsQueryEndpoint
+// and returns the the first result as float64
+func (p *DatadogProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
+	now := time.Now().Unix()
+	pl, err := p.query(ctx, query, now-p.fromDelta, now)
+	if err != nil {
+		return 0, err
+	}
+
+	vs := pl[len(pl)-1]
+	if len(vs) < 2 {
+		return 0, fmt.Errorf("invalid response: %v: %w", vs, ErrNoValuesFound)
+	}
+
+	return vs[1], nil
+}
+
+// RunRangeQuery executes the datadog query over the time range and returns the points of the first series as float64,
+// the resolution is chosen by Datadog based on the time range
+func (p *DatadogProvider) RunRangeQuery(ctx context.Context, query string, start time.Time, end time.Time, _ time.Duration) ([]float64, error) {
+	pl, err := p.query(ctx, query, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, 0, len(pl))
+	for _, vs := range pl {
+		if len(vs) > 1 {
+			values = append(values, vs[1])
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return values, nil
+}
 
-	req, err := http.NewRequest("GET", p.metricsQueryEndpoint, nil)
+// query executes the datadog query and returns the point list of the first series
+func (p *DatadogProvider) query(ctx context.Context, query string, from int64, to int64) ([][]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.metricsQueryEndpoint, nil)
 	if err != nil {
-		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+		return nil, fmt.Errorf("error http.NewRequest: %w", err)
 	}
 
 	req.Header.Set(datadogAPIKeyHeaderKey, p.apiKey)
 	req.Header.Set(datadogApplicationKeyHeaderKey, p.applicationKey)
-	now := time.Now().Unix()
 	q := req.URL.Query()
 	q.Add("query", query)
-	q.Add("from", strconv.FormatInt(now-p.fromDelta, 10))
-	q.Add("to", strconv.FormatInt(now, 10))
+	q.Add("from", strconv.FormatInt(from, 10))
+	q.Add("to", strconv.FormatInt(to, 10))
 	req.URL.RawQuery = q.Encode()
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
 	defer r.Body.Close()
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return 0, fmt.Errorf("error reading body: %w", err)
+		return nil, fmt.Errorf("error reading body: %w", err)
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("error response: %s: %w", string(b), err)
+		return nil, fmt.Errorf("error response: %s: %w", string(b), err)
 	}
 
 	var res datadogResponse
 	if err := json.Unmarshal(b, &res); err != nil {
-		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+		return nil, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
 	}
 
 	if len(res.Series) < 1 {
-		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+		return nil, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
 	}
 
 	pl := res.Series[0].Pointlist
 	if len(pl) < 1 {
-		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+		return nil, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
 	}
 
-	vs := pl[len(pl)-1]
-	if len(vs) < 1 {
-		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
-	}
+	return pl, nil
+}
 
-	return vs[1], nil
+// IsOnline calls IsOnlineContext with a background context
+func (p *DatadogProvider) IsOnline() (bool, error) {
+	return p.IsOnlineContext(context.Background())
 }
 
-// IsOnline calls the Datadog's validation endpoint with api keys
+// IsOnlineContext calls the Datadog's validation endpoint with api keys
 // and returns an error if the validation fails
-func (p *DatadogProvider) IsOnline() (bool, error) {
-	req, err := http.NewRequest("GET", p.apiKeyValidationEndpoint, nil)
+func (p *DatadogProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.apiKeyValidationEndpoint, nil)
 	if err != nil {
 		return false, fmt.Errorf("error http.NewRequest: %w", err)
 	}
@@ -151,7 +191,7 @@ func (p *DatadogProvider) IsOnline() (bool, error) {
 	req.Header.Add(datadogAPIKeyHeaderKey, p.apiKey)
 	req.Header.Add(datadogApplicationKeyHeaderKey, p.applicationKey)
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -98,6 +99,30 @@ func TestDatadogProvider_RunQuery(t *testing.T) {
 	})
 }
 
+func TestDatadogProvider_RunRangeQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1577232000", r.URL.Query().Get("from"))
+		assert.Equal(t, "1577404800", r.URL.Query().Get("to"))
+
+		json := `{"series": [{"pointlist": [[1577232000000,1.5],[1577318400000,2.5],[1577404800000,3.5]]}]}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	dp, err := NewDatadogProvider("1m",
+		flaggerv1.MetricTemplateProvider{Address: ts.URL},
+		map[string][]byte{
+			datadogApplicationKeySecretKey: []byte("app-key"),
+			datadogAPIKeySecretKey:         []byte("api-key"),
+		},
+	)
+	require.NoError(t, err)
+
+	values, err := dp.RunRangeQuery(context.TODO(), `avg:system.cpu.user{*}`, time.Unix(1577232000, 0), time.Unix(1577404800, 0), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.5, 2.5, 3.5}, values)
+}
+
 func TestDatadogProvider_IsOnline(t *testing.T) {
 	for _, c := range []struct {
 		code        int
@@ -0,0 +1,187 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/rest-apis.html
+const (
+	elasticsearchSearchPath = "/%s/_search"
+	elasticsearchCountPath  = "/%s/_count"
+	elasticsearchHealthPath = "/_cluster/health"
+
+	elasticsearchUsernameSecretKey = "username"
+	elasticsearchPasswordSecretKey = "password"
+	elasticsearchAPIKeySecretKey   = "elasticsearch_api_key"
+)
+
+// ElasticsearchProvider executes search and count queries against the Elasticsearch
+// or OpenSearch REST API
+type ElasticsearchProvider struct {
+	queryEndpoint  string
+	healthEndpoint string
+	jsonPath       string
+	parser         *jsonpath.JSONPath
+
+	timeout  time.Duration
+	username string
+	password string
+	apiKey   string
+}
+
+type elasticsearchCount struct {
+	Count *float64 `json:"count"`
+}
+
+type elasticsearchHealth struct {
+	ClusterName string `json:"cluster_name"`
+	Status      string `json:"status"`
+}
+
+// NewElasticsearchProvider takes a provider spec and the credentials map,
+// validates the address and the index pattern and returns an Elasticsearch client
+// ready to execute queries, the credentials are either basic auth or an API key
+func NewElasticsearchProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*ElasticsearchProvider, error) {
+	address := strings.TrimSuffix(provider.Address, "/")
+	if _, err := url.Parse(address); address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+	if provider.Elasticsearch == nil || provider.Elasticsearch.Index == "" {
+		return nil, fmt.Errorf("%s index is required", provider.Type)
+	}
+
+	es := ElasticsearchProvider{
+		timeout:        defaultTimeout,
+		healthEndpoint: address + elasticsearchHealthPath,
+		jsonPath:       provider.Elasticsearch.JSONPath,
+	}
+
+	index := provider.Elasticsearch.Index
+	if es.jsonPath == "" {
+		es.queryEndpoint = address + fmt.Sprintf(elasticsearchCountPath, index)
+	} else {
+		parser, err := ParseJSONPath(es.jsonPath)
+		if err != nil {
+			return nil, err
+		}
+		es.parser = parser
+		es.queryEndpoint = address + fmt.Sprintf(elasticsearchSearchPath, index)
+	}
+
+	if b, ok := credentials[elasticsearchAPIKeySecretKey]; ok {
+		es.apiKey = string(b)
+	} else if u, ok := credentials[elasticsearchUsernameSecretKey]; ok {
+		p, ok := credentials[elasticsearchPasswordSecretKey]
+		if !ok {
+			return nil, fmt.Errorf("elasticsearch credentials does not contain password")
+		}
+		es.username = string(u)
+		es.password = string(p)
+	}
+
+	return &es, nil
+}
+
+// RunQuery calls RunQueryContext with a background context
+func (p *ElasticsearchProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext sends the query as the request body and returns the document count
+// or the value selected by the JSONPath expression in the search response
+func (p *ElasticsearchProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", p.queryEndpoint, strings.NewReader(query))
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	b, err := p.do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.parser == nil {
+		var res elasticsearchCount
+		if err := json.Unmarshal(b, &res); err != nil {
+			return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+		}
+		if res.Count == nil {
+			return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+		}
+		return *res.Count, nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+	return extractJSONPath(p.parser, p.jsonPath, data, b)
+}
+
+// IsOnline calls IsOnlineContext with a background context
+func (p *ElasticsearchProvider) IsOnline() (bool, error) {
+	return p.IsOnlineContext(context.Background())
+}
+
+// IsOnlineContext calls the cluster health endpoint
+// and returns an error if the cluster status is red
+func (p *ElasticsearchProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.healthEndpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	b, err := p.do(req)
+	if err != nil {
+		return false, err
+	}
+
+	var health elasticsearchHealth
+	if err := json.Unmarshal(b, &health); err != nil {
+		return false, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+	if health.Status != "green" && health.Status != "yellow" {
+		return false, fmt.Errorf("elasticsearch cluster %s status %s", health.ClusterName, health.Status)
+	}
+
+	return true, nil
+}
+
+// do executes the authenticated request with the provider timeout and returns the response body
+func (p *ElasticsearchProvider) do(req *http.Request) ([]byte, error) {
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+p.apiKey)
+	} else if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+	return b, nil
+}
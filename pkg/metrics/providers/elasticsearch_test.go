@@ -0,0 +1,179 @@
+package providers
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewElasticsearchProvider(t *testing.T) {
+	ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Type:          "elasticsearch",
+		Address:       "http://elasticsearch:9200/",
+		Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{Index: "logs-*"},
+	}, map[string][]byte{elasticsearchAPIKeySecretKey: []byte("key")})
+	require.NoError(t, err)
+	assert.Equal(t, "http://elasticsearch:9200/logs-*/_count", ep.queryEndpoint)
+	assert.Equal(t, "http://elasticsearch:9200/_cluster/health", ep.healthEndpoint)
+	assert.Equal(t, "key", ep.apiKey)
+
+	ep, err = NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Type:          "elasticsearch",
+		Address:       "http://elasticsearch:9200",
+		Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{Index: "logs-*", JSONPath: ".hits.total.value"},
+	}, map[string][]byte{
+		elasticsearchUsernameSecretKey: []byte("flagger"),
+		elasticsearchPasswordSecretKey: []byte("secret"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http://elasticsearch:9200/logs-*/_search", ep.queryEndpoint)
+	assert.Equal(t, "flagger", ep.username)
+	assert.Equal(t, "secret", ep.password)
+
+	_, err = NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "elasticsearch",
+		Address: "http://elasticsearch:9200",
+	}, nil)
+	require.Error(t, err)
+
+	_, err = NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+		Type:          "elasticsearch",
+		Address:       "http://elasticsearch:9200",
+		Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{Index: "logs-*"},
+	}, map[string][]byte{elasticsearchUsernameSecretKey: []byte("flagger")})
+	require.Error(t, err)
+}
+
+func TestElasticsearchProvider_RunQuery(t *testing.T) {
+	eq := `{"query": {"bool": {"filter": [{"term": {"kubernetes.labels.app": "podinfo"}}, {"term": {"level": "error"}}]}}}`
+
+	t.Run("count", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/logs-*/_count", r.URL.Path)
+			assert.Equal(t, "ApiKey key", r.Header.Get("Authorization"))
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, eq, string(b))
+			w.Write([]byte(`{"count": 12, "_shards": {"total": 1, "successful": 1}}`))
+		}))
+		defer ts.Close()
+
+		ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:          "elasticsearch",
+			Address:       ts.URL,
+			Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{Index: "logs-*"},
+		}, map[string][]byte{elasticsearchAPIKeySecretKey: []byte("key")})
+		require.NoError(t, err)
+
+		f, err := ep.RunQuery(eq)
+		require.NoError(t, err)
+		assert.Equal(t, float64(12), f)
+	})
+
+	t.Run("aggregation", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/logs-*/_search", r.URL.Path)
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "flagger", username)
+			assert.Equal(t, "secret", password)
+			w.Write([]byte(`{"hits": {"total": {"value": 200}}, "aggregations": {"all": {"buckets": {"all": {"doc_count": 200, "ratio": {"value": 2.5}}}}}}`))
+		}))
+		defer ts.Close()
+
+		ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "elasticsearch",
+			Address: ts.URL,
+			Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{
+				Index:    "logs-*",
+				JSONPath: ".aggregations.all.buckets.all.ratio.value",
+			},
+		}, map[string][]byte{
+			elasticsearchUsernameSecretKey: []byte("flagger"),
+			elasticsearchPasswordSecretKey: []byte("secret"),
+		})
+		require.NoError(t, err)
+
+		f, err := ep.RunQuery(eq)
+		require.NoError(t, err)
+		assert.Equal(t, 2.5, f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"hits": {"total": {"value": 0}}, "aggregations": {"all": {"buckets": {"all": {"doc_count": 0, "ratio": {"value": null}}}}}}`))
+		}))
+		defer ts.Close()
+
+		ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "elasticsearch",
+			Address: ts.URL,
+			Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{
+				Index:    "logs-*",
+				JSONPath: ".aggregations.all.buckets.all.ratio.value",
+			},
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = ep.RunQuery(eq)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"type": "parsing_exception"}, "status": 400}`))
+		}))
+		defer ts.Close()
+
+		ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:          "elasticsearch",
+			Address:       ts.URL,
+			Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{Index: "logs-*"},
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = ep.RunQuery(eq)
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrNoValuesFound))
+	})
+}
+
+func TestElasticsearchProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		status      string
+		errExpected bool
+	}{
+		{status: "green", errExpected: false},
+		{status: "yellow", errExpected: false},
+		{status: "red", errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/_cluster/health", r.URL.Path)
+			w.Write([]byte(`{"cluster_name": "logs", "status": "` + c.status + `"}`))
+		}))
+
+		ep, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:          "elasticsearch",
+			Address:       ts.URL,
+			Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{Index: "logs-*"},
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = ep.IsOnline()
+		ts.Close()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package providers
 
 import (
+	"fmt"
+
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 )
 
@@ -17,8 +19,18 @@ func (factory Factory) Provider(
 	case "datadog":
 		return NewDatadogProvider(metricInterval, provider, credentials)
 	case "cloudwatch":
-		return NewCloudWatchProvider(metricInterval, provider)
+		return NewCloudWatchProvider(metricInterval, provider, credentials)
+	case "influxdb":
+		return NewInfluxDBProvider(provider, credentials)
+	case "web":
+		return NewWebProvider(provider, credentials)
+	case "elasticsearch":
+		return NewElasticsearchProvider(provider, credentials)
+	case "graphite":
+		return NewGraphiteProvider(metricInterval, provider, credentials)
+	case "sls":
+		return NewSLSProvider(metricInterval, provider, credentials)
 	default:
-		return NewPrometheusProvider(provider, credentials)
+		return nil, fmt.Errorf("provider %s not supported", provider.Type)
 	}
 }
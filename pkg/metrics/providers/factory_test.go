@@ -0,0 +1,30 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestFactory_Provider(t *testing.T) {
+	factory := Factory{}
+
+	p, err := factory.Provider("1m", flaggerv1.MetricTemplateProvider{Type: "influxdb", Address: "http://influxdb:8086"},
+		map[string][]byte{influxdbOrgSecretKey: []byte("edge"), influxdbTokenSecretKey: []byte("token")})
+	require.NoError(t, err)
+	assert.IsType(t, &InfluxDBProvider{}, p)
+
+	p, err = factory.Provider("1m", flaggerv1.MetricTemplateProvider{
+		Type:          "elasticsearch",
+		Address:       "http://elasticsearch:9200",
+		Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{Index: "logs-*"},
+	}, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &ElasticsearchProvider{}, p)
+
+	_, err = factory.Provider("1m", flaggerv1.MetricTemplateProvider{Type: "unknown", Address: "http://unknown"}, nil)
+	require.Error(t, err)
+}
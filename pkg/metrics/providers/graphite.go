@@ -0,0 +1,195 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://graphite.readthedocs.io/en/latest/render_api.html
+const (
+	graphiteRenderPath = "/render"
+
+	graphiteOnlineQuery = "constantLine(1)"
+)
+
+// Graphite series aggregations
+const (
+	GraphiteAggregationLast = "last"
+	GraphiteAggregationAvg  = "avg"
+	GraphiteAggregationMax  = "max"
+)
+
+// GraphiteAggregations are the supported series aggregations
+var GraphiteAggregations = []string{GraphiteAggregationLast, GraphiteAggregationAvg, GraphiteAggregationMax}
+
+// GraphiteProvider executes render queries against the Graphite API
+type GraphiteProvider struct {
+	renderEndpoint string
+
+	timeout     time.Duration
+	from        string
+	aggregation string
+	username    string
+	password    string
+}
+
+type graphiteResponse []struct {
+	Target     string       `json:"target"`
+	Datapoints [][]*float64 `json:"datapoints"`
+}
+
+// NewGraphiteProvider takes a metric interval, a provider spec and the credentials map,
+// validates the address and the aggregation and returns a Graphite client ready to
+// execute queries over the metric interval
+func NewGraphiteProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*GraphiteProvider, error) {
+	if _, err := url.Parse(provider.Address); provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	md, err := time.ParseDuration(metricInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	graphite := GraphiteProvider{
+		timeout:        defaultTimeout,
+		renderEndpoint: provider.Address + graphiteRenderPath,
+		from:           fmt.Sprintf("-%ds", int64(md.Seconds())),
+		aggregation:    GraphiteAggregationLast,
+	}
+
+	if provider.Graphite != nil && provider.Graphite.Aggregation != "" {
+		graphite.aggregation = provider.Graphite.Aggregation
+	}
+	switch graphite.aggregation {
+	case GraphiteAggregationLast, GraphiteAggregationAvg, GraphiteAggregationMax:
+	default:
+		return nil, fmt.Errorf("graphite aggregation %s not supported", graphite.aggregation)
+	}
+
+	if provider.SecretRef != nil {
+		if username, ok := credentials["username"]; ok {
+			graphite.username = string(username)
+		} else {
+			return nil, fmt.Errorf("%s credentials does not contain a username", provider.Type)
+		}
+
+		if password, ok := credentials["password"]; ok {
+			graphite.password = string(password)
+		} else {
+			return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
+		}
+	}
+
+	return &graphite, nil
+}
+
+// RunQuery calls RunQueryContext with a background context
+func (p *GraphiteProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext renders the target over the metric interval and returns
+// the aggregated value of the first series as float64
+func (p *GraphiteProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.renderEndpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	q := req.URL.Query()
+	q.Add("target", query)
+	q.Add("from", p.from)
+	q.Add("format", "json")
+	req.URL.RawQuery = q.Encode()
+
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error response: %s", string(b))
+	}
+
+	var res graphiteResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+	if len(res) < 1 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	var values []float64
+	for _, point := range res[0].Datapoints {
+		if len(point) > 0 && point[0] != nil {
+			values = append(values, *point[0])
+		}
+	}
+	if len(values) < 1 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	return aggregateGraphiteValues(p.aggregation, values), nil
+}
+
+// aggregateGraphiteValues reduces the non-null data points of a series to a single value
+func aggregateGraphiteValues(aggregation string, values []float64) float64 {
+	switch aggregation {
+	case GraphiteAggregationAvg:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	case GraphiteAggregationMax:
+		max := values[0]
+		for _, v := range values[1:] {
+			if v > max {
+				max = v
+			}
+		}
+		return max
+	default:
+		return values[len(values)-1]
+	}
+}
+
+// IsOnline calls IsOnlineContext with a background context
+func (p *GraphiteProvider) IsOnline() (bool, error) {
+	return p.IsOnlineContext(context.Background())
+}
+
+// IsOnlineContext runs a constant line query and returns an error if the API is unreachable
+func (p *GraphiteProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	value, err := p.RunQueryContext(ctx, graphiteOnlineQuery)
+	if err != nil {
+		return false, fmt.Errorf("running query failed: %w", err)
+	}
+
+	if value != float64(1) {
+		return false, fmt.Errorf("value is not 1 for query: %s", graphiteOnlineQuery)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewGraphiteProvider(t *testing.T) {
+	gp, err := NewGraphiteProvider("2m", flaggerv1.MetricTemplateProvider{
+		Type:      "graphite",
+		Address:   "http://graphite",
+		SecretRef: &corev1.LocalObjectReference{Name: "graphite"},
+	}, map[string][]byte{"username": []byte("flagger"), "password": []byte("secret")})
+	require.NoError(t, err)
+	assert.Equal(t, "http://graphite/render", gp.renderEndpoint)
+	assert.Equal(t, "-120s", gp.from)
+	assert.Equal(t, GraphiteAggregationLast, gp.aggregation)
+	assert.Equal(t, "flagger", gp.username)
+	assert.Equal(t, "secret", gp.password)
+
+	_, err = NewGraphiteProvider("2m", flaggerv1.MetricTemplateProvider{
+		Type:      "graphite",
+		Address:   "http://graphite",
+		SecretRef: &corev1.LocalObjectReference{Name: "graphite"},
+	}, map[string][]byte{"username": []byte("flagger")})
+	require.Error(t, err)
+
+	_, err = NewGraphiteProvider("2m", flaggerv1.MetricTemplateProvider{
+		Type:     "graphite",
+		Address:  "http://graphite",
+		Graphite: &flaggerv1.MetricTemplateGraphiteRequest{Aggregation: "median"},
+	}, nil)
+	require.Error(t, err)
+
+	_, err = NewGraphiteProvider("2m", flaggerv1.MetricTemplateProvider{Type: "graphite"}, nil)
+	require.Error(t, err)
+}
+
+func TestGraphiteProvider_RunQuery(t *testing.T) {
+	eq := `sumSeries(stats.timers.podinfo.errors.count)`
+	series := `[{"target": "sumSeries(stats.timers.podinfo.errors.count)", "datapoints": [[1, 1600000000], [5, 1600000010], [3, 1600000020], [null, 1600000030]]}]`
+
+	for aggregation, expected := range map[string]float64{
+		"":                      3,
+		GraphiteAggregationLast: 3,
+		GraphiteAggregationAvg:  3,
+		GraphiteAggregationMax:  5,
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/render", r.URL.Path)
+			assert.Equal(t, eq, r.URL.Query().Get("target"))
+			assert.Equal(t, "-60s", r.URL.Query().Get("from"))
+			assert.Equal(t, "json", r.URL.Query().Get("format"))
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "flagger", username)
+			assert.Equal(t, "secret", password)
+			w.Write([]byte(series))
+		}))
+
+		gp, err := NewGraphiteProvider("1m", flaggerv1.MetricTemplateProvider{
+			Type:      "graphite",
+			Address:   ts.URL,
+			SecretRef: &corev1.LocalObjectReference{Name: "graphite"},
+			Graphite:  &flaggerv1.MetricTemplateGraphiteRequest{Aggregation: aggregation},
+		}, map[string][]byte{"username": []byte("flagger"), "password": []byte("secret")})
+		require.NoError(t, err)
+
+		f, err := gp.RunQuery(eq)
+		ts.Close()
+		require.NoError(t, err)
+		assert.Equal(t, expected, f, aggregation)
+	}
+
+	for name, body := range map[string]string{
+		"no series": `[]`,
+		"no values": `[{"target": "errors", "datapoints": [[null, 1600000000], [null, 1600000010]]}]`,
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+
+		gp, err := NewGraphiteProvider("1m", flaggerv1.MetricTemplateProvider{Type: "graphite", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = gp.RunQuery(eq)
+		ts.Close()
+		require.True(t, errors.Is(err, ErrNoValuesFound), name)
+	}
+}
+
+func TestGraphiteProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusUnauthorized, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, graphiteOnlineQuery, r.URL.Query().Get("target"))
+			w.WriteHeader(c.code)
+			w.Write([]byte(`[{"target": "1", "datapoints": [[1, 1600000000], [1, 1600000060]]}]`))
+		}))
+
+		gp, err := NewGraphiteProvider("1m", flaggerv1.MetricTemplateProvider{Type: "graphite", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = gp.IsOnline()
+		ts.Close()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
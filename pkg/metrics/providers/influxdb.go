@@ -0,0 +1,210 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://docs.influxdata.com/influxdb/v2.0/api/
+const (
+	influxdbQueryPath  = "/api/v2/query"
+	influxdbHealthPath = "/health"
+
+	influxdbOrgSecretKey   = "influxdb_org"
+	influxdbTokenSecretKey = "influxdb_token"
+
+	influxdbValueColumn = "_value"
+	influxdbErrorColumn = "error"
+)
+
+// InfluxDBProvider executes Flux queries against the InfluxDB 2.x API
+type InfluxDBProvider struct {
+	queryEndpoint  string
+	healthEndpoint string
+
+	timeout time.Duration
+	org     string
+	token   string
+}
+
+type influxdbQuery struct {
+	Query string `json:"query"`
+	Type  string `json:"type"`
+}
+
+type influxdbHealth struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// NewInfluxDBProvider takes a provider spec and the credentials map,
+// validates the address, extracts the organization and the token and
+// returns an InfluxDB client ready to execute queries against the API
+func NewInfluxDBProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*InfluxDBProvider, error) {
+	if _, err := url.Parse(provider.Address); provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	influx := InfluxDBProvider{
+		timeout:        defaultTimeout,
+		queryEndpoint:  provider.Address + influxdbQueryPath,
+		healthEndpoint: provider.Address + influxdbHealthPath,
+	}
+
+	if b, ok := credentials[influxdbOrgSecretKey]; ok {
+		influx.org = string(b)
+	} else {
+		return nil, fmt.Errorf("influxdb credentials does not contain influxdb_org")
+	}
+
+	if b, ok := credentials[influxdbTokenSecretKey]; ok {
+		influx.token = string(b)
+	} else {
+		return nil, fmt.Errorf("influxdb credentials does not contain influxdb_token")
+	}
+
+	return &influx, nil
+}
+
+// RunQuery calls RunQueryContext with a background context
+func (p *InfluxDBProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext executes the Flux query against InfluxDBProvider.queryEndpoint
+// and returns the value of the first record as float64
+func (p *InfluxDBProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
+	body, err := json.Marshal(influxdbQuery{Query: query, Type: "flux"})
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.queryEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Token "+p.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/csv")
+	q := req.URL.Query()
+	q.Add("org", p.org)
+	req.URL.RawQuery = q.Encode()
+
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return parseInfluxDBResult(b)
+}
+
+// parseInfluxDBResult returns the value of the first record of the annotation-less CSV result
+func parseInfluxDBResult(b []byte) (float64, error) {
+	reader := csv.NewReader(bytes.NewReader(b))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error parsing result: %w, '%s'", err, string(b))
+	}
+
+	valueIndex, errorIndex := -1, -1
+	for i, column := range header {
+		switch column {
+		case influxdbValueColumn:
+			valueIndex = i
+		case influxdbErrorColumn:
+			errorIndex = i
+		}
+	}
+
+	record, err := reader.Read()
+	if err == io.EOF {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error parsing result: %w, '%s'", err, string(b))
+	}
+
+	// errors raised while streaming the result are returned as a table with an error column
+	if errorIndex >= 0 && errorIndex < len(record) {
+		return 0, fmt.Errorf("error response: %s", record[errorIndex])
+	}
+	if valueIndex < 0 || valueIndex >= len(record) || record[valueIndex] == "" {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	value, err := strconv.ParseFloat(record[valueIndex], 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing value %s: %w", record[valueIndex], err)
+	}
+	return value, nil
+}
+
+// IsOnline calls IsOnlineContext with a background context
+func (p *InfluxDBProvider) IsOnline() (bool, error) {
+	return p.IsOnlineContext(context.Background())
+}
+
+// IsOnlineContext calls the InfluxDB health endpoint
+// and returns an error if the instance is not healthy
+func (p *InfluxDBProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.healthEndpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return false, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("error response: %s", string(b))
+	}
+
+	var health influxdbHealth
+	if err := json.Unmarshal(b, &health); err != nil {
+		return false, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+	if health.Status != "pass" {
+		return false, fmt.Errorf("influxdb status %s: %s", health.Status, health.Message)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewInfluxDBProvider(t *testing.T) {
+	cs := map[string][]byte{
+		influxdbOrgSecretKey:   []byte("edge"),
+		influxdbTokenSecretKey: []byte("token"),
+	}
+
+	ip, err := NewInfluxDBProvider(flaggerv1.MetricTemplateProvider{Type: "influxdb", Address: "http://influxdb:8086"}, cs)
+	require.NoError(t, err)
+	assert.Equal(t, "http://influxdb:8086/api/v2/query", ip.queryEndpoint)
+	assert.Equal(t, "http://influxdb:8086/health", ip.healthEndpoint)
+	assert.Equal(t, "edge", ip.org)
+	assert.Equal(t, "token", ip.token)
+
+	_, err = NewInfluxDBProvider(flaggerv1.MetricTemplateProvider{Type: "influxdb"}, cs)
+	require.Error(t, err)
+
+	_, err = NewInfluxDBProvider(flaggerv1.MetricTemplateProvider{Type: "influxdb", Address: "http://influxdb:8086"},
+		map[string][]byte{influxdbOrgSecretKey: []byte("edge")})
+	require.Error(t, err)
+}
+
+func TestInfluxDBProvider_RunQuery(t *testing.T) {
+	cs := map[string][]byte{
+		influxdbOrgSecretKey:   []byte("edge"),
+		influxdbTokenSecretKey: []byte("token"),
+	}
+	eq := `from(bucket: "edge") |> range(start: -1m) |> mean()`
+
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/api/v2/query", r.URL.Path)
+			assert.Equal(t, "edge", r.URL.Query().Get("org"))
+			assert.Equal(t, "Token token", r.Header.Get("Authorization"))
+
+			var q influxdbQuery
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&q))
+			assert.Equal(t, eq, q.Query)
+			assert.Equal(t, "flux", q.Type)
+
+			csv := ",result,table,_start,_stop,_value,_field\r\n" +
+				",_result,0,2020-09-10T08:20:00Z,2020-09-10T08:21:00Z,99.5,success\r\n" +
+				",_result,1,2020-09-10T08:20:00Z,2020-09-10T08:21:00Z,42,success\r\n\r\n"
+			w.Write([]byte(csv))
+		}))
+		defer ts.Close()
+
+		ip, err := NewInfluxDBProvider(flaggerv1.MetricTemplateProvider{Type: "influxdb", Address: ts.URL}, cs)
+		require.NoError(t, err)
+
+		f, err := ip.RunQuery(eq)
+		require.NoError(t, err)
+		assert.Equal(t, 99.5, f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("\r\n"))
+		}))
+		defer ts.Close()
+
+		ip, err := NewInfluxDBProvider(flaggerv1.MetricTemplateProvider{Type: "influxdb", Address: ts.URL}, cs)
+		require.NoError(t, err)
+
+		_, err = ip.RunQuery(eq)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(",error,reference\r\n,bucket not found,\r\n"))
+		}))
+		defer ts.Close()
+
+		ip, err := NewInfluxDBProvider(flaggerv1.MetricTemplateProvider{Type: "influxdb", Address: ts.URL}, cs)
+		require.NoError(t, err)
+
+		_, err = ip.RunQuery(eq)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bucket not found")
+		assert.False(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":"unauthorized","message":"unauthorized access"}`))
+		}))
+		defer ts.Close()
+
+		ip, err := NewInfluxDBProvider(flaggerv1.MetricTemplateProvider{Type: "influxdb", Address: ts.URL}, cs)
+		require.NoError(t, err)
+
+		_, err = ip.RunQuery(eq)
+		require.Error(t, err)
+	})
+}
+
+func TestInfluxDBProvider_IsOnline(t *testing.T) {
+	cs := map[string][]byte{
+		influxdbOrgSecretKey:   []byte("edge"),
+		influxdbTokenSecretKey: []byte("token"),
+	}
+
+	for _, c := range []struct {
+		code        int
+		body        string
+		errExpected bool
+	}{
+		{code: http.StatusOK, body: `{"name":"influxdb","status":"pass"}`, errExpected: false},
+		{code: http.StatusServiceUnavailable, body: `{"name":"influxdb","status":"fail"}`, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/health", r.URL.Path)
+			w.WriteHeader(c.code)
+			w.Write([]byte(c.body))
+		}))
+
+		ip, err := NewInfluxDBProvider(flaggerv1.MetricTemplateProvider{Type: "influxdb", Address: ts.URL}, cs)
+		require.NoError(t, err)
+
+		_, err = ip.IsOnline()
+		ts.Close()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
@@ -2,20 +2,58 @@ package providers
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
 )
 
-const prometheusOnlineQuery = "vector(1)"
+const (
+	prometheusOnlineQuery = "vector(1)"
+
+	prometheusUsernameSecretKey = "username"
+	prometheusPasswordSecretKey = "password"
+	prometheusTokenSecretKey    = "token"
+	prometheusCASecretKey       = "ca.crt"
+	prometheusCertSecretKey     = "tls.crt"
+	prometheusKeySecretKey      = "tls.key"
+
+	// prometheusHeaderSecretKeyPrefix prefixes the secret keys sent as request headers,
+	// e.g. header.X-Scope-OrgID
+	prometheusHeaderSecretKeyPrefix = "header."
+
+	// prometheusTLSClientsMax is the maximum number of TLS clients kept for reuse,
+	// the least recently used client is evicted when a new TLS setting is added
+	prometheusTLSClientsMax = 64
+)
+
+// prometheusTLSClients holds the TLS clients by hash of their CA and client certificate,
+// the providers rebuilt for a new template or secret version reuse the transport and its connections
+var prometheusTLSClients = tlsClientCache{clients: make(map[string]*tlsClient)}
+
+type tlsClientCache struct {
+	mu      sync.Mutex
+	clients map[string]*tlsClient
+}
+
+type tlsClient struct {
+	client *http.Client
+	used   time.Time
+}
 
 // PrometheusProvider executes promQL queries
 type PrometheusProvider struct {
@@ -23,6 +61,12 @@ type PrometheusProvider struct {
 	url      url.URL
 	username string
 	password string
+	token    string
+	headers  map[string]string
+	client   *http.Client
+
+	// alibaba signs the requests to the ARMS Prometheus endpoints
+	alibaba *alibabaCredentials
 }
 
 type prometheusResponse struct {
@@ -36,9 +80,17 @@ type prometheusResponse struct {
 	}
 }
 
+type prometheusRangeResponse struct {
+	Data struct {
+		Result []struct {
+			Values [][]interface{} `json:"values"`
+		}
+	}
+}
+
 // NewPrometheusProvider takes a provider spec and the credentials map,
-// validates the address, extracts the username and password values if provided and
-// returns a Prometheus client ready to execute queries against the API
+// validates the address, extracts the Alibaba Cloud AccessKey, the username and password, the bearer token,
+// the headers and the TLS certificates if provided and returns a Prometheus client ready to execute queries against the API
 func NewPrometheusProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*PrometheusProvider, error) {
 	promURL, err := url.Parse(provider.Address)
 	if provider.Address == "" || err != nil {
@@ -46,63 +98,150 @@ func NewPrometheusProvider(provider flaggerv1.MetricTemplateProvider, credential
 	}
 
 	prom := PrometheusProvider{
-		timeout: 5 * time.Second,
+		timeout: defaultTimeout,
 		url:     *promURL,
+		client:  http.DefaultClient,
 	}
 
-	if provider.SecretRef != nil {
-		if username, ok := credentials["username"]; ok {
-			prom.username = string(username)
-		} else {
-			return nil, fmt.Errorf("%s credentials does not contain a username", provider.Type)
-		}
+	if provider.SecretRef == nil {
+		return &prom, nil
+	}
 
-		if password, ok := credentials["password"]; ok {
-			prom.password = string(password)
-		} else {
-			return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
-		}
+	ac, err := newAlibabaCredentials(credentials)
+	if err != nil {
+		return nil, err
 	}
+	prom.alibaba = ac
 
-	return &prom, nil
-}
+	username, hasUsername := credentials[prometheusUsernameSecretKey]
+	password, hasPassword := credentials[prometheusPasswordSecretKey]
+	if hasUsername && !hasPassword {
+		return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
+	}
+	if hasPassword && !hasUsername {
+		return nil, fmt.Errorf("%s credentials does not contain a username", provider.Type)
+	}
+	prom.username = string(username)
+	prom.password = string(password)
 
-// RunQuery executes the promQL query and returns the the first result as float64
-func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
-	query = url.QueryEscape(p.trimQuery(query))
-	u, err := url.Parse(fmt.Sprintf("./api/v1/query?query=%s", query))
-	if err != nil {
-		return 0, fmt.Errorf("url.Parase failed: %w", err)
+	if token, ok := credentials[prometheusTokenSecretKey]; ok {
+		prom.token = strings.TrimSpace(string(token))
 	}
-	u.Path = path.Join(p.url.Path, u.Path)
 
-	u = p.url.ResolveReference(u)
+	for k, v := range credentials {
+		if name := strings.TrimPrefix(k, prometheusHeaderSecretKeyPrefix); name != k && name != "" {
+			if prom.headers == nil {
+				prom.headers = make(map[string]string)
+			}
+			prom.headers[name] = string(v)
+		}
+	}
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	client, err := prometheusTLSClient(credentials)
 	if err != nil {
-		return 0, fmt.Errorf("http.NewRequest failed: %w", err)
+		return nil, fmt.Errorf("%s credentials TLS error: %w", provider.Type, err)
+	}
+	if client != nil {
+		prom.client = client
 	}
 
-	if p.username != "" && p.password != "" {
-		req.SetBasicAuth(p.username, p.password)
+	if prom.alibaba == nil && prom.username == "" && prom.token == "" && len(prom.headers) == 0 && client == nil {
+		return nil, fmt.Errorf("%s credentials does not contain a username and password, a token, headers or TLS certificates",
+			provider.Type)
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
-	defer cancel()
+	return &prom, nil
+}
 
-	r, err := http.DefaultClient.Do(req.WithContext(ctx))
-	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+// prometheusTLSClient returns an HTTP client trusting the CA and presenting the client certificate
+// found in the credentials, or nil if the credentials don't contain TLS settings,
+// the client is shared by the providers built with the same TLS settings
+func prometheusTLSClient(credentials map[string][]byte) (*http.Client, error) {
+	ca, hasCA := credentials[prometheusCASecretKey]
+	cert, hasCert := credentials[prometheusCertSecretKey]
+	key, hasKey := credentials[prometheusKeySecretKey]
+	if !hasCA && !hasCert && !hasKey {
+		return nil, nil
+	}
+	if hasCert != hasKey {
+		return nil, fmt.Errorf("%s and %s must be set together", prometheusCertSecretKey, prometheusKeySecretKey)
 	}
-	defer r.Body.Close()
 
-	b, err := ioutil.ReadAll(r.Body)
+	hash := sha256.New()
+	for _, k := range []string{prometheusCASecretKey, prometheusCertSecretKey, prometheusKeySecretKey} {
+		v, ok := credentials[k]
+		fmt.Fprintf(hash, "%s:%t:%d:", k, ok, len(v))
+		hash.Write(v)
+	}
+	return prometheusTLSClients.get(hex.EncodeToString(hash.Sum(nil)), func() (*http.Client, error) {
+		return newPrometheusTLSClient(ca, hasCA, cert, hasCert, key)
+	})
+}
+
+// get returns the client of the TLS settings hash or builds it,
+// the least recently used client is evicted and its idle connections closed when the cache is full
+func (c *tlsClientCache) get(hash string, build func() (*http.Client, error)) (*http.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.clients[hash]; ok {
+		cached.used = time.Now()
+		return cached.client, nil
+	}
+
+	client, err := build()
 	if err != nil {
-		return 0, fmt.Errorf("error reading body: %w", err)
+		return nil, err
 	}
 
-	if 400 <= r.StatusCode {
-		return 0, fmt.Errorf("error response: %s", string(b))
+	if len(c.clients) >= prometheusTLSClientsMax {
+		var oldest string
+		for k, v := range c.clients {
+			if oldest == "" || v.used.Before(c.clients[oldest].used) {
+				oldest = k
+			}
+		}
+		c.clients[oldest].client.CloseIdleConnections()
+		delete(c.clients, oldest)
+	}
+	c.clients[hash] = &tlsClient{client: client, used: time.Now()}
+	return client, nil
+}
+
+// newPrometheusTLSClient builds a client with its own transport for the CA and client certificate
+func newPrometheusTLSClient(ca []byte, hasCA bool, cert []byte, hasCert bool, key []byte) (*http.Client, error) {
+	tlsConfig := &tls.Config{}
+	if hasCA {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("%s does not contain a PEM encoded certificate", prometheusCASecretKey)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if hasCert {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+// RunQuery calls RunQueryContext with a background context
+func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext executes the promQL query and returns the the first result as float64
+func (p *PrometheusProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
+	query = url.QueryEscape(p.trimQuery(query))
+	b, err := p.get(ctx, fmt.Sprintf("./api/v1/query?query=%s", query))
+	if err != nil {
+		return 0, err
 	}
 
 	var result prometheusResponse
@@ -130,9 +269,107 @@ func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
 	return *value, nil
 }
 
-// IsOnline run simple Prometheus query and returns an error if the API is unreachable
+// RunRangeQuery executes the promQL range query and returns the values of the first series as float64,
+// the NaN values are skipped
+func (p *PrometheusProvider) RunRangeQuery(ctx context.Context, query string, start time.Time, end time.Time, step time.Duration) ([]float64, error) {
+	params := url.Values{}
+	params.Set("query", p.trimQuery(query))
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	b, err := p.get(ctx, "./api/v1/query_range?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var result prometheusRangeResponse
+	err = json.Unmarshal(b, &result)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	var values []float64
+	if len(result.Data.Result) > 0 {
+		for _, v := range result.Data.Result[0].Values {
+			if len(v) < 2 {
+				continue
+			}
+			s, ok := v[1].(string)
+			if !ok {
+				continue
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, err
+			}
+			if math.IsNaN(f) {
+				continue
+			}
+			values = append(values, f)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return values, nil
+}
+
+// get calls the API path relative to the provider address and returns the response body
+func (p *PrometheusProvider) get(ctx context.Context, apiPath string) ([]byte, error) {
+	u, err := url.Parse(apiPath)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parase failed: %w", err)
+	}
+	u.Path = path.Join(p.url.Path, u.Path)
+
+	u = p.url.ResolveReference(u)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	if p.alibaba != nil {
+		p.alibaba.signROA(req)
+	} else if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	} else if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+
+	return b, nil
+}
+
+// IsOnline calls IsOnlineContext with a background context
 func (p *PrometheusProvider) IsOnline() (bool, error) {
-	value, err := p.RunQuery(prometheusOnlineQuery)
+	return p.IsOnlineContext(context.Background())
+}
+
+// IsOnlineContext run simple Prometheus query and returns an error if the API is unreachable
+func (p *PrometheusProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	value, err := p.RunQueryContext(ctx, prometheusOnlineQuery)
 	if err != nil {
 		return false, fmt.Errorf("running query failed: %w", err)
 	}
@@ -2,11 +2,16 @@ package providers
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -143,6 +148,209 @@ func TestPrometheusProvider_RunQueryWithBasicAuth(t *testing.T) {
 	})
 }
 
+func TestPrometheusProvider_RunQueryWithAlibabaAccessKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "acs key-id:"))
+		assert.Equal(t, "HMAC-SHA1", r.Header.Get("x-acs-signature-method"))
+		assert.NotEmpty(t, r.Header.Get("Date"))
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "arms"},
+	}, map[string][]byte{
+		alibabaAccessKeyIDSecretKey:     []byte("key-id"),
+		alibabaAccessKeySecretSecretKey: []byte("key-secret"),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, prom.alibaba)
+
+	val, err := prom.RunQuery("sum(arms_http_requests_count)")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), val)
+
+	_, err = NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "arms"},
+	}, map[string][]byte{alibabaAccessKeyIDSecretKey: []byte("key-id")})
+	require.Error(t, err)
+}
+
+func TestPrometheusProvider_RunQueryWithTokenAndHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "tenant-a", r.Header.Get("X-Scope-OrgID"))
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "thanos"},
+	}, map[string][]byte{
+		"token":                []byte("token\n"),
+		"header.X-Scope-OrgID": []byte("tenant-a"),
+	})
+	require.NoError(t, err)
+
+	val, err := prom.RunQuery("sum(envoy_cluster_upstream_rq)")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), val)
+
+	_, err = NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "thanos"},
+	}, map[string][]byte{"username": []byte("username")})
+	require.Error(t, err)
+
+	_, err = NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "thanos"},
+	}, map[string][]byte{})
+	require.Error(t, err)
+}
+
+func TestPrometheusProvider_RunQueryWithTLS(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Len(t, r.TLS.PeerCertificates, 1)
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	serverCert := ts.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Certificate[0]})
+	credentials := map[string][]byte{
+		"ca.crt":  certPEM,
+		"tls.crt": certPEM,
+		"tls.key": pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}),
+	}
+	provider := flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "cortex"},
+	}
+
+	prom, err := NewPrometheusProvider(provider, credentials)
+	require.NoError(t, err)
+
+	val, err := prom.RunQuery("sum(envoy_cluster_upstream_rq)")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), val)
+
+	// the TLS client is shared by the providers built with the same TLS settings
+	assert.NotSame(t, http.DefaultClient, prom.client)
+	rebuilt, err := NewPrometheusProvider(provider, credentials)
+	require.NoError(t, err)
+	assert.Same(t, prom.client, rebuilt.client)
+	caOnly, err := NewPrometheusProvider(provider, map[string][]byte{"ca.crt": certPEM})
+	require.NoError(t, err)
+	assert.NotSame(t, prom.client, caOnly.client)
+
+	_, err = NewPrometheusProvider(provider, map[string][]byte{"tls.crt": certPEM})
+	require.Error(t, err)
+
+	_, err = NewPrometheusProvider(provider, map[string][]byte{"ca.crt": []byte("not a certificate")})
+	require.Error(t, err)
+}
+
+func TestPrometheusTLSClients(t *testing.T) {
+	cache := tlsClientCache{clients: make(map[string]*tlsClient)}
+	build := func() (*http.Client, error) {
+		return &http.Client{Transport: &http.Transport{}}, nil
+	}
+
+	first, err := cache.get("first", build)
+	require.NoError(t, err)
+	for i := 1; i < prometheusTLSClientsMax; i++ {
+		_, err := cache.get(strconv.Itoa(i), build)
+		require.NoError(t, err)
+	}
+	cached, err := cache.get("first", build)
+	require.NoError(t, err)
+	assert.Same(t, first, cached)
+
+	// the least recently used client is evicted
+	_, err = cache.get("new", build)
+	require.NoError(t, err)
+	assert.Len(t, cache.clients, prometheusTLSClientsMax)
+	assert.Contains(t, cache.clients, "first")
+	assert.NotContains(t, cache.clients, "1")
+}
+
+func TestPrometheusProvider_RunQueryContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// hang until the client gives up
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	_, err = prom.RunQueryContext(ctx, "vector(1)")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, int64(time.Since(begin)), int64(prom.timeout))
+}
+
+func TestPrometheusProvider_RunRangeQuery(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/query_range", r.URL.Path)
+			assert.Equal(t, "sum(envoy_cluster_upstream_rq)", r.URL.Query().Get("query"))
+			assert.Equal(t, "1545905200", r.URL.Query().Get("start"))
+			assert.Equal(t, "1545905260", r.URL.Query().Get("end"))
+			assert.Equal(t, "15", r.URL.Query().Get("step"))
+
+			json := `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1545905200,"100"],[1545905215,"NaN"],[1545905230,"98.5"]]}]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		values, err := prom.RunRangeQuery(context.TODO(), "sum(envoy_cluster_upstream_rq)",
+			time.Unix(1545905200, 0), time.Unix(1545905260, 0), 15*time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, []float64{100, 98.5}, values)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json := `{"status":"success","data":{"resultType":"matrix","result":[]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = prom.RunRangeQuery(context.TODO(), "sum(envoy_cluster_upstream_rq)", time.Now().Add(-time.Minute), time.Now(), 15*time.Second)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+}
+
 func TestPrometheusProvider_IsOnline(t *testing.T) {
 	t.Run("fail", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1,5 +1,21 @@
 package providers
 
+import (
+	"context"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// defaultTimeout bounds the provider requests whose context has no deadline
+const defaultTimeout = 5 * time.Second
+
+// maxDetachedCalls caps the calls of the providers that don't implement ContextInterface
+// still running in the background, the new calls wait for a slot within their context deadline
+const maxDetachedCalls = 32
+
+var detachedCalls = make(chan struct{}, maxDetachedCalls)
+
 type Interface interface {
 	// RunQuery executes the query and converts the first result to float64
 	RunQuery(query string) (float64, error)
@@ -7,3 +23,93 @@ type Interface interface {
 	// IsOnline calls the provider endpoint and returns an error if the API is unreachable
 	IsOnline() (bool, error)
 }
+
+// ContextInterface is implemented by the providers whose requests are cancelled when the context is done
+type ContextInterface interface {
+	Interface
+
+	// RunQueryContext executes the query within the context deadline and converts the first result to float64
+	RunQueryContext(ctx context.Context, query string) (float64, error)
+
+	// IsOnlineContext calls the provider endpoint within the context deadline
+	// and returns an error if the API is unreachable
+	IsOnlineContext(ctx context.Context) (bool, error)
+}
+
+// RangeInterface is implemented by the providers able to return the time series of a query
+type RangeInterface interface {
+	// RunRangeQuery executes the query over the time range with the given resolution
+	// and converts the values of the first series to float64
+	RunRangeQuery(ctx context.Context, query string, start time.Time, end time.Time, step time.Duration) ([]float64, error)
+}
+
+// ModelInterface is implemented by the providers whose request is templated beyond the query
+type ModelInterface interface {
+	// RunModelQuery renders the provider request with the model, executes the query
+	// and converts the result to float64
+	RunModelQuery(ctx context.Context, query string, model flaggerv1.MetricTemplateModel) (float64, error)
+}
+
+// RunQuery executes the query within the context deadline, the providers that don't
+// implement ContextInterface are left running in the background when the context is done
+func RunQuery(ctx context.Context, provider Interface, query string) (float64, error) {
+	if p, ok := provider.(ContextInterface); ok {
+		return p.RunQueryContext(ctx, query)
+	}
+	return runDetached(ctx, func() (float64, error) {
+		return provider.RunQuery(query)
+	})
+}
+
+// IsOnline calls the provider endpoint within the context deadline, the providers that don't
+// implement ContextInterface are left running in the background when the context is done
+func IsOnline(ctx context.Context, provider Interface) (bool, error) {
+	if p, ok := provider.(ContextInterface); ok {
+		return p.IsOnlineContext(ctx)
+	}
+	val, err := runDetached(ctx, func() (float64, error) {
+		ok, err := provider.IsOnline()
+		if !ok {
+			return 0, err
+		}
+		return 1, err
+	})
+	return val == 1, err
+}
+
+// withTimeout returns a context bounded by the timeout if the parent context has no deadline,
+// the deadline of the caller is kept otherwise so that it can allow longer queries
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// runDetached runs the function in a goroutine and returns the context error if it is done first,
+// the goroutine holds one of the detached call slots until the function returns
+func runDetached(ctx context.Context, f func() (float64, error)) (float64, error) {
+	select {
+	case detachedCalls <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	type result struct {
+		val float64
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-detachedCalls }()
+		val, err := f()
+		done <- result{val: val, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.val, r.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
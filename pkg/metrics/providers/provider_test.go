@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingProviderMock struct {
+	release chan struct{}
+}
+
+func (p blockingProviderMock) RunQuery(_ string) (float64, error) {
+	<-p.release
+	return 1, nil
+}
+
+func (p blockingProviderMock) IsOnline() (bool, error) {
+	<-p.release
+	return true, nil
+}
+
+func TestRunQuery(t *testing.T) {
+	mock := blockingProviderMock{release: make(chan struct{})}
+	defer close(mock.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := RunQuery(ctx, mock, "vector(1)")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	ok, err := IsOnline(ctx, mock)
+	assert.False(t, ok)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRunQuery_Completed(t *testing.T) {
+	mock := blockingProviderMock{release: make(chan struct{})}
+	close(mock.release)
+
+	val, err := RunQuery(context.Background(), mock, "vector(1)")
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), val)
+
+	ok, err := IsOnline(context.Background(), mock)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRunQuery_MaxDetachedCalls(t *testing.T) {
+	mock := blockingProviderMock{release: make(chan struct{})}
+
+	for i := 0; i < maxDetachedCalls; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		_, err := RunQuery(ctx, mock, "vector(1)")
+		cancel()
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	}
+	assert.Len(t, detachedCalls, maxDetachedCalls)
+
+	// the calls wait for a slot instead of starting a new goroutine
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := RunQuery(ctx, mock, "vector(1)")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Len(t, detachedCalls, maxDetachedCalls)
+
+	// the slots are released when the blocked calls return
+	close(mock.release)
+	require.Eventually(t, func() bool { return len(detachedCalls) == 0 }, time.Second, time.Millisecond)
+	val, err := RunQuery(context.Background(), mock, "vector(1)")
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), val)
+}
@@ -0,0 +1,30 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"text/template"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// RenderTemplate executes the text template with the metric template model functions
+func RenderTemplate(text string, model flaggerv1.MetricTemplateModel) (string, error) {
+	t, err := template.New("tmpl").Funcs(model.TemplateFunctions()).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("template parsing failed: %w", err)
+	}
+	var data bytes.Buffer
+	b := bufio.NewWriter(&data)
+
+	if err := t.Execute(b, nil); err != nil {
+		return "", fmt.Errorf("template excution failed: %w", err)
+	}
+
+	err = b.Flush()
+	if err != nil {
+		return "", fmt.Errorf("buffer flush failed: %w", err)
+	}
+	return data.String(), nil
+}
@@ -0,0 +1,181 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// https://www.alibabacloud.com/help/doc-detail/29029.htm
+const (
+	slsLogstorePath = "/logstores/%s"
+
+	slsProgressHeaderKey = "x-log-progress"
+)
+
+// SLSProvider executes analytic queries against an Alibaba Cloud Log Service log store
+type SLSProvider struct {
+	logstoreEndpoint string
+
+	timeout     time.Duration
+	fromDelta   int64
+	credentials *alibabaCredentials
+}
+
+// NewSLSProvider takes a metric interval, a provider spec and the credentials map,
+// validates the endpoint and the log store and returns an SLS client ready to
+// execute queries over the metric interval
+func NewSLSProvider(metricInterval string,
+	provider flaggerv1.MetricTemplateProvider,
+	credentials map[string][]byte) (*SLSProvider, error) {
+	endpoint, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+	if provider.SLS == nil || provider.SLS.Project == "" || provider.SLS.Logstore == "" {
+		return nil, fmt.Errorf("%s project and logstore are required", provider.Type)
+	}
+
+	md, err := time.ParseDuration(metricInterval)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metric interval: %w", err)
+	}
+
+	ac, err := newAlibabaCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if ac == nil {
+		return nil, fmt.Errorf("sls credentials does not contain %s", alibabaAccessKeyIDSecretKey)
+	}
+
+	// the project is addressed as a subdomain of the regional endpoint
+	endpoint.Host = provider.SLS.Project + "." + endpoint.Host
+	endpoint.Path = fmt.Sprintf(slsLogstorePath, provider.SLS.Logstore)
+
+	return &SLSProvider{
+		logstoreEndpoint: endpoint.String(),
+		timeout:          defaultTimeout,
+		fromDelta:        int64(md.Seconds()),
+		credentials:      ac,
+	}, nil
+}
+
+// RunQuery calls RunQueryContext with a background context
+func (p *SLSProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext executes the SLS query over the metric interval and returns
+// the single column of the first row as float64
+func (p *SLSProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
+	now := time.Now().Unix()
+	params := url.Values{}
+	params.Set("type", "log")
+	params.Set("from", strconv.FormatInt(now-p.fromDelta, 10))
+	params.Set("to", strconv.FormatInt(now, 10))
+	params.Set("query", query)
+
+	r, b, err := p.get(ctx, p.logstoreEndpoint+"?"+params.Encode())
+	if err != nil {
+		return 0, err
+	}
+	if progress := r.Header.Get(slsProgressHeaderKey); progress != "" && progress != "Complete" {
+		return 0, fmt.Errorf("sls query result is %s", progress)
+	}
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	return parseSLSRows(rows, b)
+}
+
+// parseSLSRows returns the value of the single column of the first row,
+// the reserved fields prefixed with __ are ignored
+func parseSLSRows(rows []map[string]interface{}, b []byte) (float64, error) {
+	if len(rows) < 1 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	var columns []string
+	var value interface{}
+	for k, v := range rows[0] {
+		if strings.HasPrefix(k, "__") {
+			continue
+		}
+		columns = append(columns, k)
+		value = v
+	}
+	if len(columns) != 1 {
+		return 0, fmt.Errorf("sls query must select a single column, found %v", columns)
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		if v == "" || v == "null" {
+			return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing value %s: %w", v, err)
+		}
+		return f, nil
+	case nil:
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	default:
+		return 0, fmt.Errorf("sls column %s is not a number: %v", columns[0], v)
+	}
+}
+
+// IsOnline calls IsOnlineContext with a background context
+func (p *SLSProvider) IsOnline() (bool, error) {
+	return p.IsOnlineContext(context.Background())
+}
+
+// IsOnlineContext gets the log store and returns an error if
+// the API is unreachable or the credentials are not valid
+func (p *SLSProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	if _, _, err := p.get(ctx, p.logstoreEndpoint); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// get executes the signed request and returns the response and its body
+func (p *SLSProvider) get(ctx context.Context, address string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", address, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	p.credentials.signSLS(req)
+
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("error response: %s", string(b))
+	}
+	return r, b, nil
+}
@@ -0,0 +1,141 @@
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewSLSProvider(t *testing.T) {
+	cs := map[string][]byte{
+		alibabaAccessKeyIDSecretKey:     []byte("key-id"),
+		alibabaAccessKeySecretSecretKey: []byte("key-secret"),
+	}
+
+	sp, err := NewSLSProvider("5m", flaggerv1.MetricTemplateProvider{
+		Type:    "sls",
+		Address: "https://cn-hangzhou.log.aliyuncs.com",
+		SLS:     &flaggerv1.MetricTemplateSLSRequest{Project: "edge", Logstore: "access"},
+	}, cs)
+	require.NoError(t, err)
+	assert.Equal(t, "https://edge.cn-hangzhou.log.aliyuncs.com/logstores/access", sp.logstoreEndpoint)
+	assert.Equal(t, int64(300), sp.fromDelta)
+	assert.Equal(t, "key-id", sp.credentials.accessKeyID)
+
+	_, err = NewSLSProvider("5m", flaggerv1.MetricTemplateProvider{
+		Type:    "sls",
+		Address: "https://cn-hangzhou.log.aliyuncs.com",
+	}, cs)
+	require.Error(t, err)
+
+	_, err = NewSLSProvider("5m", flaggerv1.MetricTemplateProvider{
+		Type:    "sls",
+		Address: "https://cn-hangzhou.log.aliyuncs.com",
+		SLS:     &flaggerv1.MetricTemplateSLSRequest{Project: "edge", Logstore: "access"},
+	}, nil)
+	require.Error(t, err)
+}
+
+func TestSLSProvider_RunQuery(t *testing.T) {
+	eq := `kubernetes.labels.app: podinfo | select count_if(status >= 500) * 100.0 / count(1) as error_rate`
+
+	for name, c := range map[string]struct {
+		progress    string
+		body        string
+		expected    float64
+		noData      bool
+		errExpected bool
+	}{
+		"ok":         {body: `[{"__source__": "", "__time__": "1599726000", "error_rate": "1.5"}]`, expected: 1.5},
+		"number":     {body: `[{"error_rate": 2}]`, expected: 2},
+		"no rows":    {body: `[]`, noData: true},
+		"null":       {body: `[{"__time__": "1599726000", "error_rate": "null"}]`, noData: true},
+		"columns":    {body: `[{"errors": "1", "total": "10"}]`, errExpected: true},
+		"incomplete": {progress: "Incomplete", body: `[{"error_rate": "1"}]`, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/logstores/access", r.URL.Path)
+			assert.Equal(t, "log", r.URL.Query().Get("type"))
+			assert.Equal(t, eq, r.URL.Query().Get("query"))
+			from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+			to, _ := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+			assert.Equal(t, int64(60), to-from)
+			assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "LOG key-id:"))
+
+			progress := c.progress
+			if progress == "" {
+				progress = "Complete"
+			}
+			w.Header().Set(slsProgressHeaderKey, progress)
+			w.Write([]byte(c.body))
+		}))
+
+		sp, err := NewSLSProvider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "sls",
+			Address: ts.URL,
+			SLS:     &flaggerv1.MetricTemplateSLSRequest{Project: "edge", Logstore: "access"},
+		}, map[string][]byte{
+			alibabaAccessKeyIDSecretKey:     []byte("key-id"),
+			alibabaAccessKeySecretSecretKey: []byte("key-secret"),
+		})
+		require.NoError(t, err)
+		// the test server is not reachable through the project subdomain
+		sp.logstoreEndpoint = ts.URL + "/logstores/access"
+
+		f, err := sp.RunQuery(eq)
+		ts.Close()
+		switch {
+		case c.noData:
+			require.True(t, errors.Is(err, ErrNoValuesFound), name)
+		case c.errExpected:
+			require.Error(t, err, name)
+			assert.False(t, errors.Is(err, ErrNoValuesFound), name)
+		default:
+			require.NoError(t, err, name)
+			assert.Equal(t, c.expected, f, name)
+		}
+	}
+}
+
+func TestSLSProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusUnauthorized, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/logstores/access", r.URL.Path)
+			w.WriteHeader(c.code)
+			w.Write([]byte(`{"logstoreName": "access"}`))
+		}))
+
+		sp, err := NewSLSProvider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "sls",
+			Address: ts.URL,
+			SLS:     &flaggerv1.MetricTemplateSLSRequest{Project: "edge", Logstore: "access"},
+		}, map[string][]byte{
+			alibabaAccessKeyIDSecretKey:     []byte("key-id"),
+			alibabaAccessKeySecretSecretKey: []byte("key-secret"),
+		})
+		require.NoError(t, err)
+		sp.logstoreEndpoint = ts.URL + "/logstores/access"
+
+		_, err = sp.IsOnline()
+		ts.Close()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// WebProvider executes HTTP requests against JSON APIs
+// and extracts the metric value from the response with a JSONPath expression
+type WebProvider struct {
+	timeout time.Duration
+	address string
+	request flaggerv1.MetricTemplateWebRequest
+	parser  *jsonpath.JSONPath
+
+	// authHeaders are the headers taken from the credentials
+	authHeaders map[string]string
+}
+
+// NewWebProvider takes a provider spec and the credentials map,
+// parses the JSONPath expression and returns a web client ready to execute requests,
+// the credentials are sent as request headers
+func NewWebProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*WebProvider, error) {
+	if provider.Address == "" {
+		return nil, fmt.Errorf("%s address is required", provider.Type)
+	}
+	if provider.Web == nil {
+		return nil, fmt.Errorf("%s web request is required", provider.Type)
+	}
+
+	parser, err := ParseJSONPath(provider.Web.JSONPath)
+	if err != nil {
+		return nil, err
+	}
+
+	web := WebProvider{
+		timeout:     defaultTimeout,
+		address:     provider.Address,
+		request:     *provider.Web.DeepCopy(),
+		parser:      parser,
+		authHeaders: make(map[string]string, len(credentials)),
+	}
+	if web.request.Method == "" {
+		web.request.Method = http.MethodGet
+	}
+	for k, v := range credentials {
+		web.authHeaders[k] = string(v)
+	}
+
+	return &web, nil
+}
+
+// ParseJSONPath parses the JSONPath expression, the enclosing braces are optional
+func ParseJSONPath(expression string) (*jsonpath.JSONPath, error) {
+	if expression == "" {
+		return nil, fmt.Errorf("jsonPath is required")
+	}
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	parser := jsonpath.New("metric").AllowMissingKeys(true)
+	if err := parser.Parse(expression); err != nil {
+		return nil, fmt.Errorf("jsonPath %s parsing failed: %w", expression, err)
+	}
+	return parser, nil
+}
+
+// RunQuery calls RunQueryContext with a background context
+func (p *WebProvider) RunQuery(query string) (float64, error) {
+	return p.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext executes the request with the templates rendered with an empty model
+func (p *WebProvider) RunQueryContext(ctx context.Context, query string) (float64, error) {
+	return p.RunModelQuery(ctx, query, flaggerv1.MetricTemplateModel{})
+}
+
+// RunModelQuery renders the address and the headers with the model, executes the request
+// with the query as body if the method is POST and returns the value selected by the JSONPath expression
+func (p *WebProvider) RunModelQuery(ctx context.Context, query string, model flaggerv1.MetricTemplateModel) (float64, error) {
+	address, err := RenderTemplate(p.address, model)
+	if err != nil {
+		return 0, fmt.Errorf("address render error: %w", err)
+	}
+
+	var body io.Reader
+	if p.request.Method == http.MethodPost {
+		body = strings.NewReader(query)
+	}
+	req, err := http.NewRequestWithContext(ctx, p.request.Method, address, body)
+	if err != nil {
+		return 0, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+
+	for k, v := range p.request.Headers {
+		value, err := RenderTemplate(v, model)
+		if err != nil {
+			return 0, fmt.Errorf("header %s render error: %w", k, err)
+		}
+		req.Header.Set(k, value)
+	}
+	for k, v := range p.authHeaders {
+		req.Header.Set(k, v)
+	}
+	if p.request.Method == http.MethodPost && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	b, err := p.do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	return extractJSONPath(p.parser, p.request.JSONPath, data, b)
+}
+
+// extractJSONPath returns the first value selected by the parsed JSONPath expression as float64,
+// b is the raw response the data was decoded from
+func extractJSONPath(parser *jsonpath.JSONPath, expression string, data interface{}, b []byte) (float64, error) {
+	results, err := parser.FindResults(data)
+	if err != nil {
+		return 0, fmt.Errorf("jsonPath %s error: %w", expression, err)
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	}
+
+	value := results[0][0]
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Float64:
+		return value.Float(), nil
+	case reflect.String:
+		f, err := strconv.ParseFloat(value.String(), 64)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing value %s: %w", value.String(), err)
+		}
+		return f, nil
+	case reflect.Invalid:
+		return 0, fmt.Errorf("invalid response: %s: %w", string(b), ErrNoValuesFound)
+	default:
+		return 0, fmt.Errorf("jsonPath %s selects a %s instead of a number", expression, value.Kind())
+	}
+}
+
+// IsOnline calls IsOnlineContext with a background context
+func (p *WebProvider) IsOnline() (bool, error) {
+	return p.IsOnlineContext(context.Background())
+}
+
+// IsOnlineContext calls the health address if it is set
+// and returns an error if the response status is not 2xx
+func (p *WebProvider) IsOnlineContext(ctx context.Context) (bool, error) {
+	if p.request.HealthAddress == "" {
+		return true, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.request.HealthAddress, nil)
+	if err != nil {
+		return false, fmt.Errorf("error http.NewRequest: %w", err)
+	}
+	for k, v := range p.authHeaders {
+		req.Header.Set(k, v)
+	}
+
+	if _, err := p.do(req); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// do executes the request with the provider timeout and returns the response body
+func (p *WebProvider) do(req *http.Request) ([]byte, error) {
+	ctx, cancel := withTimeout(req.Context(), p.timeout)
+	defer cancel()
+	r, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		return nil, fmt.Errorf("error response: %s", string(b))
+	}
+	return b, nil
+}
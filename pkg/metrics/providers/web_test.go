@@ -0,0 +1,156 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewWebProvider(t *testing.T) {
+	wp, err := NewWebProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "web",
+		Address: "http://slo-api/{{ target }}",
+		Web:     &flaggerv1.MetricTemplateWebRequest{JSONPath: ".data.value"},
+	}, map[string][]byte{"Authorization": []byte("Bearer token")})
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, wp.request.Method)
+	assert.Equal(t, "Bearer token", wp.authHeaders["Authorization"])
+
+	_, err = NewWebProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "web",
+		Address: "http://slo-api",
+	}, nil)
+	require.Error(t, err)
+
+	_, err = NewWebProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "web",
+		Address: "http://slo-api",
+		Web:     &flaggerv1.MetricTemplateWebRequest{JSONPath: "{.data[}"},
+	}, nil)
+	require.Error(t, err)
+}
+
+func TestWebProvider_RunModelQuery(t *testing.T) {
+	model := flaggerv1.MetricTemplateModel{Target: "podinfo", Namespace: "test", Variant: "canary"}
+
+	t.Run("get", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/slo/test/podinfo", r.URL.Path)
+			assert.Equal(t, "canary", r.Header.Get("X-Variant"))
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"data": {"slos": [{"name": "availability", "value": 99.95}]}}`))
+		}))
+		defer ts.Close()
+
+		wp, err := NewWebProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "web",
+			Address: ts.URL + "/slo/{{ namespace }}/{{ target }}",
+			Web: &flaggerv1.MetricTemplateWebRequest{
+				Headers:  map[string]string{"X-Variant": "{{ variant }}"},
+				JSONPath: `{.data.slos[?(@.name=="availability")].value}`,
+			},
+		}, map[string][]byte{"Authorization": []byte("Bearer token")})
+		require.NoError(t, err)
+
+		f, err := wp.RunModelQuery(context.TODO(), "", model)
+		require.NoError(t, err)
+		assert.Equal(t, 99.95, f)
+	})
+
+	t.Run("post", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"service": "podinfo"}`, string(b))
+			w.Write([]byte(`{"latency": "250.5"}`))
+		}))
+		defer ts.Close()
+
+		wp, err := NewWebProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "web",
+			Address: ts.URL,
+			Web:     &flaggerv1.MetricTemplateWebRequest{Method: http.MethodPost, JSONPath: ".latency"},
+		}, nil)
+		require.NoError(t, err)
+
+		f, err := wp.RunModelQuery(context.TODO(), `{"service": "podinfo"}`, model)
+		require.NoError(t, err)
+		assert.Equal(t, 250.5, f)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": {}}`))
+		}))
+		defer ts.Close()
+
+		wp, err := NewWebProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "web",
+			Address: ts.URL,
+			Web:     &flaggerv1.MetricTemplateWebRequest{JSONPath: ".data.value"},
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = wp.RunModelQuery(context.TODO(), "", model)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("not a number", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": {"value": {"avg": 1}}}`))
+		}))
+		defer ts.Close()
+
+		wp, err := NewWebProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "web",
+			Address: ts.URL,
+			Web:     &flaggerv1.MetricTemplateWebRequest{JSONPath: ".data.value"},
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = wp.RunModelQuery(context.TODO(), "", model)
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrNoValuesFound))
+	})
+}
+
+func TestWebProvider_IsOnline(t *testing.T) {
+	for _, c := range []struct {
+		code        int
+		errExpected bool
+	}{
+		{code: http.StatusOK, errExpected: false},
+		{code: http.StatusServiceUnavailable, errExpected: true},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/healthz", r.URL.Path)
+			w.WriteHeader(c.code)
+		}))
+
+		wp, err := NewWebProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "web",
+			Address: ts.URL + "/slo",
+			Web:     &flaggerv1.MetricTemplateWebRequest{JSONPath: ".value", HealthAddress: ts.URL + "/healthz"},
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = wp.IsOnline()
+		ts.Close()
+		if c.errExpected {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/weaveworks/flagger/pkg/apis/edas/v1alpha1/route"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+/*
+EDAS Principles:
+* Dubbo and Spring Cloud consumers pick providers from the microservice registry,
+  there is no proxy in the data path that Flagger could program
+* the registry agents tag the canary instances with the canary service name
+  and route the consumer calls according to the tag routing rules
+* when match conditions are set (A/B testing) only the matching calls are routed to the tagged instances
+* without match conditions the tagged instances receive a weighted share of the calls
+
+Implementation:
+* the tag routing rules are generated from analysis.dubboMatch and analysis.springCloudMatch
+* the rules are stored in the registry under the apex service name
+* SetRoutes toggles the match rules (A/B testing) or changes the weight (canary)
+*/
+
+const (
+	edasTagRouteDataKey    = "route.json"
+	edasTagRouteNameSuffix = "-tag-route"
+	edasTagRouteLabelKey   = "flagger.app/tag-route"
+)
+
+// EdasTagRoute is the tag routing rule set published to the microservice registry
+type EdasTagRoute struct {
+	// Tag of the canary instances
+	Tag string `json:"tag"`
+
+	// Enabled routes the calls matching the Dubbo and Spring Cloud rules to the tagged instances
+	Enabled bool `json:"enabled"`
+
+	// Weight is the percentage of calls routed to the tagged instances when no rule is set
+	Weight int `json:"weight"`
+
+	// Dubbo rules
+	Dubbo []EdasDubboRule `json:"dubbo,omitempty"`
+
+	// Spring Cloud rules
+	SpringCloud []EdasSpringCloudRule `json:"springCloud,omitempty"`
+}
+
+// EdasDubboRule matches the calls of a Dubbo service method
+type EdasDubboRule struct {
+	ServiceName   string                `json:"serviceName"`
+	Version       string                `json:"version,omitempty"`
+	Group         string                `json:"group,omitempty"`
+	MethodName    string                `json:"methodName"`
+	ParamTypes    []string              `json:"paramTypes,omitempty"`
+	TriggerPolicy route.ConditionPolicy `json:"triggerPolicy"`
+	Conditions    []EdasCondition       `json:"conditions"`
+}
+
+// EdasSpringCloudRule matches the calls of a Spring Cloud path
+type EdasSpringCloudRule struct {
+	Path          string                `json:"path"`
+	TriggerPolicy route.ConditionPolicy `json:"triggerPolicy"`
+	Conditions    []EdasCondition       `json:"conditions"`
+}
+
+// EdasCondition compares a call parameter, header or cookie against a list of values
+type EdasCondition struct {
+	// Type is the Dubbo parameter index or the Spring Cloud strategy
+	Type string `json:"type"`
+
+	// Key extracts the value from the parameter, header, query param or cookie
+	Key string `json:"key"`
+
+	// Operator is one of ==, !=, >, <, >=, <=
+	Operator string `json:"operator"`
+
+	Values []string `json:"values"`
+}
+
+// TagRouteRegistry stores the tag routing rules consumed by the microservice registry agents
+type TagRouteRegistry interface {
+	// Get returns the rules of the canary or a NotFound error
+	Get(canary *flaggerv1.Canary) (*EdasTagRoute, error)
+	// Put creates or updates the rules of the canary
+	Put(canary *flaggerv1.Canary, tagRoute *EdasTagRoute) error
+	// Delete removes the rules of the canary
+	Delete(canary *flaggerv1.Canary) error
+}
+
+// EdasRouter is managing the tag routing rules of Dubbo and Spring Cloud services
+type EdasRouter struct {
+	registry TagRouteRegistry
+	logger   *zap.SugaredLogger
+}
+
+// Reconcile creates or updates the tag routing rules, the traffic state is left untouched
+func (er *EdasRouter) Reconcile(canary *flaggerv1.Canary) error {
+	newTagRoute, err := makeEdasTagRoute(canary)
+	if err != nil {
+		return fmt.Errorf("tag route %s.%s build error: %w", canary.Name, canary.Namespace, err)
+	}
+
+	tagRoute, err := er.registry.Get(canary)
+	if errors.IsNotFound(err) {
+		if err := er.registry.Put(canary, newTagRoute); err != nil {
+			return fmt.Errorf("tag route %s.%s create error: %w", canary.Name, canary.Namespace, err)
+		}
+		er.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Tag route %s.%s created", newTagRoute.Tag, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("tag route %s.%s get query error: %w", canary.Name, canary.Namespace, err)
+	}
+
+	// keep the current traffic state
+	newTagRoute.Enabled = tagRoute.Enabled
+	newTagRoute.Weight = tagRoute.Weight
+
+	if diff := cmp.Diff(newTagRoute, tagRoute); diff != "" {
+		if err := er.registry.Put(canary, newTagRoute); err != nil {
+			return fmt.Errorf("tag route %s.%s update error: %w", canary.Name, canary.Namespace, err)
+		}
+		er.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("Tag route %s.%s updated", newTagRoute.Tag, canary.Namespace)
+	}
+
+	return nil
+}
+
+// GetRoutes returns the traffic state of the tag routing rules
+func (er *EdasRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	tagRoute, err := er.registry.Get(canary)
+	if err != nil {
+		err = fmt.Errorf("tag route %s.%s get query error: %w", canary.Name, canary.Namespace, err)
+		return
+	}
+
+	// A/B testing
+	if hasEdasMatch(canary) {
+		if tagRoute.Enabled {
+			return 0, 100, false, nil
+		}
+		return 100, 0, false, nil
+	}
+
+	canaryWeight = tagRoute.Weight
+	primaryWeight = 100 - canaryWeight
+	return
+}
+
+// SetRoutes enables the match rules for A/B testing or sets the weight of the tagged instances,
+// traffic mirroring is not supported by the registry
+func (er *EdasRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	_ int,
+	canaryWeight int,
+	_ bool,
+) error {
+	tagRoute, err := er.registry.Get(canary)
+	if err != nil {
+		return fmt.Errorf("tag route %s.%s get query error: %w", canary.Name, canary.Namespace, err)
+	}
+
+	tagRouteClone := *tagRoute
+	if hasEdasMatch(canary) {
+		tagRouteClone.Enabled = canaryWeight > 0
+		tagRouteClone.Weight = 0
+	} else {
+		tagRouteClone.Enabled = false
+		tagRouteClone.Weight = canaryWeight
+	}
+
+	if err := er.registry.Put(canary, &tagRouteClone); err != nil {
+		return fmt.Errorf("tag route %s.%s update error: %w", canary.Name, canary.Namespace, err)
+	}
+	return nil
+}
+
+// Finalize removes the tag routing rules so that all calls go to the untagged instances
+func (er *EdasRouter) Finalize(canary *flaggerv1.Canary) error {
+	if err := er.registry.Delete(canary); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("tag route %s.%s delete error: %w", canary.Name, canary.Namespace, err)
+	}
+	return nil
+}
+
+func hasEdasMatch(canary *flaggerv1.Canary) bool {
+	return len(canary.GetAnalysis().DubboMatch) > 0 || len(canary.GetAnalysis().SpringCloudMatch) > 0
+}
+
+// makeEdasTagRoute converts the canary match requests into tag routing rules
+func makeEdasTagRoute(canary *flaggerv1.Canary) (*EdasTagRoute, error) {
+	_, _, canaryName := canary.GetServiceNames()
+	tagRoute := &EdasTagRoute{
+		Tag: canaryName,
+	}
+
+	for _, m := range canary.GetAnalysis().DubboMatch {
+		if err := m.Validate(); err != nil {
+			return nil, err
+		}
+		rule := EdasDubboRule{
+			ServiceName:   m.ServiceName,
+			Version:       m.Version,
+			Group:         m.Group,
+			MethodName:    m.MethodName,
+			ParamTypes:    m.ParamTypes,
+			TriggerPolicy: makeEdasTriggerPolicy(m.TriggerPolicy),
+		}
+		for _, c := range m.Conditions {
+			operator, _ := c.Operator.Symbol()
+			rule.Conditions = append(rule.Conditions, EdasCondition{
+				Type:     fmt.Sprintf("%d", c.ParamIndex),
+				Key:      c.Key,
+				Operator: operator,
+				Values:   c.Values,
+			})
+		}
+		tagRoute.Dubbo = append(tagRoute.Dubbo, rule)
+	}
+
+	for _, m := range canary.GetAnalysis().SpringCloudMatch {
+		if err := m.Validate(); err != nil {
+			return nil, err
+		}
+		rule := EdasSpringCloudRule{
+			Path:          m.Path,
+			TriggerPolicy: makeEdasTriggerPolicy(m.TriggerPolicy),
+		}
+		for _, c := range m.Conditions {
+			operator, _ := c.Operator.Symbol()
+			rule.Conditions = append(rule.Conditions, EdasCondition{
+				Type:     string(c.Strategy),
+				Key:      c.Key,
+				Operator: operator,
+				Values:   c.Values,
+			})
+		}
+		tagRoute.SpringCloud = append(tagRoute.SpringCloud, rule)
+	}
+
+	return tagRoute, nil
+}
+
+func makeEdasTriggerPolicy(policy route.ConditionPolicy) route.ConditionPolicy {
+	if policy == "" {
+		return route.PolicyAND
+	}
+	return policy
+}
+
+// ConfigMapTagRouteRegistry stores the tag routing rules in a ConfigMap
+// named <apex>-tag-route that is watched by the registry agents
+type ConfigMapTagRouteRegistry struct {
+	kubeClient kubernetes.Interface
+}
+
+func (r *ConfigMapTagRouteRegistry) Get(canary *flaggerv1.Canary) (*EdasTagRoute, error) {
+	cm, err := r.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Get(context.TODO(), r.name(canary), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	tagRoute := &EdasTagRoute{}
+	if err := json.Unmarshal([]byte(cm.Data[edasTagRouteDataKey]), tagRoute); err != nil {
+		return nil, fmt.Errorf("configmap %s.%s unmarshal error: %w", cm.Name, cm.Namespace, err)
+	}
+	return tagRoute, nil
+}
+
+func (r *ConfigMapTagRouteRegistry) Put(canary *flaggerv1.Canary, tagRoute *EdasTagRoute) error {
+	b, err := json.Marshal(tagRoute)
+	if err != nil {
+		return fmt.Errorf("tag route marshal error: %w", err)
+	}
+
+	cm, err := r.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Get(context.TODO(), r.name(canary), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      r.name(canary),
+				Namespace: canary.Namespace,
+				Labels: map[string]string{
+					edasTagRouteLabelKey: tagRoute.Tag,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+						Group:   flaggerv1.SchemeGroupVersion.Group,
+						Version: flaggerv1.SchemeGroupVersion.Version,
+						Kind:    flaggerv1.CanaryKind,
+					}),
+				},
+			},
+			Data: map[string]string{
+				edasTagRouteDataKey: string(b),
+			},
+		}
+		_, err = r.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	cmClone := cm.DeepCopy()
+	if cmClone.Data == nil {
+		cmClone.Data = make(map[string]string)
+	}
+	cmClone.Data[edasTagRouteDataKey] = string(b)
+	_, err = r.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Update(context.TODO(), cmClone, metav1.UpdateOptions{})
+	return err
+}
+
+func (r *ConfigMapTagRouteRegistry) Delete(canary *flaggerv1.Canary) error {
+	return r.kubeClient.CoreV1().ConfigMaps(canary.Namespace).Delete(context.TODO(), r.name(canary), metav1.DeleteOptions{})
+}
+
+func (r *ConfigMapTagRouteRegistry) name(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	return apexName + edasTagRouteNameSuffix
+}
@@ -0,0 +1,258 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/weaveworks/flagger/pkg/apis/edas/v1alpha1/route"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// localTagRouteRegistry is an in-memory stand-in for the microservice registry
+type localTagRouteRegistry struct {
+	routes map[string]EdasTagRoute
+}
+
+func newLocalTagRouteRegistry() *localTagRouteRegistry {
+	return &localTagRouteRegistry{routes: make(map[string]EdasTagRoute)}
+}
+
+func (r *localTagRouteRegistry) Get(canary *flaggerv1.Canary) (*EdasTagRoute, error) {
+	tagRoute, ok := r.routes[canary.Namespace+"/"+canary.Name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Resource: "tagroutes"}, canary.Name)
+	}
+	return &tagRoute, nil
+}
+
+func (r *localTagRouteRegistry) Put(canary *flaggerv1.Canary, tagRoute *EdasTagRoute) error {
+	r.routes[canary.Namespace+"/"+canary.Name] = *tagRoute
+	return nil
+}
+
+func (r *localTagRouteRegistry) Delete(canary *flaggerv1.Canary) error {
+	key := canary.Namespace + "/" + canary.Name
+	if _, ok := r.routes[key]; !ok {
+		return errors.NewNotFound(schema.GroupResource{Resource: "tagroutes"}, canary.Name)
+	}
+	delete(r.routes, key)
+	return nil
+}
+
+func newTestEdasABTest() *flaggerv1.Canary {
+	cd := newTestABTest()
+	cd.Spec.Analysis.Match = nil
+	cd.Spec.Analysis.DubboMatch = []route.DubboMatchRequest{
+		{
+			ServiceName: "com.example.DemoService",
+			Version:     "1.0.0",
+			MethodName:  "sayHello",
+			ParamTypes:  []string{"java.lang.String"},
+			Conditions: []route.DubboCondition{
+				{
+					ParamIndex: 0,
+					Operator:   route.OperatorEqual,
+					Values:     []string{"canary"},
+				},
+			},
+		},
+	}
+	cd.Spec.Analysis.SpringCloudMatch = []route.SpringCloudMatchRequest{
+		{
+			Path:          "/hello",
+			TriggerPolicy: route.PolicyOR,
+			Conditions: []route.SpringCloudCondition{
+				{
+					Strategy: route.SCTrafficStrategyHEADER,
+					Key:      "x-user",
+					Operator: route.OperatorEqual,
+					Values:   []string{"alice", "bob"},
+				},
+				{
+					Strategy: route.SCTrafficStrategyCOOKIE,
+					Key:      "uid",
+					Operator: route.OperatorGreatThanOrEqual,
+					Values:   []string{"100"},
+				},
+			},
+		},
+	}
+	return cd
+}
+
+func TestEdasRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	registry := newLocalTagRouteRegistry()
+	router := &EdasRouter{
+		registry: registry,
+		logger:   mocks.logger,
+	}
+
+	cd := newTestEdasABTest()
+	err := router.Reconcile(cd)
+	require.NoError(t, err)
+
+	tagRoute, err := registry.Get(cd)
+	require.NoError(t, err)
+	assert.Equal(t, "abtest-canary", tagRoute.Tag)
+	assert.False(t, tagRoute.Enabled)
+
+	require.Len(t, tagRoute.Dubbo, 1)
+	assert.Equal(t, route.PolicyAND, tagRoute.Dubbo[0].TriggerPolicy)
+	require.Len(t, tagRoute.Dubbo[0].Conditions, 1)
+	assert.Equal(t, "0", tagRoute.Dubbo[0].Conditions[0].Type)
+	assert.Equal(t, "==", tagRoute.Dubbo[0].Conditions[0].Operator)
+
+	require.Len(t, tagRoute.SpringCloud, 1)
+	assert.Equal(t, route.PolicyOR, tagRoute.SpringCloud[0].TriggerPolicy)
+	require.Len(t, tagRoute.SpringCloud[0].Conditions, 2)
+	assert.Equal(t, "HEADER", tagRoute.SpringCloud[0].Conditions[0].Type)
+	assert.Equal(t, "x-user", tagRoute.SpringCloud[0].Conditions[0].Key)
+	assert.Equal(t, "COOKIE", tagRoute.SpringCloud[0].Conditions[1].Type)
+	assert.Equal(t, ">=", tagRoute.SpringCloud[0].Conditions[1].Operator)
+
+	// test update keeps the traffic state
+	err = router.SetRoutes(cd, 0, 100, false)
+	require.NoError(t, err)
+
+	cd.Spec.Analysis.SpringCloudMatch[0].Path = "/v2/hello"
+	err = router.Reconcile(cd)
+	require.NoError(t, err)
+
+	tagRoute, err = registry.Get(cd)
+	require.NoError(t, err)
+	assert.True(t, tagRoute.Enabled)
+	assert.Equal(t, "/v2/hello", tagRoute.SpringCloud[0].Path)
+}
+
+func TestEdasRouter_ReconcileInvalid(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &EdasRouter{
+		registry: newLocalTagRouteRegistry(),
+		logger:   mocks.logger,
+	}
+
+	cd := newTestEdasABTest()
+	cd.Spec.Analysis.DubboMatch[0].Conditions[0].ParamIndex = 1
+	err := router.Reconcile(cd)
+	require.Error(t, err)
+
+	cd = newTestEdasABTest()
+	cd.Spec.Analysis.SpringCloudMatch[0].Conditions[0].Strategy = "BODY"
+	err = router.Reconcile(cd)
+	require.Error(t, err)
+
+	cd = newTestEdasABTest()
+	cd.Spec.Analysis.SpringCloudMatch[0].TriggerPolicy = "XOR"
+	err = router.Reconcile(cd)
+	require.Error(t, err)
+}
+
+func TestEdasRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &EdasRouter{
+		registry: newLocalTagRouteRegistry(),
+		logger:   mocks.logger,
+	}
+
+	cd := newTestEdasABTest()
+	err := router.Reconcile(cd)
+	require.NoError(t, err)
+
+	p, c, m, err := router.GetRoutes(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.False(t, m)
+
+	err = router.SetRoutes(cd, 0, 100, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	err = router.SetRoutes(cd, 100, 0, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+}
+
+func TestEdasRouter_Canary(t *testing.T) {
+	mocks := newFixture(nil)
+	registry := newLocalTagRouteRegistry()
+	router := &EdasRouter{
+		registry: registry,
+		logger:   mocks.logger,
+	}
+
+	err := router.Reconcile(mocks.canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.canary, 70, 30, false)
+	require.NoError(t, err)
+
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+
+	tagRoute, err := registry.Get(mocks.canary)
+	require.NoError(t, err)
+	assert.False(t, tagRoute.Enabled)
+	assert.Equal(t, 30, tagRoute.Weight)
+}
+
+func TestEdasRouter_Finalize(t *testing.T) {
+	mocks := newFixture(nil)
+	registry := newLocalTagRouteRegistry()
+	router := &EdasRouter{
+		registry: registry,
+		logger:   mocks.logger,
+	}
+
+	cd := newTestEdasABTest()
+	err := router.Reconcile(cd)
+	require.NoError(t, err)
+
+	err = router.Finalize(cd)
+	require.NoError(t, err)
+
+	_, err = registry.Get(cd)
+	assert.True(t, errors.IsNotFound(err))
+
+	// finalize is idempotent
+	err = router.Finalize(cd)
+	require.NoError(t, err)
+}
+
+func TestConfigMapTagRouteRegistry(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &EdasRouter{
+		registry: &ConfigMapTagRouteRegistry{kubeClient: mocks.kubeClient},
+		logger:   mocks.logger,
+	}
+
+	cd := newTestEdasABTest()
+	err := router.Reconcile(cd)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(cd, 0, 100, false)
+	require.NoError(t, err)
+
+	p, c, _, err := router.GetRoutes(cd)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	err = router.Finalize(cd)
+	require.NoError(t, err)
+}
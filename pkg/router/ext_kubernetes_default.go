@@ -55,6 +55,9 @@ func (e *ExtKubernetesDefaultRouter) Initialize(canary *flaggerv1.Canary) error
 
 // Reconcile creates or updates the main service
 func (e *ExtKubernetesDefaultRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if _, exist := internal.CanaryDistinguishLabelsExisted(canary); !exist {
+		return e.innerK8sRouter.Reconcile(canary)
+	}
 	apexName, _, _ := canary.GetServiceNames()
 
 	var err error
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/internal"
+)
+
+func newTestVersionedDeployment(name string, version string) *appsv1.Deployment {
+	labels := map[string]string{
+		"app":     "podinfo",
+		"version": version,
+	}
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			},
+		},
+	}
+}
+
+func TestExtKubernetesDefaultRouter_PodSelector(t *testing.T) {
+	mocks := newFixture(nil)
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(),
+		newTestVersionedDeployment("podinfo-primary", "v1"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(),
+		newTestVersionedDeployment("podinfo", "v2"), metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	router := &ExtKubernetesDefaultRouter{
+		innerK8sRouter: &KubernetesDefaultRouter{
+			kubeClient:    mocks.kubeClient,
+			flaggerClient: mocks.flaggerClient,
+			logger:        mocks.logger,
+			labelSelector: "app",
+		},
+	}
+
+	cd := mocks.canary.DeepCopy()
+	cd.Status.Phase = flaggerv1.CanaryPhaseInitialized
+	cd.Spec.PodSelector = &flaggerv1.CanaryPodSelector{
+		DistinguishLabels: []string{"app", "version"},
+		GeneralLabels:     []string{"app"},
+	}
+	// the typed field takes precedence over the deprecated annotation
+	cd.Annotations = map[string]string{
+		internal.OAM_CANARY_DISTINGUISH_LABELS: "app",
+	}
+
+	err = router.Initialize(cd)
+	require.NoError(t, err)
+
+	canarySvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "podinfo", "version": "v2"}, canarySvc.Spec.Selector)
+
+	err = router.Reconcile(cd)
+	require.NoError(t, err)
+
+	apexSvc, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "podinfo", "version": "v1"}, apexSvc.Spec.Selector)
+
+	// after the analysis the apex service selects the whole app
+	cd.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	err = router.Reconcile(cd)
+	require.NoError(t, err)
+
+	apexSvc, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "podinfo"}, apexSvc.Spec.Selector)
+}
+
+func TestDeprecatedAnnotations(t *testing.T) {
+	cd := newTestCanary()
+	cd.Annotations = map[string]string{
+		internal.OAM_CANARY_EXT_SWITCH:         "true",
+		internal.OAM_CANARY_DISTINGUISH_LABELS: "app,version",
+		internal.OAM_CANARY_GENERAL_LABELS:     "app",
+	}
+	assert.True(t, internal.IsExtentOn(cd))
+	assert.Len(t, internal.DeprecatedAnnotations(cd), 3)
+
+	labels, ok := internal.CanaryDistinguishLabelsExisted(cd)
+	require.True(t, ok)
+	assert.Equal(t, []string{"app", "version"}, labels)
+
+	cd.Spec.ReplicaScaling = &flaggerv1.CanaryReplicaScaling{Enabled: false}
+	cd.Spec.PodSelector = &flaggerv1.CanaryPodSelector{GeneralLabels: []string{"name"}}
+	assert.False(t, internal.IsExtentOn(cd))
+	assert.Equal(t, map[string]string{
+		internal.OAM_CANARY_DISTINGUISH_LABELS: "spec.podSelector.distinguishLabels",
+	}, internal.DeprecatedAnnotations(cd))
+
+	labels, ok = internal.CanaryGeneralLabelsExisted(cd)
+	require.True(t, ok)
+	assert.Equal(t, []string{"name"}, labels)
+}
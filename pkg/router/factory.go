@@ -138,6 +138,11 @@ func (factory *Factory) innerMeshRouter(provider string, labelSelector string) I
 			logger:     factory.logger,
 			kubeClient: factory.kubeClient,
 		}
+	case provider == flaggerv1.EDASProvider:
+		return &EdasRouter{
+			logger:   factory.logger,
+			registry: &ConfigMapTagRouteRegistry{kubeClient: factory.kubeClient},
+		}
 	case provider == flaggerv1.KubernetesProvider:
 		return &NopRouter{}
 	default:
@@ -38,7 +38,7 @@ func (r *OAMRouteWrapper) SetRoutes(canary *v1beta1.Canary, primaryWeight int, c
 		}
 		// now scale up the target to max replica, the canary will be zeroed in ScaleToZero in the controller
 		targetName := canary.Spec.TargetRef.Name
-		targetReplica := int32(canary.Spec.Analysis.MaxReplicas)
+		targetReplica := int32(canary.GetAnalysis().MaxReplicas)
 		if err := r.scalar.Scale(targetName, targetReplica); err != nil {
 			return fmt.Errorf("adjust replicas of primary deployment %s.%s failed %w, replicas: %d", targetName, canary.Namespace, err, targetReplica)
 		}
@@ -51,7 +51,7 @@ func (r *OAMRouteWrapper) SetRoutes(canary *v1beta1.Canary, primaryWeight int, c
 		// rollback, we make rollback as fast as possible.
 		// now source is primary
 		primaryName := r.getSourceName()
-		primaryReplicas := int32(canary.Spec.Analysis.MaxReplicas)
+		primaryReplicas := int32(canary.GetAnalysis().MaxReplicas)
 		err := r.scalar.Scale(primaryName, primaryReplicas)
 		if err != nil {
 			return fmt.Errorf("adjust replicas of primary deployment %s.%s failed %w, replicas: %d", primaryName, canary.Namespace, err, primaryReplicas)
@@ -73,14 +73,21 @@ func (r *OAMRouteWrapper) SetRoutes(canary *v1beta1.Canary, primaryWeight int, c
 		}
 		var canaryReplicas int32 = 0
 		// prefer specified canary replicas
-		if canary.Spec.Analysis.CanaryReplicas > 0 {
-			canaryReplicas = int32(canary.Spec.Analysis.CanaryReplicas)
+		if canary.GetAnalysis().CanaryReplicas > 0 {
+			canaryReplicas = int32(canary.GetAnalysis().CanaryReplicas)
 		}
 		// use auto canary weight to compute canary replicas
-		maxReplicas := canary.Spec.Analysis.MaxReplicas
-		if canary.Spec.Analysis.StepWeight > 0 {
+		maxReplicas := canary.GetAnalysis().MaxReplicas
+		if canary.GetAnalysis().StepWeight > 0 {
 			canaryReplicas = int32(percent(canaryWeight, maxReplicas))
 		}
+		// use the replicas of the analysis step being applied
+		if step, ok := canary.GetCurrentStep(); ok && step.Weight == canaryWeight {
+			canaryReplicas = int32(percent(canaryWeight, maxReplicas))
+			if step.Replicas > 0 {
+				canaryReplicas = int32(step.Replicas)
+			}
+		}
 		// save at least 1
 		if canaryReplicas == 0 && canaryWeight != 0 {
 			canaryReplicas = 1
@@ -37,7 +37,7 @@ func (r *RouterScalableWrapper) SetRoutes(canary *v1beta1.Canary, primaryWeight
 		}
 		// now target is primary
 		primaryName := canary.Spec.TargetRef.Name
-		primaryReplicas := int32(canary.Spec.Analysis.MaxReplicas)
+		primaryReplicas := int32(canary.GetAnalysis().MaxReplicas)
 		err := r.updateReplicas(canary, primaryName, &primaryReplicas)
 		if err != nil {
 			return fmt.Errorf("adjust replicas of primary deployment %s.%s failed %w, replicas: %d", primaryName, canary.Namespace, err, primaryReplicas)
@@ -56,7 +56,7 @@ func (r *RouterScalableWrapper) SetRoutes(canary *v1beta1.Canary, primaryWeight
 		// rollback, we make rollback as fast as possible.
 		// now source is primary
 		primaryName := r.getSourceName(canary)
-		primaryReplicas := int32(canary.Spec.Analysis.MaxReplicas)
+		primaryReplicas := int32(canary.GetAnalysis().MaxReplicas)
 		err := r.updateReplicas(canary, primaryName, &primaryReplicas)
 		if err != nil {
 			return fmt.Errorf("adjust replicas of primary deployment %s.%s failed %w, replicas: %d", primaryName, canary.Namespace, err, primaryReplicas)
@@ -76,14 +76,21 @@ func (r *RouterScalableWrapper) SetRoutes(canary *v1beta1.Canary, primaryWeight
 		}
 		var canaryReplicas int32 = 0
 		// prefer specified canary replicas
-		if canary.Spec.Analysis.CanaryReplicas > 0 {
-			canaryReplicas = int32(canary.Spec.Analysis.CanaryReplicas)
+		if canary.GetAnalysis().CanaryReplicas > 0 {
+			canaryReplicas = int32(canary.GetAnalysis().CanaryReplicas)
 		}
 		// use auto canary weight to compute canary replicas
-		maxReplicas := canary.Spec.Analysis.MaxReplicas
-		if canary.Spec.Analysis.StepWeight > 0 {
+		maxReplicas := canary.GetAnalysis().MaxReplicas
+		if canary.GetAnalysis().StepWeight > 0 {
 			canaryReplicas = int32(percent(canaryWeight, maxReplicas))
 		}
+		// use the replicas of the analysis step being applied
+		if step, ok := canary.GetCurrentStep(); ok && step.Weight == canaryWeight {
+			canaryReplicas = int32(percent(canaryWeight, maxReplicas))
+			if step.Replicas > 0 {
+				canaryReplicas = int32(step.Replicas)
+			}
+		}
 
 		if canaryReplicas == 0 && canaryWeight != 0 {
 			canaryReplicas = 1
@@ -115,21 +122,6 @@ func (r *RouterScalableWrapper) SetRoutes(canary *v1beta1.Canary, primaryWeight
 	return nil
 }
 
-func (r *RouterScalableWrapper) haltCanary(canary *v1beta1.Canary) error {
-	ca := canary.DeepCopy()
-	webHooks := make([]v1beta1.CanaryWebhook, 0, len(ca.Spec.Analysis.Webhooks)+1)
-	webHooks = append(webHooks, v1beta1.CanaryWebhook{
-		Name: "flagger-default-halt",
-		Type: v1beta1.ConfirmRolloutHook,
-		URL:  internal.DefaultCanaryHaltUrl,
-	})
-	webHooks = append(webHooks, ca.Spec.Analysis.Webhooks...)
-	ca.Spec.Analysis.Webhooks = webHooks
-	new, e := r.flaggerClient.FlaggerV1beta1().Canaries(ca.Namespace).Update(context.Background(), ca, metav1.UpdateOptions{})
-	new.DeepCopyInto(canary)
-	return e
-}
-
 func (r *RouterScalableWrapper) updateReplicas(canary *v1beta1.Canary, name string, replicas *int32) error {
 	ctx := context.Background()
 	dc := r.kubeClient.AppsV1().Deployments(canary.Namespace)
@@ -147,9 +139,13 @@ func (r *RouterScalableWrapper) updateReplicas(canary *v1beta1.Canary, name stri
 }
 
 func (r *RouterScalableWrapper) GetRoutes(canary *v1beta1.Canary) (primaryWeight int, canaryWeight int, mirrored bool, err error) {
-	if internal.IsExtentOn(canary) && canary.Spec.Analysis.StepWeight <= 0 {
+	if internal.IsExtentOn(canary) && canary.GetAnalysis().StepWeight <= 0 {
 		// prefer specified canary weight
-		canaryWeight = canary.Spec.Analysis.CanaryWeight
+		canaryWeight = canary.GetAnalysis().CanaryWeight
+		// resume from the analysis step recorded in status
+		if step, ok := canary.GetCurrentStep(); ok {
+			canaryWeight = step.Weight
+		}
 		primaryWeight = hundred - canaryWeight
 		return
 	}
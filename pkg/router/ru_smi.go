@@ -29,10 +29,12 @@ func (rsr *RollingUpdateSmiRouter) SetRoutes(canary *v1beta1.Canary, primaryWeig
 		// canary have been promoted
 		if canary.Status.Phase == v1beta1.CanaryPhasePromoting && canaryWeight == 0 && primaryWeight == hundred {
 			return nil
+		} else if canary.GetAnalysis() == nil {
+			return fmt.Errorf("set route of canary %s.%s failed, the analysis is not set", canary.Name, canary.Namespace)
 		} else {
 			primaryName := canary.Spec.SourceRef.Name
 			canaryName := canary.Spec.TargetRef.Name
-			maxReplicas := canary.Spec.Analysis.MaxReplicas
+			maxReplicas := canary.GetAnalysis().MaxReplicas
 			canaryReplicas := int32(percent(canaryWeight, maxReplicas))
 			primaryReplicas := int32(maxReplicas) - canaryReplicas
 
@@ -57,19 +59,17 @@ func (rsr *RollingUpdateSmiRouter) SetRoutes(canary *v1beta1.Canary, primaryWeig
 	}
 }
 
+// haltCanary suspends the canary analysis until the canary replicas are reconciled,
+// the scheduler records the Suspended condition and the analysis resumes once spec.suspend is cleared
 func (rsr *RollingUpdateSmiRouter) haltCanary(canary *v1beta1.Canary) error {
 	ca := canary.DeepCopy()
-	webHooks := make([]v1beta1.CanaryWebhook, 0, len(ca.Spec.Analysis.Webhooks)+1)
-	webHooks = append(webHooks, v1beta1.CanaryWebhook{
-		Name: "flagger-default-halt",
-		Type: v1beta1.ConfirmRolloutHook,
-		URL:  internal.DefaultCanaryHaltUrl,
-	})
-	webHooks = append(webHooks, ca.Spec.Analysis.Webhooks...)
-	ca.Spec.Analysis.Webhooks = webHooks
-	new, e := rsr.flaggerClient.FlaggerV1beta1().Canaries(ca.Namespace).Update(context.Background(), ca, metav1.UpdateOptions{})
-	new.DeepCopyInto(canary)
-	return e
+	ca.Spec.Suspend = true
+	updated, err := rsr.flaggerClient.FlaggerV1beta1().Canaries(ca.Namespace).Update(context.Background(), ca, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("suspend canary %s.%s failed %w", ca.Name, ca.Namespace, err)
+	}
+	updated.DeepCopyInto(canary)
+	return nil
 }
 
 func (rsr *RollingUpdateSmiRouter) updateReplicas(canary *v1beta1.Canary, name string, replicas *int32) error {
@@ -90,11 +90,15 @@ func (rsr *RollingUpdateSmiRouter) updateReplicas(canary *v1beta1.Canary, name s
 
 func (rsr *RollingUpdateSmiRouter) GetRoutes(canary *v1beta1.Canary) (primaryWeight int, canaryWeight int, mirrored bool, err error) {
 	if internal.IsRollingUpdate(canary) {
+		if canary.GetAnalysis() == nil {
+			err = fmt.Errorf("get route of canary %s.%s failed, the analysis is not set", canary.Name, canary.Namespace)
+			return
+		}
 		canaryName := canary.Spec.TargetRef.Name
 		if cd, err := rsr.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.Background(), canaryName, metav1.GetOptions{}); err != nil {
 			err = fmt.Errorf("canary %s.%s is not exist %w", canaryName, canary.Namespace, err)
 		} else {
-			canaryWeight = percentOf(int(cd.Status.ReadyReplicas), canary.Spec.Analysis.MaxReplicas)
+			canaryWeight = percentOf(int(cd.Status.ReadyReplicas), canary.GetAnalysis().MaxReplicas)
 			if canaryWeight > hundred {
 				canaryWeight = hundred
 			}
@@ -110,16 +114,25 @@ func (rsr *RollingUpdateSmiRouter) Finalize(canary *v1beta1.Canary) error {
 	return nil
 }
 
+// percentOf returns the percentage of part in total,
+// the result is kept in the [0, 100] range
 func percentOf(part int, total int) int {
-	if part > total {
-		panic(fmt.Errorf("part: %d can't be great than total: %d", part, total))
+	if total <= 0 || part <= 0 {
+		return 0
+	}
+	if part >= total {
+		return hundred
 	}
 	return int((float64(part) * float64(hundred)) / float64(total))
 }
 
+// percent returns the percent share of all rounded up,
+// a percent outside of the [0, 100] range is clamped
 func percent(percent int, all int) int {
-	if percent > hundred || percent < 0 {
-		panic(fmt.Errorf("percent: %d cant't be less than 0 or greater than 100", percent))
+	if percent < 0 {
+		percent = 0
+	} else if percent > hundred {
+		percent = hundred
 	}
 	return int(math.Ceil((float64(all) * float64(percent)) / float64(hundred)))
 }
@@ -1,6 +1,15 @@
 package router
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
 
 func TestPercent(t *testing.T) {
 	r := percent(33, 3)
@@ -34,12 +43,12 @@ func TestPercent(t *testing.T) {
 
 func TestPercentOf(t *testing.T) {
 	r := percentOf(1, 3)
-	if r != 34 {
+	if r != 33 {
 		t.Fatalf("unexpected result of `percentOf(1, 3)` is %d", r)
 	}
 
 	r = percentOf(2, 3)
-	if r != 67 {
+	if r != 66 {
 		t.Fatalf("unexpected result of `percentOf(2, 3)` is %d", r)
 	}
 
@@ -53,3 +62,60 @@ func TestPercentOf(t *testing.T) {
 		t.Fatalf("unexpected result of `percentOf(3, 3)` is %d", r)
 	}
 }
+
+func TestPercentOutOfRange(t *testing.T) {
+	r := percent(-10, 3)
+	if r != 0 {
+		t.Fatalf("unexpected result of `percent(-10, 3)` is %d", r)
+	}
+
+	r = percent(120, 3)
+	if r != 3 {
+		t.Fatalf("unexpected result of `percent(120, 3)` is %d", r)
+	}
+
+	r = percentOf(4, 3)
+	if r != 100 {
+		t.Fatalf("unexpected result of `percentOf(4, 3)` is %d", r)
+	}
+
+	r = percentOf(1, 0)
+	if r != 0 {
+		t.Fatalf("unexpected result of `percentOf(1, 0)` is %d", r)
+	}
+}
+
+func TestRollingUpdateSmiRouter_SetRoutes(t *testing.T) {
+	canary := newTestCanary()
+	canary.Spec.SourceRef = &flaggerv1.CrossNamespaceObjectReference{Name: "podinfo-source"}
+	canary.Spec.Analysis.StepReplicas = 1
+	canary.Spec.Analysis.MaxReplicas = 4
+	mocks := newFixture(canary)
+
+	source := newTestDeployment()
+	source.Name = "podinfo-source"
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(), source, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	router := &RollingUpdateSmiRouter{SmiRouter: &SmiRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+	}}
+
+	// the analysis is suspended until the replicas are reconciled
+	err = router.SetRoutes(mocks.canary, 50, 50, false)
+	require.NoError(t, err)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *dep.Spec.Replicas)
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-source", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *dep.Spec.Replicas)
+
+	cd, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, cd.Spec.Suspend)
+	assert.Empty(t, cd.Spec.Analysis.Webhooks)
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	// ValidatePath is the path of the validating admission webhook
+	ValidatePath = "/validate"
+
+	// MutatePath is the path of the mutating admission webhook
+	MutatePath = "/mutate"
+)
+
+// jsonPatchOp is a RFC 6902 JSON patch operation
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// admitFunc validates or mutates the object of an admission request
+type admitFunc func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
+
+// NewAdmissionHandler returns a handler serving the validating and mutating admission webhooks
+// for the Flagger custom resources, the canaries without a provider are validated against the mesh provider
+func NewAdmissionHandler(meshProvider string, logger *zap.SugaredLogger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ValidatePath, func(w http.ResponseWriter, r *http.Request) {
+		serveAdmission(w, r, logger, func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+			return validate(req, meshProvider)
+		})
+	})
+	mux.HandleFunc(MutatePath, func(w http.ResponseWriter, r *http.Request) {
+		serveAdmission(w, r, logger, mutate)
+	})
+	return mux
+}
+
+func serveAdmission(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, admit admitFunc) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading the request body failed: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, fmt.Sprintf("unmarshaling the admission review failed: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "admission review request is empty", http.StatusBadRequest)
+		return
+	}
+
+	resp := admit(review.Request)
+	resp.UID = review.Request.UID
+	if !resp.Allowed {
+		logger.With("admission", fmt.Sprintf("%s.%s", review.Request.Name, review.Request.Namespace)).
+			Infof("%s %s denied: %s", review.Request.Kind.Kind, r.URL.Path, resp.Result.Message)
+	}
+
+	out, err := json.Marshal(&admissionv1.AdmissionReview{
+		TypeMeta: review.TypeMeta,
+		Response: resp,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("marshaling the admission review failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out)
+}
+
+// validate rejects the Canary, MetricTemplate, AlertProvider, CanaryGroup and analysis template objects
+// with an inconsistent spec
+func validate(req *admissionv1.AdmissionRequest, meshProvider string) *admissionv1.AdmissionResponse {
+	if req.Operation == admissionv1.Delete {
+		return allowed()
+	}
+
+	var errs field.ErrorList
+	switch req.Kind.Kind {
+	case flaggerv1.CanaryKind:
+		cd := &flaggerv1.Canary{}
+		if err := json.Unmarshal(req.Object.Raw, cd); err != nil {
+			return denied(fmt.Sprintf("canary unmarshal error: %v", err))
+		}
+		errs = ValidateCanary(cd, meshProvider)
+	case flaggerv1.MetricTemplateKind:
+		mt := &flaggerv1.MetricTemplate{}
+		if err := json.Unmarshal(req.Object.Raw, mt); err != nil {
+			return denied(fmt.Sprintf("metric template unmarshal error: %v", err))
+		}
+		errs = ValidateMetricTemplate(mt)
+	case flaggerv1.AlertProviderKind:
+		ap := &flaggerv1.AlertProvider{}
+		if err := json.Unmarshal(req.Object.Raw, ap); err != nil {
+			return denied(fmt.Sprintf("alert provider unmarshal error: %v", err))
+		}
+		errs = ValidateAlertProvider(ap)
+	case flaggerv1.CanaryGroupKind:
+		group := &flaggerv1.CanaryGroup{}
+		if err := json.Unmarshal(req.Object.Raw, group); err != nil {
+			return denied(fmt.Sprintf("canary group unmarshal error: %v", err))
+		}
+		errs = ValidateCanaryGroup(group)
+	case flaggerv1.AnalysisTemplateKind:
+		template := &flaggerv1.AnalysisTemplate{}
+		if err := json.Unmarshal(req.Object.Raw, template); err != nil {
+			return denied(fmt.Sprintf("analysis template unmarshal error: %v", err))
+		}
+		errs = ValidateAnalysisTemplate(template.Spec)
+	case flaggerv1.ClusterAnalysisTemplateKind:
+		template := &flaggerv1.ClusterAnalysisTemplate{}
+		if err := json.Unmarshal(req.Object.Raw, template); err != nil {
+			return denied(fmt.Sprintf("cluster analysis template unmarshal error: %v", err))
+		}
+		errs = ValidateAnalysisTemplate(template.Spec)
+	}
+
+	if len(errs) > 0 {
+		return denied(errs.ToAggregate().Error())
+	}
+	return allowed()
+}
+
+// mutate sets the defaults of the Canary objects and records the user that requested a canary action
+func mutate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Kind.Kind != flaggerv1.CanaryKind || req.Operation == admissionv1.Delete {
+		return allowed()
+	}
+
+	cd := &flaggerv1.Canary{}
+	if err := json.Unmarshal(req.Object.Raw, cd); err != nil {
+		return denied(fmt.Sprintf("canary unmarshal error: %v", err))
+	}
+
+	patch := DefaultCanary(cd)
+	patch = append(patch, actionByPatch(req, cd)...)
+	if len(patch) == 0 {
+		return allowed()
+	}
+
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return denied(fmt.Sprintf("canary patch marshal error: %v", err))
+	}
+
+	resp := allowed()
+	patchType := admissionv1.PatchTypeJSONPatch
+	resp.Patch = b
+	resp.PatchType = &patchType
+	return resp
+}
+
+// actionByPatch records the user that requested a new canary action in the flagger.app/action-by annotation
+func actionByPatch(req *admissionv1.AdmissionRequest, cd *flaggerv1.Canary) []jsonPatchOp {
+	action, ok := cd.Annotations[flaggerv1.ActionAnnotation]
+	if !ok {
+		return nil
+	}
+	if req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		old := &flaggerv1.Canary{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err == nil {
+			if oldAction, ok := old.Annotations[flaggerv1.ActionAnnotation]; ok && oldAction == action {
+				return nil
+			}
+		}
+	}
+
+	path := "/metadata/annotations/" + strings.ReplaceAll(flaggerv1.ActionByAnnotation, "/", "~1")
+	return []jsonPatchOp{{Op: "add", Path: path, Value: req.UserInfo.Username}}
+}
+
+func allowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func denied(message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: message,
+			Code:    http.StatusUnprocessableEntity,
+		},
+	}
+}
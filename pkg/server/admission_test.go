@@ -0,0 +1,453 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/weaveworks/flagger/pkg/apis/edas/v1alpha1/route"
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/logger"
+)
+
+func newTestCanary() *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		TypeMeta: metav1.TypeMeta{APIVersion: flaggerv1.SchemeGroupVersion.String(), Kind: flaggerv1.CanaryKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "podinfo",
+		},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.CrossNamespaceObjectReference{
+				Name:       "podinfo",
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+			},
+			Service: flaggerv1.CanaryService{
+				Port: 9898,
+			},
+			Analysis: &flaggerv1.CanaryAnalysis{
+				MaxWeight:  50,
+				StepWeight: 10,
+			},
+		},
+	}
+}
+
+func newTestReview(t *testing.T, kind string, obj runtime.Object) *admissionv1.AdmissionReview {
+	b, err := json.Marshal(obj)
+	require.NoError(t, err)
+	return &admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "test",
+			Kind:      metav1.GroupVersionKind{Group: flaggerv1.SchemeGroupVersion.Group, Version: flaggerv1.SchemeGroupVersion.Version, Kind: kind},
+			Name:      "podinfo",
+			Namespace: "default",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: b},
+		},
+	}
+}
+
+func postReview(t *testing.T, path string, review *admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
+	return postMeshReview(t, "", path, review)
+}
+
+func postMeshReview(t *testing.T, meshProvider string, path string, review *admissionv1.AdmissionReview) *admissionv1.AdmissionResponse {
+	logger, _ := logger.NewLogger("debug")
+	handler := NewAdmissionHandler(meshProvider, logger)
+
+	b, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	out := &admissionv1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+	require.NotNil(t, out.Response)
+	assert.Equal(t, review.Request.UID, out.Response.UID)
+	return out.Response
+}
+
+func TestAdmission_ValidateCanary(t *testing.T) {
+	resp := postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, newTestCanary()))
+	assert.True(t, resp.Allowed)
+
+	// the pod metrics can be compared without a template
+	cd := newTestCanary()
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:       "pod-restarts",
+		Comparison: &flaggerv1.CanaryMetricComparison{Mode: flaggerv1.ComparisonDifference},
+	}}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	assert.True(t, resp.Allowed)
+
+	// the Dubbo conditions are routed by the EDAS provider
+	cd = newTestCanary()
+	cd.Spec.Provider = flaggerv1.EDASProvider
+	cd.Spec.Analysis.DubboMatch = []route.DubboMatchRequest{testDubboMatch()}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	assert.True(t, resp.Allowed)
+
+	cd = newTestCanary()
+	cd.Spec.Provider = flaggerv1.OAMProvider
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	assert.True(t, resp.Allowed)
+
+	// the canaries without a provider are routed by the mesh provider
+	cd = newTestCanary()
+	cd.Spec.Analysis.DubboMatch = []route.DubboMatchRequest{testDubboMatch()}
+	resp = postMeshReview(t, flaggerv1.EDASProvider, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	assert.True(t, resp.Allowed)
+	resp = postMeshReview(t, flaggerv1.IstioProvider, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	assert.False(t, resp.Allowed)
+
+	tests := map[string]func(cd *flaggerv1.Canary){
+		"stepReplicas without maxReplicas": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.StepReplicas = 1
+		},
+		"canaryWeight above 100": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.CanaryWeight = 120
+		},
+		"unknown provider": func(cd *flaggerv1.Canary) {
+			cd.Spec.Provider = "envoy"
+		},
+		"invalid interval": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Interval = "1 minute"
+		},
+		"step approval without confirm-step webhook": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Steps = []flaggerv1.CanaryStep{{Weight: 10, Approval: true}}
+		},
+		"invalid step pause": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Steps = []flaggerv1.CanaryStep{{Weight: 10, Pause: "forever"}}
+		},
+		"unknown schedule time zone": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Schedule = &flaggerv1.CanarySchedule{
+				Allowed: []flaggerv1.CanaryTimeWindow{{Start: "09:00", End: "17:00", TimeZone: "Mars/Olympus"}},
+			}
+		},
+		"invalid schedule day": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Schedule = &flaggerv1.CanarySchedule{
+				Blackout: []flaggerv1.CanaryTimeWindow{{Days: []string{"Friday"}}},
+			}
+		},
+		"comparison of an inline query": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+				Name:       "latency",
+				Query:      "histogram_quantile(0.99, rate(latency_bucket[1m]))",
+				Comparison: &flaggerv1.CanaryMetricComparison{Mode: flaggerv1.ComparisonRatio, Tolerance: 10},
+			}}
+		},
+		"unknown comparison mode": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+				Name:       "request-duration",
+				Comparison: &flaggerv1.CanaryMetricComparison{Mode: "percent", Tolerance: 10},
+			}}
+		},
+		"invalid metric timeout": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+				Name:    "request-success-rate",
+				Timeout: "0s",
+			}}
+		},
+		"retry policy without retries": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+				Name:     "request-success-rate",
+				OnNoData: &flaggerv1.CanaryMetricPolicy{Action: flaggerv1.MetricPolicyRetry},
+			}}
+		},
+		"unknown error policy": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+				Name:    "request-success-rate",
+				OnError: &flaggerv1.CanaryMetricPolicy{Action: "ignore"},
+			}}
+		},
+		"passScore above 100": func(cd *flaggerv1.Canary) {
+			passScore := float64(120)
+			cd.Spec.Analysis.PassScore = &passScore
+		},
+		"judge marginal score above pass score": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Judge = &flaggerv1.CanaryJudge{PassScore: 80, MarginalScore: 90}
+		},
+		"invalid judge step": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.Judge = &flaggerv1.CanaryJudge{Step: "fast"}
+		},
+		"dubbo condition without the edas provider": func(cd *flaggerv1.Canary) {
+			cd.Spec.Provider = flaggerv1.IstioProvider
+			cd.Spec.Analysis.DubboMatch = []route.DubboMatchRequest{testDubboMatch()}
+		},
+		"spring cloud condition without the edas provider": func(cd *flaggerv1.Canary) {
+			cd.Spec.Analysis.SpringCloudMatch = []route.SpringCloudMatchRequest{{
+				Path: "/hello",
+				Conditions: []route.SpringCloudCondition{
+					{Strategy: "HEADER", Key: "user", Operator: route.OperatorEqual, Values: []string{"test"}},
+				},
+			}}
+		},
+		"malformed dubbo condition": func(cd *flaggerv1.Canary) {
+			cd.Spec.Provider = flaggerv1.EDASProvider
+			cd.Spec.Analysis.DubboMatch = []route.DubboMatchRequest{
+				{
+					ServiceName: "com.example.DemoService",
+					MethodName:  "sayHello",
+					Conditions: []route.DubboCondition{
+						{ParamIndex: 2, Operator: route.OperatorEqual, Values: []string{"canary"}},
+					},
+				},
+			}
+		},
+		"malformed spring cloud condition": func(cd *flaggerv1.Canary) {
+			cd.Spec.Provider = flaggerv1.EDASProvider
+			cd.Spec.Analysis.SpringCloudMatch = []route.SpringCloudMatchRequest{
+				{
+					Path: "/hello",
+					Conditions: []route.SpringCloudCondition{
+						{Strategy: "BODY", Key: "user", Operator: route.OperatorEqual, Values: []string{"test"}},
+					},
+				},
+			}
+		},
+	}
+
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			cd := newTestCanary()
+			mutate(cd)
+			resp := postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+			assert.False(t, resp.Allowed)
+			assert.NotEmpty(t, resp.Result.Message)
+		})
+	}
+}
+
+func testDubboMatch() route.DubboMatchRequest {
+	return route.DubboMatchRequest{
+		ServiceName: "com.example.DemoService",
+		MethodName:  "sayHello",
+		ParamTypes:  []string{"java.lang.String"},
+		Conditions: []route.DubboCondition{
+			{ParamIndex: 0, Operator: route.OperatorEqual, Values: []string{"canary"}},
+		},
+	}
+}
+
+func TestAdmission_ValidateProviders(t *testing.T) {
+	mt := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "latency", Namespace: "default"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: "http://prometheus:9090"},
+			Query:    "sum(rate(http_requests_total[1m]))",
+		},
+	}
+	resp := postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, mt))
+	assert.True(t, resp.Allowed)
+
+	mt.Spec.Provider.Type = "influxdb"
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, mt))
+	assert.True(t, resp.Allowed)
+
+	mt.Spec.Provider.Type = ""
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, mt))
+	assert.False(t, resp.Allowed)
+
+	web := mt.DeepCopy()
+	web.Spec.Query = ""
+	web.Spec.Provider = flaggerv1.MetricTemplateProvider{
+		Type:    "web",
+		Address: "http://slo-api/{{ target }}",
+		Web:     &flaggerv1.MetricTemplateWebRequest{JSONPath: ".data.value"},
+	}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, web))
+	assert.True(t, resp.Allowed)
+
+	web.Spec.Provider.Web.JSONPath = "{.data[}"
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, web))
+	assert.False(t, resp.Allowed)
+
+	es := mt.DeepCopy()
+	es.Spec.Provider = flaggerv1.MetricTemplateProvider{
+		Type:          "elasticsearch",
+		Address:       "http://elasticsearch:9200",
+		Elasticsearch: &flaggerv1.MetricTemplateElasticsearchRequest{Index: "logs-*"},
+	}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, es))
+	assert.True(t, resp.Allowed)
+
+	es.Spec.Provider.Elasticsearch.Index = ""
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, es))
+	assert.False(t, resp.Allowed)
+
+	graphite := mt.DeepCopy()
+	graphite.Spec.Provider = flaggerv1.MetricTemplateProvider{
+		Type:     "graphite",
+		Address:  "http://graphite",
+		Graphite: &flaggerv1.MetricTemplateGraphiteRequest{Aggregation: "max"},
+	}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, graphite))
+	assert.True(t, resp.Allowed)
+
+	graphite.Spec.Provider.Graphite.Aggregation = "median"
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, graphite))
+	assert.False(t, resp.Allowed)
+
+	sls := mt.DeepCopy()
+	sls.Spec.Provider = flaggerv1.MetricTemplateProvider{
+		Type:      "sls",
+		Address:   "https://cn-hangzhou.log.aliyuncs.com",
+		SecretRef: &corev1.LocalObjectReference{Name: "alibaba"},
+		SLS:       &flaggerv1.MetricTemplateSLSRequest{Project: "edge", Logstore: "access"},
+	}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, sls))
+	assert.True(t, resp.Allowed)
+
+	sls.Spec.Provider.SecretRef = nil
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, sls))
+	assert.False(t, resp.Allowed)
+
+	mt.Spec.Provider.Type = "graphite-legacy"
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.MetricTemplateKind, mt))
+	assert.False(t, resp.Allowed)
+
+	ap := &flaggerv1.AlertProvider{
+		ObjectMeta: metav1.ObjectMeta{Name: "on-call", Namespace: "default"},
+		Spec: flaggerv1.AlertProviderSpec{
+			Type:      "slack",
+			SecretRef: &corev1.LocalObjectReference{Name: "slack-url"},
+		},
+	}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.AlertProviderKind, ap))
+	assert.True(t, resp.Allowed)
+
+	ap.Spec.Type = "pager"
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.AlertProviderKind, ap))
+	assert.False(t, resp.Allowed)
+}
+
+func TestAdmission_MutateCanary(t *testing.T) {
+	resp := postReview(t, MutatePath, newTestReview(t, flaggerv1.CanaryKind, newTestCanary()))
+	require.True(t, resp.Allowed)
+	require.NotNil(t, resp.PatchType)
+	assert.Equal(t, admissionv1.PatchTypeJSONPatch, *resp.PatchType)
+
+	var patch []jsonPatchOp
+	require.NoError(t, json.Unmarshal(resp.Patch, &patch))
+	assert.Equal(t, []jsonPatchOp{
+		{Op: "add", Path: "/spec/service/portName", Value: "http"},
+		{Op: "add", Path: "/spec/analysis/interval", Value: "1m0s"},
+		{Op: "add", Path: "/spec/analysis/threshold", Value: float64(1)},
+	}, patch)
+
+	cd := newTestCanary()
+	cd.Spec.Service.PortName = "grpc"
+	cd.Spec.Analysis.Interval = "30s"
+	cd.Spec.Analysis.Threshold = 5
+	resp = postReview(t, MutatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	require.True(t, resp.Allowed)
+	assert.Nil(t, resp.Patch)
+
+	// the interval and threshold are left to the analysis templates
+	cd = newTestCanary()
+	cd.Spec.Service.PortName = "grpc"
+	cd.Spec.Analysis.TemplateRefs = []flaggerv1.AnalysisTemplateReference{{Name: "slo"}}
+	resp = postReview(t, MutatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	require.True(t, resp.Allowed)
+	assert.Nil(t, resp.Patch)
+}
+
+func TestAdmission_MutateCanaryAction(t *testing.T) {
+	cd := newTestCanary()
+	cd.Spec.Service.PortName = "http"
+	cd.Spec.Analysis.Interval = "1m"
+	cd.Spec.Analysis.Threshold = 5
+	old, err := json.Marshal(cd)
+	require.NoError(t, err)
+
+	cd.Annotations = map[string]string{flaggerv1.ActionAnnotation: string(flaggerv1.CanaryActionPromote)}
+	review := newTestReview(t, flaggerv1.CanaryKind, cd)
+	review.Request.Operation = admissionv1.Update
+	review.Request.OldObject = runtime.RawExtension{Raw: old}
+	review.Request.UserInfo.Username = "jane"
+
+	resp := postReview(t, MutatePath, review)
+	require.True(t, resp.Allowed)
+
+	var patch []jsonPatchOp
+	require.NoError(t, json.Unmarshal(resp.Patch, &patch))
+	assert.Equal(t, []jsonPatchOp{
+		{Op: "add", Path: "/metadata/annotations/flagger.app~1action-by", Value: "jane"},
+	}, patch)
+
+	// the actor is kept when the action is unchanged
+	review.Request.OldObject = review.Request.Object
+	resp = postReview(t, MutatePath, review)
+	require.True(t, resp.Allowed)
+	assert.Nil(t, resp.Patch)
+
+	cd.Annotations[flaggerv1.ActionAnnotation] = "restart"
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "flagger.app/action")
+}
+
+func TestAdmission_ValidateAnalysisTemplate(t *testing.T) {
+	template := &flaggerv1.ClusterAnalysisTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "slo"},
+		Spec: flaggerv1.AnalysisTemplateSpec{
+			Analysis: flaggerv1.CanaryAnalysis{
+				Metrics: []flaggerv1.CanaryMetric{{Name: "request-success-rate", Interval: "1m"}},
+			},
+		},
+	}
+	resp := postReview(t, ValidatePath, newTestReview(t, flaggerv1.ClusterAnalysisTemplateKind, template))
+	assert.True(t, resp.Allowed)
+
+	template.Spec.Analysis.TemplateRefs = []flaggerv1.AnalysisTemplateReference{{Name: "base"}}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.ClusterAnalysisTemplateKind, template))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "spec.analysis.templateRefs")
+
+	cd := newTestCanary()
+	cd.Spec.Analysis.TemplateRefs = []flaggerv1.AnalysisTemplateReference{{Name: "slo", Kind: "MetricTemplate"}}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryKind, cd))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "spec.analysis.templateRefs[0].kind")
+}
+
+func TestAdmission_ValidateCanaryGroup(t *testing.T) {
+	group := &flaggerv1.CanaryGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "default"},
+		Spec: flaggerv1.CanaryGroupSpec{
+			Members: []flaggerv1.CanaryGroupMember{
+				{Name: "gateway", DependsOn: []string{"backend"}},
+				{Name: "backend"},
+			},
+		},
+	}
+	resp := postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryGroupKind, group))
+	assert.True(t, resp.Allowed)
+
+	order, err := group.SortMembers()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "gateway"}, order)
+
+	group.Spec.Members[1].DependsOn = []string{"gateway"}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryGroupKind, group))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "dependency cycle")
+
+	group.Spec.Members[1].DependsOn = []string{"database"}
+	resp = postReview(t, ValidatePath, newTestReview(t, flaggerv1.CanaryGroupKind, group))
+	assert.False(t, resp.Allowed)
+}
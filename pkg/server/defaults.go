@@ -0,0 +1,35 @@
+package server
+
+import (
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const defaultPortName = "http"
+
+// DefaultCanary returns the JSON patch that sets the default values of the canary spec
+func DefaultCanary(cd *flaggerv1.Canary) []jsonPatchOp {
+	var patch []jsonPatchOp
+
+	if cd.Spec.Service.PortName == "" {
+		patch = append(patch, jsonPatchOp{Op: "add", Path: "/spec/service/portName", Value: defaultPortName})
+	}
+
+	// the analysis templates are merged by the controller, the defaults would override their values
+	analysis := cd.GetAnalysis()
+	if analysis == nil || len(analysis.TemplateRefs) > 0 {
+		return patch
+	}
+	analysisPath := "/spec/analysis"
+	if cd.Spec.Analysis == nil {
+		analysisPath = "/spec/canaryAnalysis"
+	}
+
+	if analysis.Interval == "" {
+		patch = append(patch, jsonPatchOp{Op: "add", Path: analysisPath + "/interval", Value: flaggerv1.AnalysisInterval.String()})
+	}
+	if analysis.Threshold == 0 {
+		patch = append(patch, jsonPatchOp{Op: "add", Path: analysisPath + "/threshold", Value: cd.GetAnalysisThreshold()})
+	}
+
+	return patch
+}
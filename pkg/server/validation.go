@@ -0,0 +1,484 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	flaggerv1 "github.com/weaveworks/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/weaveworks/flagger/pkg/metrics/observers"
+	"github.com/weaveworks/flagger/pkg/metrics/providers"
+)
+
+// meshProviders are the values accepted by spec.provider,
+// the versioned providers (e.g. smi:v1alpha2) are matched by prefix
+var meshProviders = []string{
+	flaggerv1.AppMeshProvider,
+	flaggerv1.LinkerdProvider,
+	flaggerv1.IstioProvider,
+	flaggerv1.SMIProvider,
+	flaggerv1.ContourProvider,
+	flaggerv1.GlooProvider,
+	flaggerv1.NGINXProvider,
+	flaggerv1.KubernetesProvider,
+	flaggerv1.SkipperProvider,
+	flaggerv1.EDASProvider,
+	flaggerv1.OAMProvider,
+}
+
+// metricProviders are the values accepted by the metric template spec.provider.type
+var metricProviders = []string{"prometheus", "datadog", "cloudwatch", "influxdb", "web", "elasticsearch", "graphite", "sls"}
+
+// webMethods are the values accepted by the metric template spec.provider.web.method
+var webMethods = []string{"GET", "POST"}
+
+// alertProviders are the values accepted by the alert provider spec.type
+var alertProviders = []string{"slack", "discord", "rocket", "msteams", "dingtalk"}
+
+// ValidateCanary checks the canary spec for inconsistencies that would only be noticed at runtime,
+// the mesh provider is the provider of the canaries that don't specify one
+func ValidateCanary(cd *flaggerv1.Canary, meshProvider string) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if cd.Spec.TargetRef.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("targetRef", "name"), ""))
+	}
+	if cd.Spec.SourceRef != nil && cd.Spec.SourceRef.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("sourceRef", "name"), ""))
+	}
+	if cd.Spec.Provider != "" && !isMeshProvider(cd.Spec.Provider) {
+		errs = append(errs, field.NotSupported(specPath.Child("provider"), cd.Spec.Provider, meshProviders))
+	}
+
+	if action, ok := cd.Annotations[flaggerv1.ActionAnnotation]; ok && !flaggerv1.CanaryAction(action).IsValid() {
+		errs = append(errs, field.NotSupported(field.NewPath("metadata", "annotations").Key(flaggerv1.ActionAnnotation),
+			action, canaryActions()))
+	}
+
+	if cd.Spec.PodSelector != nil {
+		for i, label := range cd.Spec.PodSelector.DistinguishLabels {
+			if label == "" {
+				errs = append(errs, field.Required(specPath.Child("podSelector", "distinguishLabels").Index(i), ""))
+			}
+		}
+		for i, label := range cd.Spec.PodSelector.GeneralLabels {
+			if label == "" {
+				errs = append(errs, field.Required(specPath.Child("podSelector", "generalLabels").Index(i), ""))
+			}
+		}
+	}
+
+	if cd.GetAnalysis() == nil {
+		return errs
+	}
+	analysisPath := specPath.Child("analysis")
+	if cd.Spec.Analysis == nil {
+		analysisPath = specPath.Child("canaryAnalysis")
+	}
+	errs = append(errs, validateAnalysis(cd.GetAnalysis(), analysisPath)...)
+
+	// the Dubbo and Spring Cloud conditions are ignored by the other routers
+	provider := cd.Spec.Provider
+	if provider == "" {
+		provider = meshProvider
+	}
+	if provider != flaggerv1.EDASProvider {
+		if len(cd.GetAnalysis().DubboMatch) > 0 {
+			errs = append(errs, field.Invalid(analysisPath.Child("dubboMatch"), provider,
+				fmt.Sprintf("requires the %s provider", flaggerv1.EDASProvider)))
+		}
+		if len(cd.GetAnalysis().SpringCloudMatch) > 0 {
+			errs = append(errs, field.Invalid(analysisPath.Child("springCloudMatch"), provider,
+				fmt.Sprintf("requires the %s provider", flaggerv1.EDASProvider)))
+		}
+	}
+	return errs
+}
+
+func validateAnalysis(analysis *flaggerv1.CanaryAnalysis, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	for i, ref := range analysis.TemplateRefs {
+		refPath := path.Child("templateRefs").Index(i)
+		if ref.Name == "" {
+			errs = append(errs, field.Required(refPath.Child("name"), ""))
+		}
+		if ref.Kind != "" && ref.Kind != flaggerv1.AnalysisTemplateKind && ref.Kind != flaggerv1.ClusterAnalysisTemplateKind {
+			errs = append(errs, field.NotSupported(refPath.Child("kind"), ref.Kind,
+				[]string{flaggerv1.AnalysisTemplateKind, flaggerv1.ClusterAnalysisTemplateKind}))
+		}
+	}
+
+	if analysis.Interval != "" {
+		if _, err := time.ParseDuration(analysis.Interval); err != nil {
+			errs = append(errs, field.Invalid(path.Child("interval"), analysis.Interval, err.Error()))
+		}
+	}
+
+	if analysis.StepReplicas < 0 {
+		errs = append(errs, field.Invalid(path.Child("stepReplicas"), analysis.StepReplicas, "must be greater than or equal to 0"))
+	}
+	if analysis.StepReplicas > 0 && analysis.MaxReplicas <= 0 {
+		errs = append(errs, field.Required(path.Child("maxReplicas"), "maxReplicas is required when stepReplicas is set"))
+	}
+	if analysis.MaxReplicas > 0 && analysis.StepReplicas > analysis.MaxReplicas {
+		errs = append(errs, field.Invalid(path.Child("stepReplicas"), analysis.StepReplicas, "must be less than or equal to maxReplicas"))
+	}
+
+	weights := []struct {
+		name  string
+		value int
+	}{
+		{"canaryWeight", analysis.CanaryWeight},
+		{"maxWeight", analysis.MaxWeight},
+		{"stepWeight", analysis.StepWeight},
+		{"stepWeightPromotion", analysis.StepWeightPromotion},
+		{"mirrorWeight", analysis.MirrorWeight},
+	}
+	for _, w := range weights {
+		if w.value < 0 || w.value > 100 {
+			errs = append(errs, field.Invalid(path.Child(w.name), w.value, "must be in the range [0, 100]"))
+		}
+	}
+
+	hasConfirmStepHook := false
+	for _, webhook := range analysis.Webhooks {
+		if webhook.Type == flaggerv1.ConfirmStepHook {
+			hasConfirmStepHook = true
+		}
+	}
+	for i, step := range analysis.Steps {
+		stepPath := path.Child("steps").Index(i)
+		if step.Weight < 0 || step.Weight > 100 {
+			errs = append(errs, field.Invalid(stepPath.Child("weight"), step.Weight, "must be in the range [0, 100]"))
+		}
+		if step.Replicas < 0 || (analysis.MaxReplicas > 0 && step.Replicas > analysis.MaxReplicas) {
+			errs = append(errs, field.Invalid(stepPath.Child("replicas"), step.Replicas, "must be in the range [0, maxReplicas]"))
+		}
+		if step.Pause != "" {
+			if _, err := time.ParseDuration(step.Pause); err != nil {
+				errs = append(errs, field.Invalid(stepPath.Child("pause"), step.Pause, err.Error()))
+			}
+		}
+		if step.Approval && !hasConfirmStepHook {
+			errs = append(errs, field.Invalid(stepPath.Child("approval"), step.Approval, "requires a confirm-step webhook"))
+		}
+	}
+
+	if analysis.Schedule != nil {
+		windows := []struct {
+			name  string
+			value []flaggerv1.CanaryTimeWindow
+		}{
+			{"allowed", analysis.Schedule.Allowed},
+			{"blackout", analysis.Schedule.Blackout},
+		}
+		for _, w := range windows {
+			for i, window := range w.value {
+				if _, err := window.Contains(time.Now()); err != nil {
+					errs = append(errs, field.Invalid(path.Child("schedule", w.name).Index(i), window, err.Error()))
+				}
+			}
+		}
+	}
+
+	if analysis.PassScore != nil && (*analysis.PassScore < 0 || *analysis.PassScore > 100) {
+		errs = append(errs, field.Invalid(path.Child("passScore"), *analysis.PassScore, "must be in the range [0, 100]"))
+	}
+
+	if analysis.Judge != nil {
+		errs = append(errs, validateJudge(analysis.Judge, path.Child("judge"))...)
+	}
+
+	for i, match := range analysis.DubboMatch {
+		if err := match.Validate(); err != nil {
+			errs = append(errs, field.Invalid(path.Child("dubboMatch").Index(i), match.ServiceName, err.Error()))
+		}
+	}
+	for i, match := range analysis.SpringCloudMatch {
+		if err := match.Validate(); err != nil {
+			errs = append(errs, field.Invalid(path.Child("springCloudMatch").Index(i), match.Path, err.Error()))
+		}
+	}
+
+	for i, metric := range analysis.Metrics {
+		metricPath := path.Child("metrics").Index(i)
+		if metric.Name == "" {
+			errs = append(errs, field.Required(metricPath.Child("name"), ""))
+		}
+		if metric.Interval != "" {
+			if _, err := time.ParseDuration(metric.Interval); err != nil {
+				errs = append(errs, field.Invalid(metricPath.Child("interval"), metric.Interval, err.Error()))
+			}
+		}
+		if metric.Timeout != "" {
+			if d, err := time.ParseDuration(metric.Timeout); err != nil {
+				errs = append(errs, field.Invalid(metricPath.Child("timeout"), metric.Timeout, err.Error()))
+			} else if d <= 0 {
+				errs = append(errs, field.Invalid(metricPath.Child("timeout"), metric.Timeout, "must be greater than 0"))
+			}
+		}
+		if metric.ThresholdRange != nil && metric.ThresholdRange.Min != nil && metric.ThresholdRange.Max != nil &&
+			*metric.ThresholdRange.Min > *metric.ThresholdRange.Max {
+			errs = append(errs, field.Invalid(metricPath.Child("thresholdRange"), *metric.ThresholdRange.Min, "min must be less than or equal to max"))
+		}
+		if metric.Comparison != nil {
+			errs = append(errs, validateComparison(metric, metricPath.Child("comparison"))...)
+		}
+		if metric.Weight != nil && *metric.Weight < 0 {
+			errs = append(errs, field.Invalid(metricPath.Child("weight"), *metric.Weight, "must be greater than or equal to 0"))
+		}
+		errs = append(errs, validateMetricPolicy(metric.OnNoData, metricPath.Child("onNoData"))...)
+		errs = append(errs, validateMetricPolicy(metric.OnError, metricPath.Child("onError"))...)
+	}
+
+	for i, webhook := range analysis.Webhooks {
+		if webhook.URL == "" {
+			errs = append(errs, field.Required(path.Child("webhooks").Index(i).Child("url"), ""))
+		}
+	}
+
+	return errs
+}
+
+func validateComparison(metric flaggerv1.CanaryMetric, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	comparison := metric.Comparison
+
+	if metric.TemplateRef == nil && metric.Name != "request-success-rate" && metric.Name != "request-duration" &&
+		!observers.IsPodMetric(metric.Name) {
+		errs = append(errs, field.Forbidden(path, "comparison requires a builtin metric or a templateRef"))
+	}
+	if comparison.Mode != flaggerv1.ComparisonRatio && comparison.Mode != flaggerv1.ComparisonDifference {
+		errs = append(errs, field.NotSupported(path.Child("mode"), comparison.Mode,
+			[]string{string(flaggerv1.ComparisonRatio), string(flaggerv1.ComparisonDifference)}))
+	}
+	if comparison.Direction != "" && comparison.Direction != flaggerv1.ComparisonLowerIsBetter &&
+		comparison.Direction != flaggerv1.ComparisonHigherIsBetter {
+		errs = append(errs, field.NotSupported(path.Child("direction"), comparison.Direction,
+			[]string{string(flaggerv1.ComparisonLowerIsBetter), string(flaggerv1.ComparisonHigherIsBetter)}))
+	}
+	if comparison.Tolerance < 0 {
+		errs = append(errs, field.Invalid(path.Child("tolerance"), comparison.Tolerance, "must be greater than or equal to 0"))
+	}
+	return errs
+}
+
+func validateMetricPolicy(policy *flaggerv1.CanaryMetricPolicy, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if policy == nil {
+		return errs
+	}
+
+	actions := []string{string(flaggerv1.MetricPolicyPass), string(flaggerv1.MetricPolicyFail),
+		string(flaggerv1.MetricPolicyHold), string(flaggerv1.MetricPolicyRetry)}
+	if !contains(actions, string(policy.Action)) {
+		errs = append(errs, field.NotSupported(path.Child("action"), policy.Action, actions))
+	}
+	if policy.Action == flaggerv1.MetricPolicyRetry && policy.Retries < 1 {
+		errs = append(errs, field.Invalid(path.Child("retries"), policy.Retries, "must be greater than 0 for retry"))
+	}
+	if policy.Action != flaggerv1.MetricPolicyRetry && policy.Retries != 0 {
+		errs = append(errs, field.Forbidden(path.Child("retries"), "only allowed for retry"))
+	}
+	return errs
+}
+
+func validateJudge(judge *flaggerv1.CanaryJudge, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if judge.Step != "" {
+		if step, err := time.ParseDuration(judge.Step); err != nil {
+			errs = append(errs, field.Invalid(path.Child("step"), judge.Step, err.Error()))
+		} else if step <= 0 {
+			errs = append(errs, field.Invalid(path.Child("step"), judge.Step, "must be greater than 0"))
+		}
+	}
+	if judge.Confidence < 0 || judge.Confidence >= 100 {
+		errs = append(errs, field.Invalid(path.Child("confidence"), judge.Confidence, "must be in the range [0, 100)"))
+	}
+	for _, score := range []struct {
+		name  string
+		value float64
+	}{
+		{"passScore", judge.PassScore},
+		{"marginalScore", judge.MarginalScore},
+	} {
+		if score.value < 0 || score.value > 100 {
+			errs = append(errs, field.Invalid(path.Child(score.name), score.value, "must be in the range [0, 100]"))
+		}
+	}
+	if judge.GetMarginalScore() > judge.GetPassScore() {
+		errs = append(errs, field.Invalid(path.Child("marginalScore"), judge.GetMarginalScore(),
+			"must be less than or equal to passScore"))
+	}
+	return errs
+}
+
+// ValidateMetricTemplate checks the metric template provider and query
+func ValidateMetricTemplate(mt *flaggerv1.MetricTemplate) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if mt.Spec.Provider.Type == "" {
+		errs = append(errs, field.Required(specPath.Child("provider", "type"), ""))
+	} else if !contains(metricProviders, mt.Spec.Provider.Type) {
+		errs = append(errs, field.NotSupported(specPath.Child("provider", "type"), mt.Spec.Provider.Type, metricProviders))
+	}
+	if mt.Spec.Provider.Type == "web" {
+		errs = append(errs, validateWebRequest(mt.Spec.Provider, specPath.Child("provider"))...)
+	} else if mt.Spec.Query == "" {
+		errs = append(errs, field.Required(specPath.Child("query"), ""))
+	}
+	if mt.Spec.Provider.Type == "elasticsearch" {
+		errs = append(errs, validateElasticsearchRequest(mt.Spec.Provider, specPath.Child("provider"))...)
+	}
+	if mt.Spec.Provider.Type == "sls" {
+		errs = append(errs, validateSLSRequest(mt.Spec.Provider, specPath.Child("provider"))...)
+	}
+	if graphite := mt.Spec.Provider.Graphite; graphite != nil && graphite.Aggregation != "" &&
+		!contains(providers.GraphiteAggregations, graphite.Aggregation) {
+		errs = append(errs, field.NotSupported(specPath.Child("provider", "graphite", "aggregation"),
+			graphite.Aggregation, providers.GraphiteAggregations))
+	}
+	return errs
+}
+
+// validateWebRequest checks the address, the method and the JSONPath expression of the web provider
+func validateWebRequest(provider flaggerv1.MetricTemplateProvider, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if provider.Address == "" {
+		errs = append(errs, field.Required(path.Child("address"), ""))
+	}
+	if provider.Web == nil {
+		return append(errs, field.Required(path.Child("web"), ""))
+	}
+
+	webPath := path.Child("web")
+	if provider.Web.Method != "" && !contains(webMethods, provider.Web.Method) {
+		errs = append(errs, field.NotSupported(webPath.Child("method"), provider.Web.Method, webMethods))
+	}
+	if provider.Web.JSONPath == "" {
+		errs = append(errs, field.Required(webPath.Child("jsonPath"), ""))
+	} else if _, err := providers.ParseJSONPath(provider.Web.JSONPath); err != nil {
+		errs = append(errs, field.Invalid(webPath.Child("jsonPath"), provider.Web.JSONPath, err.Error()))
+	}
+	return errs
+}
+
+// validateElasticsearchRequest checks the address, the index and the JSONPath expression of the elasticsearch provider
+func validateElasticsearchRequest(provider flaggerv1.MetricTemplateProvider, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if provider.Address == "" {
+		errs = append(errs, field.Required(path.Child("address"), ""))
+	}
+	if provider.Elasticsearch == nil {
+		return append(errs, field.Required(path.Child("elasticsearch"), ""))
+	}
+
+	esPath := path.Child("elasticsearch")
+	if provider.Elasticsearch.Index == "" {
+		errs = append(errs, field.Required(esPath.Child("index"), ""))
+	}
+	if provider.Elasticsearch.JSONPath != "" {
+		if _, err := providers.ParseJSONPath(provider.Elasticsearch.JSONPath); err != nil {
+			errs = append(errs, field.Invalid(esPath.Child("jsonPath"), provider.Elasticsearch.JSONPath, err.Error()))
+		}
+	}
+	return errs
+}
+
+// validateSLSRequest checks the endpoint, the log store and the credentials of the sls provider
+func validateSLSRequest(provider flaggerv1.MetricTemplateProvider, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if provider.Address == "" {
+		errs = append(errs, field.Required(path.Child("address"), ""))
+	}
+	if provider.SecretRef == nil {
+		errs = append(errs, field.Required(path.Child("secretRef"), "the AccessKey is required"))
+	}
+	if provider.SLS == nil {
+		return append(errs, field.Required(path.Child("sls"), ""))
+	}
+
+	slsPath := path.Child("sls")
+	if provider.SLS.Project == "" {
+		errs = append(errs, field.Required(slsPath.Child("project"), ""))
+	}
+	if provider.SLS.Logstore == "" {
+		errs = append(errs, field.Required(slsPath.Child("logstore"), ""))
+	}
+	return errs
+}
+
+// ValidateAlertProvider checks the alert provider type and address
+func ValidateAlertProvider(ap *flaggerv1.AlertProvider) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if !contains(alertProviders, ap.Spec.Type) {
+		errs = append(errs, field.NotSupported(specPath.Child("type"), ap.Spec.Type, alertProviders))
+	}
+	if ap.Spec.Address == "" && ap.Spec.SecretRef == nil {
+		errs = append(errs, field.Required(specPath.Child("address"), "address or secretRef is required"))
+	}
+	return errs
+}
+
+// ValidateAnalysisTemplate checks the analysis fragment of an AnalysisTemplate or ClusterAnalysisTemplate
+func ValidateAnalysisTemplate(spec flaggerv1.AnalysisTemplateSpec) field.ErrorList {
+	analysisPath := field.NewPath("spec", "analysis")
+	errs := validateAnalysis(&spec.Analysis, analysisPath)
+	if len(spec.Analysis.TemplateRefs) > 0 {
+		errs = append(errs, field.Forbidden(analysisPath.Child("templateRefs"), "templates can't reference other templates"))
+	}
+	return errs
+}
+
+// ValidateCanaryGroup checks the group members and their dependencies
+func ValidateCanaryGroup(group *flaggerv1.CanaryGroup) field.ErrorList {
+	var errs field.ErrorList
+	membersPath := field.NewPath("spec", "members")
+
+	if len(group.Spec.Members) == 0 {
+		errs = append(errs, field.Required(membersPath, ""))
+	}
+	for i, member := range group.Spec.Members {
+		if member.Name == "" {
+			errs = append(errs, field.Required(membersPath.Index(i).Child("name"), ""))
+		}
+	}
+	if _, err := group.SortMembers(); err != nil {
+		errs = append(errs, field.Invalid(membersPath, len(group.Spec.Members), err.Error()))
+	}
+	return errs
+}
+
+func canaryActions() []string {
+	actions := make([]string, 0, len(flaggerv1.CanaryActions))
+	for _, action := range flaggerv1.CanaryActions {
+		actions = append(actions, string(action))
+	}
+	return actions
+}
+
+func isMeshProvider(provider string) bool {
+	for _, p := range meshProviders {
+		if provider == p || strings.HasPrefix(provider, fmt.Sprintf("%s:", p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ListenAndServeWebhook starts the admission webhook HTTPS server and waits for SIGTERM,
+// the certificate and key are loaded from the tls.crt and tls.key files of the cert dir
+func ListenAndServeWebhook(port string, certDir string, meshProvider string, timeout time.Duration, logger *zap.SugaredLogger, stopCh <-chan struct{}) {
+	srv := &http.Server{
+		Addr:         ":" + port,
+		Handler:      NewAdmissionHandler(meshProvider, logger),
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  15 * time.Second,
+	}
+
+	logger.Infof("Starting admission webhook server on port %s", port)
+
+	// run server in background
+	go func() {
+		certFile := filepath.Join(certDir, "tls.crt")
+		keyFile := filepath.Join(certDir, "tls.key")
+		if err := srv.ListenAndServeTLS(certFile, keyFile); err != http.ErrServerClosed {
+			logger.Fatalf("Admission webhook server crashed %v", err)
+		}
+	}()
+
+	// wait for SIGTERM or SIGINT
+	<-stopCh
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Errorf("Admission webhook server graceful shutdown failed %v", err)
+	} else {
+		logger.Info("Admission webhook server stopped")
+	}
+}